@@ -1,24 +1,14 @@
 package main
 
 import (
+	httpapp "URLshortener/internal/app/http"
 	"URLshortener/internal/config"
-	"URLshortener/internal/http-server/handlers/redirect"
-	deletee "URLshortener/internal/http-server/handlers/url/delete"
-	"URLshortener/internal/http-server/handlers/url/deleteUserData"
-	"URLshortener/internal/http-server/handlers/url/getUsersAliases"
-	"URLshortener/internal/http-server/handlers/url/save"
-	"URLshortener/internal/http-server/handlers/url/update"
-	"URLshortener/internal/http-server/middleware/authorization"
-	"URLshortener/internal/http-server/middleware/logger"
-	jwtlib "URLshortener/internal/jwt"
 	"URLshortener/internal/lib/logger/sl"
-	"URLshortener/internal/storage/sql"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	"flag"
 	"log/slog"
-	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 )
 
 const (
@@ -31,6 +21,11 @@ func main() {
 
 	cfg := config.MustLoad()
 
+	// url-shortener migrate up|down|status|version
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(cfg, flag.Args()[1:]))
+	}
+
 	log := setupLogger(cfg.Env)
 	log.Info(
 		"starting url-shortener",
@@ -38,47 +33,25 @@ func main() {
 	)
 	log.Debug("debug messages are enabled")
 
-	storage, err := sql.New(cfg.DBDriver, cfg.ConnString)
+	application, err := httpapp.New(log, cfg)
 	if err != nil {
-		log.Error("failed to init storage", sl.Err(err))
+		log.Error("failed to init application", sl.Err(err))
 		os.Exit(1) // можно return но так непонятно что была ошибка
 	}
 
-	router := chi.NewRouter()
-
-	router.Use(middleware.RequestID)
-	router.Use(logger.New(log))
-	router.Use(middleware.Recoverer)
-	router.Use(middleware.URLFormat)
-
-	tokenValidator := jwtlib.New(time.Hour, time.Hour, cfg.Secret)
+	go application.MustRun()
 
-	router.Use(authorization.New(log, tokenValidator))
+	// Graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
 
-	router.Route("/", func(r chi.Router) {
-		r.Post("/", save.New(log, storage))
-		r.Patch("/", update.New(log, storage))
-		r.Delete("/", deletee.New(log, storage))
-		r.Delete("/admin", deleteUserData.New(log, storage))
-		r.Get("/{alias}", redirect.New(log, storage))
-		r.Get("/urls", getUsersAliases.New(log, storage))
-	})
+	sign := <-stop
 
-	log.Info("starting server :", slog.String("address", cfg.Address))
+	log.Info("stopping application", slog.String("signal", sign.String()))
 
-	srv := &http.Server{
-		Addr:         cfg.Address,
-		Handler:      router,
-		ReadTimeout:  cfg.HTTPServer.Timeout,
-		WriteTimeout: cfg.HTTPServer.Timeout,
-		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
-	}
-
-	if err := srv.ListenAndServe(); err != nil {
-		log.Error("failed to start server")
-	}
+	application.Stop()
 
-	log.Error("server stopped")
+	log.Info("application stopped")
 }
 
 func setupLogger(env string) *slog.Logger {
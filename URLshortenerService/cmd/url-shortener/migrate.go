@@ -0,0 +1,80 @@
+package main
+
+import (
+	"URLshortener/internal/config"
+	"URLshortener/internal/migrator"
+	"URLshortener/migrations"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: url-shortener [-config path] migrate <command>
+
+commands:
+  up        apply all pending migrations
+  down [n]  roll back the last n migrations, 1 by default
+  status    list the migrations and whether they are applied
+  version   print the version of the schema`
+
+// runMigrate runs the migrate subcommand and returns the exit code.
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	m, err := migrator.New(cfg.DBDriver, cfg.ConnString, migrations.FS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+		err = m.Down(steps)
+	case "status":
+		var status []migrator.Migration
+		status, err = m.Status()
+		for _, migration := range status {
+			state := "pending"
+			if migration.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%03d_%s\t%s\n", migration.Version, migration.Name, state)
+		}
+	case "version":
+		var (
+			version uint
+			dirty   bool
+		)
+		version, dirty, err = m.Version()
+		if err == nil {
+			fmt.Print(version)
+			if dirty {
+				fmt.Print(" (dirty)")
+			}
+			fmt.Println()
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/logger/sl"
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+type ClickSaver interface {
+	SaveClicks(ctx context.Context, clicks []models.Click) error
+}
+
+// Writer buffers click events and saves them in batches in the background,
+// so the redirect never waits for the database.
+type Writer struct {
+	log           *slog.Logger
+	saver         ClickSaver
+	events        chan models.Click
+	batchSize     int
+	flushInterval time.Duration
+	anonymizeIP   bool
+
+	// mu guards closed: Record sends under the read lock, so once Close has
+	// set closed no click can reach events after the final drain
+	mu        sync.RWMutex
+	closed    bool
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewWriter(
+	log *slog.Logger,
+	saver ClickSaver,
+	bufferSize int,
+	batchSize int,
+	flushInterval time.Duration,
+	anonymizeIP bool,
+) *Writer {
+	w := &Writer{
+		log:           log.With(slog.String("component", "analytics.Writer")),
+		saver:         saver,
+		events:        make(chan models.Click, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		anonymizeIP:   anonymizeIP,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Record queues the click. If the buffer is full or the writer is closed
+// the click is dropped.
+func (w *Writer) Record(click models.Click) {
+	if w.anonymizeIP {
+		click.IP = AnonymizeIP(click.IP)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return
+	}
+
+	select {
+	case w.events <- click:
+	default:
+		w.log.Warn("click buffer is full, event dropped", slog.String("alias", click.Alias))
+	}
+}
+
+// Close stops accepting clicks and flushes everything that is already queued.
+func (w *Writer) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		close(w.stop)
+	})
+	<-w.done
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.Click, 0, w.batchSize)
+
+	for {
+		select {
+		case click := <-w.events:
+			batch = append(batch, click)
+			if len(batch) >= w.batchSize {
+				batch = w.flush(batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(batch)
+		case <-w.stop:
+			for {
+				select {
+				case click := <-w.events:
+					batch = append(batch, click)
+				default:
+					w.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *Writer) flush(batch []models.Click) []models.Click {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	if err := w.saver.SaveClicks(context.Background(), batch); err != nil {
+		w.log.Error("failed to save clicks", slog.Int("count", len(batch)), sl.Err(err))
+	}
+
+	return batch[:0]
+}
+
+// AnonymizeIP zeroes the host part of the address:
+// the last octet for IPv4 and everything after /48 for IPv6.
+func AnonymizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,91 @@
+package analytics_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"URLshortener/internal/analytics"
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+type saverStub struct {
+	mu      sync.Mutex
+	batches [][]models.Click
+}
+
+func (s *saverStub) SaveClicks(_ context.Context, clicks []models.Click) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, append([]models.Click(nil), clicks...))
+	return nil
+}
+
+func (s *saverStub) total() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, b := range s.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestWriter_FlushesOnClose(t *testing.T) {
+	saver := &saverStub{}
+	w := analytics.NewWriter(slogdiscard.NewDiscardLogger(), saver, 100, 10, time.Hour, false)
+
+	for i := 0; i < 25; i++ {
+		w.Record(models.Click{URLID: 1, Alias: "alias"})
+	}
+	w.Close()
+
+	require.Equal(t, 25, saver.total())
+
+	// после закрытия события больше не принимаются
+	w.Record(models.Click{URLID: 1, Alias: "alias"})
+	require.Equal(t, 25, saver.total())
+}
+
+func TestWriter_FlushesByInterval(t *testing.T) {
+	saver := &saverStub{}
+	w := analytics.NewWriter(slogdiscard.NewDiscardLogger(), saver, 100, 10, 10*time.Millisecond, false)
+	defer w.Close()
+
+	w.Record(models.Click{URLID: 1, Alias: "alias"})
+
+	require.Eventually(t, func() bool { return saver.total() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestWriter_AnonymizesIP(t *testing.T) {
+	saver := &saverStub{}
+	w := analytics.NewWriter(slogdiscard.NewDiscardLogger(), saver, 100, 10, time.Hour, true)
+
+	w.Record(models.Click{URLID: 1, IP: "192.168.10.42"})
+	w.Close()
+
+	require.Len(t, saver.batches, 1)
+	require.Equal(t, "192.168.10.0", saver.batches[0][0].IP)
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	cases := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "203.0.113.77", want: "203.0.113.0"},
+		{ip: "2001:db8:abcd:12:1:2:3:4", want: "2001:db8:abcd::"},
+		{ip: "not an ip", want: "not an ip"},
+		{ip: "", want: ""},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.want, analytics.AnonymizeIP(tc.ip))
+	}
+}
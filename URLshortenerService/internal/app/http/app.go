@@ -0,0 +1,495 @@
+package httpapp
+
+import (
+	"URLshortener/internal/analytics"
+	"URLshortener/internal/config"
+	"URLshortener/internal/http-server/handlers/domains"
+	"URLshortener/internal/http-server/handlers/folders"
+	"URLshortener/internal/http-server/handlers/health"
+	"URLshortener/internal/http-server/handlers/qr"
+	"URLshortener/internal/http-server/handlers/redirect"
+	"URLshortener/internal/http-server/handlers/stats"
+	"URLshortener/internal/http-server/handlers/tags"
+	"URLshortener/internal/http-server/handlers/url/batch"
+	deletee "URLshortener/internal/http-server/handlers/url/delete"
+	"URLshortener/internal/http-server/handlers/url/deleteUserData"
+	"URLshortener/internal/http-server/handlers/url/getUsersAliases"
+	"URLshortener/internal/http-server/handlers/url/rename"
+	"URLshortener/internal/http-server/handlers/url/revisions"
+	"URLshortener/internal/http-server/handlers/url/save"
+	"URLshortener/internal/http-server/handlers/url/transfer"
+	"URLshortener/internal/http-server/handlers/url/trash"
+	"URLshortener/internal/http-server/handlers/url/update"
+	"URLshortener/internal/http-server/middleware/authorization"
+	"URLshortener/internal/http-server/middleware/logger"
+	"URLshortener/internal/http-server/middleware/metrics"
+	"URLshortener/internal/http-server/middleware/ratelimit"
+	"URLshortener/internal/http-server/middleware/realip"
+	"URLshortener/internal/http-server/middleware/tracing"
+	"URLshortener/internal/janitor"
+	jwtlib "URLshortener/internal/jwt"
+	"URLshortener/internal/lib/alias"
+	"URLshortener/internal/lib/attempts"
+	"URLshortener/internal/lib/cache"
+	"URLshortener/internal/lib/dnsverify"
+	"URLshortener/internal/lib/logger/sl"
+	metricslib "URLshortener/internal/lib/metrics"
+	"URLshortener/internal/lib/pagemeta"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/lib/random"
+	"URLshortener/internal/lib/screening"
+	tracinglib "URLshortener/internal/lib/tracing"
+	"URLshortener/internal/migrator"
+	"URLshortener/internal/storage/aliascache"
+	"URLshortener/internal/storage/sql"
+	"URLshortener/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// readinessTimeout limits the pings of /readyz, probes usually give up after a few seconds.
+const readinessTimeout = 2 * time.Second
+
+type App struct {
+	log             *slog.Logger
+	server          *http.Server
+	metricsServer   *http.Server
+	shutdownTimeout time.Duration
+	storage         *sql.Storage
+	clickWriter     *analytics.Writer
+	janitor         *janitor.Janitor
+	stopAliasCache  func()
+	stopTracing     func(context.Context) error
+}
+
+// New builds the storage, the background workers and the router of the
+// service. Nothing is served until MustRun.
+func New(log *slog.Logger, cfg *config.Config) (*App, error) {
+	const op = "httpapp.New"
+
+	stopTracing, err := tracinglib.Setup(context.Background(), tracinglib.Options{
+		ServiceName: "url-service",
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to init tracing: %w", op, err)
+	}
+
+	if cfg.AutoMigrate {
+		if err := migrate(log, cfg.DBDriver, cfg.ConnString); err != nil {
+			stopTracing(context.Background())
+			return nil, fmt.Errorf("%s: failed to migrate storage: %w", op, err)
+		}
+	}
+
+	storage, err := sql.New(cfg.DBDriver, cfg.ConnString)
+	if err != nil {
+		stopTracing(context.Background())
+		return nil, fmt.Errorf("%s: failed to init storage: %w", op, err)
+	}
+
+	appMetrics := metricslib.New()
+	storage.OnError(appMetrics.StorageError)
+	appMetrics.MustRegister(metricslib.DBStats(storage.Stats))
+
+	aliasGenerator, err := setupAliasGenerator(cfg.Alias, storage)
+	if err != nil {
+		storage.Close()
+		stopTracing(context.Background())
+		return nil, fmt.Errorf("%s: failed to init alias generator: %w", op, err)
+	}
+	aliases := alias.NewAllocator(aliasGenerator, cfg.Alias.MaxAttempts)
+
+	router := chi.NewRouter()
+
+	router.Use(middleware.RequestID)
+	router.Use(tracing.New(log))
+	router.Use(realip.New(log))
+	router.Use(logger.New(log))
+	router.Use(metrics.New(log, appMetrics))
+	router.Use(middleware.Recoverer)
+	router.Use(middleware.URLFormat)
+
+	tokenValidator := jwtlib.New(time.Hour, time.Hour, cfg.Secret)
+
+	domainVerifier := dnsverify.New(net.DefaultResolver, cfg.Domains.VerifyTimeout)
+
+	urlScreener, err := setupScreener(log, cfg, storage)
+	if err != nil {
+		storage.Close()
+		stopTracing(context.Background())
+		return nil, fmt.Errorf("%s: failed to init url screening: %w", op, err)
+	}
+
+	// Без fetcher'а заголовок и описание страницы не запрашиваются
+	// Страницы запрашиваются только с публичных адресов, даже если DNS ответит иначе, чем при проверке
+	var metadataFetcher save.MetadataFetcher
+	if cfg.Metadata.Fetch {
+		client := &http.Client{Timeout: cfg.Metadata.Timeout, Transport: screening.SafeTransport(cfg.Metadata.Timeout)}
+		metadataFetcher = pagemeta.New(client, cfg.Metadata.Timeout, cfg.Metadata.MaxBytes)
+	}
+
+	clickWriter := analytics.NewWriter(
+		log,
+		storage,
+		cfg.Analytics.BufferSize,
+		cfg.Analytics.BatchSize,
+		cfg.Analytics.FlushInterval,
+		cfg.Analytics.AnonymizeIP,
+	)
+
+	expiredJanitor := janitor.New(log, storage, cfg.Janitor.Interval, cfg.Janitor.Mode, cfg.Trash.Retention)
+	expiredJanitor.Start()
+
+	passwordAttempts := attempts.New(cfg.Protection.MaxAttempts, cfg.Protection.Window)
+	aliasResolver, stopAliasCache := setupAliasCache(log, cfg.AliasCache, storage, appMetrics)
+	redirectHandler := redirect.New(log, aliasResolver, clickWriter, passwordAttempts)
+	qrImages := cache.New[string, []byte](cfg.QR.CacheSize, cfg.QR.CacheTTL)
+
+	rateLimits := ratelimit.NewMemoryStore()
+	linkQuotas := setupQuotas(cfg.Quotas, storage)
+
+	// Пробы для compose и оркестраторов, без лимитов и токена
+	router.Get("/healthz", health.NewLiveness())
+	router.Get("/readyz", health.NewReadiness(log, readinessTimeout, health.Check{Name: "database", Pinger: storage}))
+	router.Get("/version", health.NewVersion())
+
+	router.Group(func(r chi.Router) {
+		redirectLimit := ratelimit.New(log, rateLimits, "redirect", limit(cfg.RateLimit.Redirect), ratelimit.ByIP)
+		// Счетчик снаружи лимита, чтобы отказы лимита попадали в rate_limited
+		redirectMetrics := metrics.Redirects(appMetrics)
+
+		// Публичный редирект, доступен без токена. Алиас ищется на домене из Host
+		r.With(redirectMetrics, redirectLimit).Get("/{alias}", redirectHandler)
+		// Форма ввода пароля защищённой ссылки
+		r.With(redirectMetrics, redirectLimit).Post("/{alias}", redirectHandler)
+		// QR-код короткой ссылки: /{alias}/qr, /{alias}/qr.svg
+		r.With(redirectLimit).Get("/{alias}/qr", qr.New(log, aliasResolver, qrImages, cfg.PublicURL))
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(authorization.New(log, tokenValidator))
+		r.Use(ratelimit.New(log, rateLimits, "api", limit(cfg.RateLimit.API), ratelimit.ByUser))
+
+		createLimit := ratelimit.New(log, rateLimits, "create", limit(cfg.RateLimit.Create), ratelimit.ByUser)
+
+		r.With(createLimit).Post("/", save.New(log, storage, aliases, urlScreener, linkQuotas, metadataFetcher))
+		r.With(createLimit).Post("/batch", batch.New(log, storage, aliases, urlScreener, linkQuotas, cfg.Batch.MaxItems))
+		r.Patch("/", update.New(log, storage, urlScreener))
+		r.Patch("/alias", rename.New(log, storage, cfg.PublicURL, cfg.Rename.MaxGrace))
+		r.Get("/links/{id}/revisions", revisions.NewList(log, storage))
+		r.Post("/links/{id}/revisions/{revision}/rollback", revisions.NewRollback(log, storage, urlScreener, cfg.PublicURL))
+		r.Delete("/", deletee.New(log, storage))
+		r.Get("/trash", trash.NewList(log, storage, cfg.PublicURL, cfg.Trash.Retention))
+		r.Post("/trash/{id}/restore", trash.NewRestore(log, storage, cfg.PublicURL))
+		r.Delete("/trash/{id}", trash.NewPurge(log, storage))
+		r.Delete("/admin", deleteUserData.New(log, storage))
+		r.Get("/urls", getUsersAliases.New(log, storage, cfg.PublicURL))
+		r.Get("/export", transfer.NewExport(log, storage))
+		r.With(createLimit).Post("/import", transfer.NewImport(log, storage, aliases, urlScreener, linkQuotas))
+		r.Get("/stats", stats.NewUserStats(log, storage))
+		r.Get("/stats/{alias}", stats.NewLinkStats(log, storage))
+		r.Post("/domains", domains.NewCreate(log, storage, cfg.PublicURL))
+		r.Get("/domains", domains.NewList(log, storage))
+		r.Post("/domains/{id}/verify", domains.NewVerify(log, storage, domainVerifier))
+		r.Delete("/domains/{id}", domains.NewDelete(log, storage))
+		r.Get("/tags", tags.NewList(log, storage))
+		r.Post("/tags", tags.NewCreate(log, storage))
+		r.Patch("/tags/{id}", tags.NewRename(log, storage))
+		r.Delete("/tags/{id}", tags.NewDelete(log, storage))
+		r.Post("/links/tags", tags.NewBulk(log, storage))
+		r.Get("/folders", folders.NewList(log, storage))
+		r.Post("/folders", folders.NewCreate(log, storage))
+		r.Patch("/folders/{id}", folders.NewUpdate(log, storage))
+		r.Delete("/folders/{id}", folders.NewDelete(log, storage))
+		r.Post("/links/folder", folders.NewMove(log, storage))
+	})
+
+	// Span запроса начинается до роутера и продолжает трассу из traceparent
+	srv := &http.Server{
+		Addr:         cfg.Address,
+		Handler:      otelhttp.NewHandler(router, "url-service"),
+		ReadTimeout:  cfg.HTTPServer.Timeout,
+		WriteTimeout: cfg.HTTPServer.Timeout,
+		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
+	}
+
+	// Метрики слушают отдельный адрес, наружу он не публикуется
+	var metricsSrv *http.Server
+	if cfg.Metrics.Enabled {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", appMetrics.Handler())
+
+		metricsSrv = &http.Server{
+			Addr:              cfg.Metrics.Address,
+			Handler:           metricsMux,
+			ReadHeaderTimeout: cfg.HTTPServer.Timeout,
+		}
+	} else {
+		log.Info("metrics are disabled")
+	}
+
+	return &App{
+		log:             log,
+		server:          srv,
+		metricsServer:   metricsSrv,
+		shutdownTimeout: cfg.HTTPServer.ShutdownTimeout,
+		storage:         storage,
+		clickWriter:     clickWriter,
+		janitor:         expiredJanitor,
+		stopAliasCache:  stopAliasCache,
+		stopTracing:     stopTracing,
+	}, nil
+
+}
+
+func (a *App) MustRun() {
+	if err := a.run(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) run() error {
+	const op = "httpapp.Run"
+
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	if a.metricsServer != nil {
+		go a.runMetrics()
+	}
+
+	log.Info("http server is running", slog.String("address", a.server.Addr))
+
+	if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// runMetrics serves the metrics until Stop. The service keeps working if
+// the metrics address cannot be listened on.
+func (a *App) runMetrics() {
+	const op = "httpapp.runMetrics"
+
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("metrics server is running", slog.String("address", a.metricsServer.Addr))
+
+	if err := a.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("failed to serve metrics", sl.Err(err))
+	}
+}
+
+// Stop waits up to the shutdown timeout for requests in flight, then stops
+// the background workers, writes the queued clicks and closes the storage.
+func (a *App) Stop() {
+	const op = "httpapp.Stop"
+
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("stopping http server", slog.String("address", a.server.Addr))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	// После дедлайна оставшиеся соединения рвутся, клики от них уже в очереди
+	if err := a.server.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to drain http connections", sl.Err(err))
+		a.server.Close()
+	} else {
+		log.Info("http server is stopped")
+	}
+
+	a.janitor.Stop()
+	a.clickWriter.Close()
+	log.Info("queued clicks are written")
+
+	a.stopAliasCache()
+
+	// Метрики отдаются до последнего, чтобы был виден ход остановки
+	// У каждого шага свой таймаут: истекший на разгрузке соединений не должен обрывать следующие
+	if a.metricsServer != nil {
+		metricsCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+		defer cancel()
+
+		if err := a.metricsServer.Shutdown(metricsCtx); err != nil {
+			a.metricsServer.Close()
+		}
+	}
+
+	if err := a.storage.Close(); err != nil {
+		log.Error("failed to close storage", sl.Err(err))
+	}
+
+	// Последними отправляются spans остановки
+	tracingCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	if err := a.stopTracing(tracingCtx); err != nil {
+		log.Error("failed to flush spans", sl.Err(err))
+	}
+}
+
+// migrate applies the pending migrations. Replicas starting together wait
+// for each other on the migration lock.
+func migrate(log *slog.Logger, driver string, connString string) error {
+	m, err := migrator.New(driver, connString, migrations.FS)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return err
+	}
+
+	version, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	log.Info("storage is migrated", slog.Uint64("version", uint64(version)))
+
+	return nil
+}
+
+// setupScreener returns nil if screening is disabled.
+func setupScreener(log *slog.Logger, cfg *config.Config, domains screening.DomainLookup) (save.URLScreener, error) {
+	if !cfg.Screening.Enabled {
+		log.Warn("url screening is disabled")
+		return nil, nil
+	}
+
+	hosts := cfg.Screening.Hosts
+	if u, err := url.Parse(cfg.PublicURL); err == nil && u.Hostname() != "" {
+		hosts = append(hosts, u.Hostname())
+	}
+
+	checkers := []screening.Checker{
+		screening.Schemes("http", "https"),
+		screening.SelfReference(hosts, domains),
+	}
+
+	if cfg.Screening.Blocklist != "" {
+		blocklist, err := screening.NewBlocklist(log, cfg.Screening.Blocklist, cfg.Screening.ReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("blocklist loaded", slog.Int("domains", blocklist.Len()))
+		checkers = append(checkers, blocklist)
+	}
+
+	// Проверка через DNS самая медленная, поэтому последняя
+	checkers = append(checkers, screening.PublicAddress(net.DefaultResolver, cfg.Screening.ResolveTimeout))
+
+	return screening.New(checkers...), nil
+}
+
+// setupAliasCache puts a cache in front of alias resolution and subscribes it
+// to changes of links. Without the cache the storage is returned as is.
+// The returned function releases the cache.
+func setupAliasCache(log *slog.Logger, cfg config.AliasCache, storage *sql.Storage, appMetrics *metricslib.Metrics) (redirect.AliasResolver, func()) {
+	if !cfg.Enabled {
+		log.Info("alias cache is disabled")
+		return storage, func() {}
+	}
+
+	var backend aliascache.Backend
+	closeBackend := func() error { return nil }
+	if cfg.RedisAddr != "" {
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		backend = aliascache.NewRedis(client, "alias:", max(cfg.TTL, cfg.NegativeTTL))
+		closeBackend = client.Close
+		log.Info("alias cache uses redis", slog.String("address", cfg.RedisAddr))
+	} else {
+		backend = aliascache.NewMemory(cfg.Size, max(cfg.TTL, cfg.NegativeTTL))
+	}
+
+	resolver := aliascache.New(log, storage, backend, cfg.TTL, cfg.NegativeTTL)
+	storage.OnAliasChange(resolver.Invalidate)
+	storage.OnHostChange(resolver.InvalidateHost)
+	stopStats := resolver.ReportStats(cfg.StatsInterval)
+	registerAliasCacheStats(appMetrics, resolver)
+
+	return resolver, func() {
+		stopStats()
+		if err := closeBackend(); err != nil {
+			log.Error("failed to close alias cache", sl.Err(err))
+		}
+	}
+}
+
+func registerAliasCacheStats(appMetrics *metricslib.Metrics, resolver *aliascache.Resolver) {
+	counter := func(name string, help string, value func(aliascache.Stats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricslib.Namespace,
+			Subsystem: "alias_cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 {
+			return float64(value(resolver.Stats()))
+		})
+	}
+
+	appMetrics.MustRegister(
+		counter("hits_total", "Aliases resolved from the cache.", func(s aliascache.Stats) uint64 { return s.Hits }),
+		counter("misses_total", "Aliases resolved from the database.", func(s aliascache.Stats) uint64 { return s.Misses }),
+		counter("errors_total", "Failed calls to the cache backend.", func(s aliascache.Stats) uint64 { return s.Errors }),
+	)
+}
+
+func setupQuotas(cfg config.Quotas, counter quota.LinkCounter) *quota.Enforcer {
+	roles := make(map[string]quota.Limits, len(cfg.Roles))
+	for role, q := range cfg.Roles {
+		roles[role] = quota.Limits{MaxLinks: q.MaxLinks, MaxDaily: q.MaxDaily}
+	}
+
+	return quota.New(counter, roles, quota.Limits{MaxLinks: cfg.Default.MaxLinks, MaxDaily: cfg.Default.MaxDaily})
+}
+
+func limit(cfg config.Limit) ratelimit.Limit {
+	return ratelimit.Limit{Requests: cfg.Requests, Per: cfg.Per, Burst: cfg.Burst}
+}
+
+func setupAliasGenerator(cfg config.Alias, seq alias.Sequence) (alias.Generator, error) {
+	alphabet := cfg.Alphabet
+	if alphabet == "" {
+		alphabet = random.Base62
+	}
+
+	switch cfg.Generator {
+	case alias.KindSequential:
+		return alias.NewSequential(seq, cfg.Length, alphabet)
+	case alias.KindHashids:
+		return alias.NewHashids(seq, cfg.Salt, cfg.Length, alphabet)
+	case alias.KindWords:
+		return alias.NewWords(), nil
+	default:
+		return alias.NewRandom(cfg.Length, alphabet)
+	}
+}
@@ -9,15 +9,38 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 )
 
 type Config struct {
 	Env        string `yaml:"env" env-default:"local"`
-	DBDriver   string `yaml:"db_driver"`
+	DBDriver   string `yaml:"db_driver" env-default:"postgres"`
 	ConnString string `yaml:"conn_string"`
 	Secret     string `yaml:"secret"`
+	PublicURL  string `yaml:"public_url" env-default:"http://localhost:8082"`
 	HTTPServer `yaml:"http_server"`
+	Analytics  Analytics  `yaml:"analytics"`
+	Janitor    Janitor    `yaml:"janitor"`
+	Protection Protection `yaml:"protection"`
+	QR         QR         `yaml:"qr"`
+	Batch      Batch      `yaml:"batch"`
+	Alias      Alias      `yaml:"alias"`
+	Domains    Domains    `yaml:"domains"`
+	Metadata   Metadata   `yaml:"metadata"`
+	Rename     Rename     `yaml:"rename"`
+	Trash      Trash      `yaml:"trash"`
+	Screening  Screening  `yaml:"screening"`
+	RateLimit  RateLimit  `yaml:"rate_limit"`
+	Quotas     Quotas     `yaml:"quotas"`
+	AliasCache AliasCache `yaml:"alias_cache"`
+	Metrics    Metrics    `yaml:"metrics"`
+	Tracing    Tracing    `yaml:"tracing"`
+
+	// StoragePath is the database file of the sqlite3 driver
+	StoragePath string `yaml:"storage_path" env:"DB_URLS_PATH"`
+	// AutoMigrate applies the pending migrations on start
+	AutoMigrate bool `yaml:"auto_migrate" env:"AUTO_MIGRATE" env-default:"false"`
 }
 
 type DBInitData struct {
@@ -32,6 +55,140 @@ type HTTPServer struct {
 	Address     string        `yaml:"address" env-default:"localhost:8080"`
 	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	// ShutdownTimeout is how long requests in flight may finish on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"15s"`
+}
+
+type Analytics struct {
+	AnonymizeIP   bool          `yaml:"anonymize_ip" env-default:"true"`
+	BufferSize    int           `yaml:"buffer_size" env-default:"10000"`
+	BatchSize     int           `yaml:"batch_size" env-default:"100"`
+	FlushInterval time.Duration `yaml:"flush_interval" env-default:"2s"`
+}
+
+type Janitor struct {
+	Interval time.Duration `yaml:"interval" env-default:"1h"`
+	Mode     string        `yaml:"mode" env-default:"delete" validate:"oneof=delete archive"`
+}
+
+// Protection limits failed password attempts per protected alias.
+type Protection struct {
+	MaxAttempts int           `yaml:"max_attempts" env-default:"5"`
+	Window      time.Duration `yaml:"window" env-default:"15m"`
+}
+
+// QR configures the cache of rendered QR codes.
+type QR struct {
+	CacheSize int           `yaml:"cache_size" env-default:"1000"`
+	CacheTTL  time.Duration `yaml:"cache_ttl" env-default:"24h"`
+}
+
+type Batch struct {
+	MaxItems int `yaml:"max_items" env-default:"500"`
+}
+
+// Alias configures generation of aliases for links created without one.
+type Alias struct {
+	Generator   string `yaml:"generator" env-default:"random" validate:"oneof=random sequential hashids words"`
+	Length      int    `yaml:"length" env-default:"6" validate:"min=1,max=64"`
+	Alphabet    string `yaml:"alphabet"`
+	Salt        string `yaml:"salt" env:"ALIAS_SALT"`
+	MaxAttempts int    `yaml:"max_attempts" env-default:"5" validate:"min=1"`
+}
+
+// Domains configures verification of custom domains.
+type Domains struct {
+	VerifyTimeout time.Duration `yaml:"verify_timeout" env-default:"5s"`
+}
+
+// Metadata configures fetching of the title and description of the
+// destination page when a link is created.
+type Metadata struct {
+	Fetch    bool          `yaml:"fetch" env-default:"false"`
+	Timeout  time.Duration `yaml:"timeout" env-default:"3s"`
+	MaxBytes int64         `yaml:"max_bytes" env-default:"524288"`
+}
+
+// Rename limits how long the old alias of a renamed link keeps redirecting.
+type Rename struct {
+	MaxGrace time.Duration `yaml:"max_grace" env-default:"720h"`
+}
+
+// Trash configures how long deleted links can be restored before they are purged.
+type Trash struct {
+	Retention time.Duration `yaml:"retention" env-default:"720h"`
+}
+
+// Screening configures the checks destinations must pass before links are
+// created or changed. The host of PublicURL is always treated as own.
+type Screening struct {
+	Enabled        bool          `yaml:"enabled" env-default:"true"`
+	Hosts          []string      `yaml:"hosts"`
+	Blocklist      string        `yaml:"blocklist"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env-default:"30s"`
+	ResolveTimeout time.Duration `yaml:"resolve_timeout" env-default:"2s"`
+}
+
+// RateLimit configures token buckets of the routes. Redirect is keyed on the
+// client IP, the others on the user ID. Create applies to link creation on
+// top of API.
+type RateLimit struct {
+	Redirect Limit `yaml:"redirect"`
+	API      Limit `yaml:"api"`
+	Create   Limit `yaml:"create"`
+}
+
+// Limit allows Requests per Per with bursts of up to Burst requests
+// (Requests if not set). Zero Requests turns the limit off.
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
+// Quotas limit the number of links per role. Roles missing from Roles get
+// Default. Zero means no limit.
+type Quotas struct {
+	Default Quota            `yaml:"default"`
+	Roles   map[string]Quota `yaml:"roles"`
+}
+
+type Quota struct {
+	MaxLinks int `yaml:"max_links"`
+	MaxDaily int `yaml:"max_daily"`
+}
+
+// AliasCache configures the cache of resolved aliases used by redirects.
+// With RedisAddr set the cache is shared by all instances, otherwise every
+// instance keeps its own in memory.
+type AliasCache struct {
+	Enabled       bool          `yaml:"enabled" env-default:"true"`
+	Size          int           `yaml:"size" env-default:"100000"`
+	TTL           time.Duration `yaml:"ttl" env-default:"5m"`
+	NegativeTTL   time.Duration `yaml:"negative_ttl" env-default:"30s"`
+	RedisAddr     string        `yaml:"redis_addr" env:"ALIAS_CACHE_REDIS_ADDR"`
+	RedisPassword string        `yaml:"redis_password" env:"ALIAS_CACHE_REDIS_PASSWORD"`
+	RedisDB       int           `yaml:"redis_db"`
+	StatsInterval time.Duration `yaml:"stats_interval" env-default:"5m"`
+}
+
+// Metrics are served on their own listener, so they are not reachable
+// through the public address.
+type Metrics struct {
+	Enabled bool   `yaml:"enabled" env-default:"true"`
+	Address string `yaml:"address" env-default:"localhost:9090"`
+}
+
+// Tracing exports spans to stdout or to a file for local runs and to an
+// OTLP collector in production.
+type Tracing struct {
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none" validate:"oneof=none stdout otlp"`
+	// File receives the spans of the stdout exporter, empty means stdout
+	File string `yaml:"file" env:"TRACING_FILE"`
+	// Endpoint is the host:port of the OTLP gRPC collector
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env-default:"true"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1" validate:"min=0,max=1"`
 }
 
 func MustLoad() *Config {
@@ -58,30 +215,58 @@ func MustLoad() *Config {
 		log.Fatalf("cannot read config: %v", err)
 	}
 
-	urls_db := DBInitData{
-		DB_NAME:     os.Getenv("DB_URLS_NAME"),
-		DB_USERNAME: os.Getenv("DB_URLS_USERNAME"),
-		DB_PASSWORD: os.Getenv("DB_URLS_PASSWORD"),
-		DB_HOST:     os.Getenv("DB_URLS_HOST"),
-		DB_PORT:     os.Getenv("DB_URLS_PORT"),
+	if err := validator.New().Struct(cfg.Janitor); err != nil {
+		log.Fatalf("invalid janitor mode: %s", cfg.Janitor.Mode)
 	}
 
-	if err := validator.New().Struct(urls_db); err != nil {
-		var validateErr validator.ValidationErrors
-		if !errors.As(err, &validateErr) {
-			log.Fatalf("cannot to validate urls_db init data")
-		}
+	if err := validator.New().Struct(cfg.Alias); err != nil {
+		log.Fatalf("invalid alias config: %v", err)
+	}
+
+	if err := validator.New().Struct(cfg.Tracing); err != nil {
+		log.Fatalf("invalid tracing config: %v", err)
+	}
 
-		log.Fatalf(response.ValidateEnvVar(validateErr))
+	if err := validator.New().Var(cfg.DBDriver, "oneof=postgres sqlite3"); err != nil {
+		log.Fatalf("invalid db_driver: %s", cfg.DBDriver)
 	}
 
-	cfg.ConnString = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		urls_db.DB_USERNAME,
-		urls_db.DB_PASSWORD,
-		urls_db.DB_HOST,
-		"5432",
-		urls_db.DB_NAME,
-	)
+	// Для sqlite3 база лежит в файле, переменные DB_URLS_* не нужны
+	switch cfg.DBDriver {
+	case "sqlite3":
+		if cfg.StoragePath == "" {
+			log.Fatal("storage_path is required for the sqlite3 driver")
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.StoragePath), 0o755); err != nil {
+			log.Fatalf("cannot create storage directory: %v", err)
+		}
+		cfg.ConnString = cfg.StoragePath
+	default:
+		urls_db := DBInitData{
+			DB_NAME:     os.Getenv("DB_URLS_NAME"),
+			DB_USERNAME: os.Getenv("DB_URLS_USERNAME"),
+			DB_PASSWORD: os.Getenv("DB_URLS_PASSWORD"),
+			DB_HOST:     os.Getenv("DB_URLS_HOST"),
+			DB_PORT:     os.Getenv("DB_URLS_PORT"),
+		}
+
+		if err := validator.New().Struct(urls_db); err != nil {
+			var validateErr validator.ValidationErrors
+			if !errors.As(err, &validateErr) {
+				log.Fatalf("cannot to validate urls_db init data")
+			}
+
+			log.Fatal(response.ValidateEnvVar(validateErr))
+		}
+
+		cfg.ConnString = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+			urls_db.DB_USERNAME,
+			urls_db.DB_PASSWORD,
+			urls_db.DB_HOST,
+			"5432",
+			urls_db.DB_NAME,
+		)
+	}
 
 	secretKey := os.Getenv("SECRET_KEY")
 	if secretKey == "" {
@@ -1,7 +1,39 @@
 package models
 
+import "time"
+
 type AliasNote struct {
-	ID    int64  `json:"id"`
-	Url   string `json:"url"`
-	Alias string `json:"alias"`
+	ID           int64      `json:"id"`
+	Url          string     `json:"url"`
+	Alias        string     `json:"alias"`
+	Domain       string     `json:"domain,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Description  string     `json:"description,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	RedirectCode int        `json:"redirectCode"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	MaxClicks    *int64     `json:"maxClicks,omitempty"`
+	ClicksUsed   int64      `json:"clicksUsed"`
+	Protected    bool       `json:"protected"`
+	PasswordHash []byte     `json:"-"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
+	FolderID     *int64     `json:"folderId,omitempty"`
+}
+
+// LinkMetadata changes the descriptive fields of a link. Nil fields are kept as is.
+type LinkMetadata struct {
+	Title       *string
+	Description *string
+	Notes       *string
+}
+
+// Expired reports whether the link has reached its time or click limit.
+func (n AliasNote) Expired(now time.Time) bool {
+	if n.ExpiresAt != nil && !now.Before(*n.ExpiresAt) {
+		return true
+	}
+
+	return n.MaxClicks != nil && n.ClicksUsed >= *n.MaxClicks
 }
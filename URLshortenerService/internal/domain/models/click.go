@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type Click struct {
+	URLID     int64
+	Alias     string
+	ClickedAt time.Time
+	Referrer  string
+	UserAgent string
+	IP        string
+	RequestID string
+}
+
+type StatsPoint struct {
+	Time           time.Time `json:"time"`
+	Clicks         int64     `json:"clicks"`
+	UniqueVisitors int64     `json:"uniqueVisitors"`
+}
+
+type LinkClicks struct {
+	ID     int64  `json:"id"`
+	Alias  string `json:"alias"`
+	Clicks int64  `json:"clicks"`
+}
+
+type Stats struct {
+	TotalClicks    int64        `json:"totalClicks"`
+	UniqueVisitors int64        `json:"uniqueVisitors"`
+	Series         []StatsPoint `json:"series"`
+	Links          []LinkClicks `json:"links,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Domain is a custom host registered by a user for short links.
+// Links can be created on it only after the ownership is verified.
+type Domain struct {
+	ID         int64      `json:"id"`
+	Host       string     `json:"host"`
+	Token      string     `json:"token"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// ShortURL builds the short link of the alias. Links without a domain live
+// on publicURL, links on a custom domain use its host with the same scheme.
+func ShortURL(publicURL, domain, alias string) string {
+	publicURL = strings.TrimRight(publicURL, "/")
+	if domain == "" {
+		return publicURL + "/" + alias
+	}
+
+	scheme := "https"
+	if u, err := url.Parse(publicURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+
+	return scheme + "://" + domain + "/" + alias
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	SortCreated = "created"
+	SortAlias   = "alias"
+	SortClicks  = "clicks"
+
+	StatusActive  = "active"
+	StatusExpired = "expired"
+)
+
+// LinkFilter selects a page of the user's links.
+// Empty fields do not restrict the result. Links must have all of the Tags.
+type LinkFilter struct {
+	Search      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Status      string
+	Tags        []string
+	FolderID    *int64
+	Sort        string
+	Desc        bool
+	Limit       int
+	Cursor      string
+}
+
+type LinkItem struct {
+	AliasNote
+	ShortURL string   `json:"shortUrl,omitempty"`
+	Tags     []string `json:"tags"`
+	Clicks   int64    `json:"clicks"`
+}
+
+type LinkPage struct {
+	Items      []LinkItem `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	Total      int64      `json:"total"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Kinds of link changes recorded in revisions.
+const (
+	ChangeURL      = "url"
+	ChangeAlias    = "alias"
+	ChangePassword = "password"
+	ChangeMetadata = "metadata"
+	ChangeRollback = "rollback"
+)
+
+// Actor is whoever changes a link: the user ID and the role from the JWT claims.
+type Actor struct {
+	UserID int64
+	Role   string
+}
+
+// Revision records one change of a link. The destination and the alias are
+// kept as they were before and after the change.
+type Revision struct {
+	ID        int64     `json:"id"`
+	URLID     int64     `json:"urlId"`
+	Change    string    `json:"change"`
+	OldURL    string    `json:"oldUrl"`
+	NewURL    string    `json:"newUrl"`
+	OldAlias  string    `json:"oldAlias"`
+	NewAlias  string    `json:"newAlias"`
+	ActorID   int64     `json:"actorId"`
+	ActorRole string    `json:"actorRole,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
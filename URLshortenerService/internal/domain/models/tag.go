@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Tag labels links of its owner. A link can have any number of tags.
+type Tag struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Links     int64     `json:"links"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Folder groups links of its owner. Folders form a tree, a link is in at most one folder.
+type Folder struct {
+	ID        int64     `json:"id"`
+	ParentID  *int64    `json:"parentId,omitempty"`
+	Name      string    `json:"name"`
+	Links     int64     `json:"links"`
+	CreatedAt time.Time `json:"createdAt"`
+}
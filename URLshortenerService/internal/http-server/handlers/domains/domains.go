@@ -0,0 +1,333 @@
+package domains
+
+import (
+	"URLshortener/internal/domain/models"
+	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/dnsverify"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/lib/random"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tokenLength = 32
+
+type Request struct {
+	Host string `json:"host" validate:"required,fqdn"`
+}
+
+// Response is a domain together with the TXT record that proves its ownership.
+type Response struct {
+	models.Domain
+	RecordName  string `json:"recordName"`
+	RecordValue string `json:"recordValue"`
+}
+
+type ListResponse struct {
+	Domains []Response `json:"domains"`
+}
+
+//go:generate mockery --name=DomainCreator --output=./mocks
+type DomainCreator interface {
+	AddDomain(ctx context.Context, userID int64, host string, token string) (models.Domain, error)
+}
+
+type DomainLister interface {
+	ListDomains(ctx context.Context, userID int64) ([]models.Domain, error)
+}
+
+//go:generate mockery --name=DomainVerifier --output=./mocks
+type DomainVerifier interface {
+	GetDomain(ctx context.Context, id int64, userID int64) (models.Domain, error)
+	MarkDomainVerified(ctx context.Context, id int64, userID int64, at time.Time) error
+}
+
+//go:generate mockery --name=OwnershipChecker --output=./mocks
+type OwnershipChecker interface {
+	Verify(ctx context.Context, host string, token string) error
+}
+
+type DomainDeleter interface {
+	DeleteDomain(ctx context.Context, id int64, userID int64) error
+}
+
+// NewCreate registers a custom domain of the user. The response contains the
+// TXT record to publish before the domain is verified with NewVerify.
+// The host of publicURL cannot be registered.
+func NewCreate(log *slog.Logger, domainCreator DomainCreator, publicURL string) http.HandlerFunc {
+	var publicHost string
+	if u, err := url.Parse(publicURL); err == nil {
+		publicHost = strings.ToLower(u.Hostname())
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.domains.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		req.Host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(req.Host)), ".")
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Info("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.ValidationError(validateErr)))
+			return
+		}
+
+		if req.Host == publicHost {
+			log.Info("public host cannot be registered", slog.String("host", req.Host))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("domain is reserved"))
+			return
+		}
+
+		domain, err := domainCreator.AddDomain(r.Context(), userID, req.Host, random.String(tokenLength, random.Base62))
+		switch {
+		case errors.Is(err, storage.ErrDomainExist):
+			log.Info("domain already registered", slog.String("host", req.Host))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("domain already registered"))
+			return
+		case err != nil:
+			log.Error("failed to add domain", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to add domain"))
+			return
+		}
+
+		log.Info("domain added", slog.Int64("id", domain.ID), slog.String("host", domain.Host))
+
+		render.JSON(w, r, newResponse(domain))
+	}
+}
+
+// NewList returns all custom domains of the user.
+func NewList(log *slog.Logger, domainLister DomainLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.domains.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		domains, err := domainLister.ListDomains(r.Context(), userID)
+		if err != nil {
+			log.Error("failed to list domains", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		response := ListResponse{Domains: make([]Response, 0, len(domains))}
+		for _, domain := range domains {
+			response.Domains = append(response.Domains, newResponse(domain))
+		}
+
+		render.JSON(w, r, response)
+	}
+}
+
+// NewVerify checks the TXT record of the user's domain /domains/{id}/verify.
+// Links can be created on the domain once it is verified.
+func NewVerify(log *slog.Logger, domainVerifier DomainVerifier, ownershipChecker OwnershipChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.domains.NewVerify"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := domainID(w, r, log)
+		if !ok {
+			return
+		}
+
+		domain, err := domainVerifier.GetDomain(r.Context(), id, userID)
+		switch {
+		case errors.Is(err, storage.ErrDomainNotFound):
+			log.Info("domain not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("domain not found"))
+			return
+		case err != nil:
+			log.Error("failed to get domain", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		if domain.Verified {
+			render.JSON(w, r, newResponse(domain))
+			return
+		}
+
+		err = ownershipChecker.Verify(r.Context(), domain.Host, domain.Token)
+		switch {
+		case errors.Is(err, dnsverify.ErrRecordNotFound):
+			log.Info("verification record not found", slog.String("host", domain.Host))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("TXT record "+dnsverify.RecordName(domain.Host)+" with value "+
+				dnsverify.RecordValue(domain.Token)+" not found"))
+			return
+		case err != nil:
+			log.Error("failed to look up verification record", sl.Err(err))
+			render.Status(r, http.StatusBadGateway)
+			render.JSON(w, r, resp.Error("failed to look up DNS records, try again later"))
+			return
+		}
+
+		now := time.Now().UTC()
+		err = domainVerifier.MarkDomainVerified(r.Context(), id, userID, now)
+		switch {
+		case errors.Is(err, storage.ErrDomainExist):
+			log.Info("domain verified by another user", slog.String("host", domain.Host))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("domain already registered"))
+			return
+		case errors.Is(err, storage.ErrDomainNotFound):
+			log.Info("domain claim dropped", slog.String("host", domain.Host))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("domain not found"))
+			return
+		case err != nil:
+			log.Error("failed to mark domain verified", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		log.Info("domain verified", slog.String("host", domain.Host))
+
+		domain.Verified = true
+		domain.VerifiedAt = &now
+		render.JSON(w, r, newResponse(domain))
+	}
+}
+
+// NewDelete removes the user's domain /domains/{id} if it has no links.
+func NewDelete(log *slog.Logger, domainDeleter DomainDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.domains.NewDelete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := domainID(w, r, log)
+		if !ok {
+			return
+		}
+
+		err := domainDeleter.DeleteDomain(r.Context(), id, userID)
+		switch {
+		case errors.Is(err, storage.ErrDomainNotFound):
+			log.Info("domain not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("domain not found"))
+			return
+		case errors.Is(err, storage.ErrDomainInUse):
+			log.Info("domain has links", slog.Int64("id", id))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("domain has links, delete them first"))
+			return
+		case err != nil:
+			log.Error("failed to delete domain", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to delete domain"))
+			return
+		}
+
+		log.Info("domain deleted", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+func newResponse(domain models.Domain) Response {
+	return Response{
+		Domain:      domain,
+		RecordName:  dnsverify.RecordName(domain.Host),
+		RecordValue: dnsverify.RecordValue(domain.Token),
+	}
+}
+
+func domainID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		log.Info("invalid domain id", slog.String("id", chi.URLParam(r, "id")))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("invalid domain id"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func userIDFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	// Берем из контекста данные JWT токена
+	claims, err := jwtlib.GetClaimsFromContext(r.Context())
+	if err != nil {
+		log.Error("failed to get claims from context")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to get claims"))
+		return 0, false
+	}
+
+	userIDAny, ok := claims["uid"]
+	if !ok {
+		log.Error("failed to get field uid from claims")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("internal error"))
+		return 0, false
+	}
+
+	return int64(userIDAny.(float64)), true
+}
@@ -0,0 +1,178 @@
+package domains_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/domains"
+	"URLshortener/internal/http-server/handlers/domains/mocks"
+	"URLshortener/internal/lib/dnsverify"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func withClaims(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+}
+
+func TestCreateHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		host       string
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Success",
+			input:      `{"host": "Go.Example.com."}`,
+			host:       "go.example.com",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Already registered",
+			input:      `{"host": "go.example.com"}`,
+			host:       "go.example.com",
+			mockError:  storage.ErrDomainExist,
+			respStatus: http.StatusConflict,
+			respError:  "domain already registered",
+		},
+		{
+			name:       "Invalid host",
+			input:      `{"host": "not a host"}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "field Host is not valid",
+		},
+		{
+			name:       "Public host",
+			input:      `{"host": "svsevs.ru"}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "domain is reserved",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			creatorMock := mocks.NewDomainCreator(t)
+			if tc.host != "" {
+				creatorMock.On("AddDomain", mock.Anything, int64(1), tc.host, mock.AnythingOfType("string")).
+					Return(models.Domain{ID: 1, Host: tc.host, Token: "token"}, tc.mockError).
+					Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/domains", strings.NewReader(tc.input))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			domains.NewCreate(slogdiscard.NewDiscardLogger(), creatorMock, "https://svsevs.ru").ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				domains.Response
+				Error string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, "_urlshortener.go.example.com", resp.RecordName)
+				require.Equal(t, "urlshortener-verification=token", resp.RecordValue)
+			}
+		})
+	}
+}
+
+func TestVerifyHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		domain     models.Domain
+		getError   error
+		checkError error
+		markError  error
+		respStatus int
+		verified   bool
+	}{
+		{
+			name:       "Verified",
+			domain:     models.Domain{ID: 1, Host: "go.example.com", Token: "token"},
+			respStatus: http.StatusOK,
+			verified:   true,
+		},
+		{
+			name:       "Already verified",
+			domain:     models.Domain{ID: 1, Host: "go.example.com", Token: "token", Verified: true},
+			respStatus: http.StatusOK,
+			verified:   true,
+		},
+		{
+			name:       "Record not published",
+			domain:     models.Domain{ID: 1, Host: "go.example.com", Token: "token"},
+			checkError: fmt.Errorf("lookup: %w", dnsverify.ErrRecordNotFound),
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "DNS failure",
+			domain:     models.Domain{ID: 1, Host: "go.example.com", Token: "token"},
+			checkError: errors.New("timeout"),
+			respStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "Domain of another user",
+			getError:   storage.ErrDomainNotFound,
+			respStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Verified by another user first",
+			domain:     models.Domain{ID: 1, Host: "go.example.com", Token: "token"},
+			markError:  storage.ErrDomainExist,
+			respStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifierMock := mocks.NewDomainVerifier(t)
+			checkerMock := mocks.NewOwnershipChecker(t)
+
+			verifierMock.On("GetDomain", mock.Anything, int64(1), int64(1)).Return(tc.domain, tc.getError).Once()
+			if tc.getError == nil && !tc.domain.Verified {
+				checkerMock.On("Verify", mock.Anything, tc.domain.Host, tc.domain.Token).Return(tc.checkError).Once()
+				if tc.checkError == nil {
+					verifierMock.On("MarkDomainVerified", mock.Anything, int64(1), int64(1), mock.Anything).Return(tc.markError).Once()
+				}
+			}
+
+			r := chi.NewRouter()
+			r.Post("/domains/{id}/verify", domains.NewVerify(slogdiscard.NewDiscardLogger(), verifierMock, checkerMock))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withClaims(httptest.NewRequest(http.MethodPost, "/domains/1/verify", nil)))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp domains.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.verified, resp.Verified)
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package folders
+
+import (
+	"URLshortener/internal/domain/models"
+	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type CreateRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	ParentID *int64 `json:"parentId,omitempty" validate:"omitempty,min=1"`
+}
+
+// UpdateRequest renames the folder and moves it. Omitted fields are not
+// changed, parentId 0 moves the folder to the root.
+type UpdateRequest struct {
+	Name     string `json:"name,omitempty" validate:"max=100"`
+	ParentID *int64 `json:"parentId,omitempty" validate:"omitempty,min=0"`
+}
+
+// MoveRequest puts links into the folder, or out of any folder if FolderID is null.
+type MoveRequest struct {
+	IDs      []int64 `json:"ids" validate:"required,min=1,max=1000,dive,min=1"`
+	FolderID *int64  `json:"folderId" validate:"omitempty,min=1"`
+}
+
+type ListResponse struct {
+	Folders []models.Folder `json:"folders"`
+}
+
+type FolderLister interface {
+	ListFolders(ctx context.Context, userID int64) ([]models.Folder, error)
+}
+
+//go:generate mockery --name=FolderCreator --output=./mocks
+type FolderCreator interface {
+	CreateFolder(ctx context.Context, userID int64, name string, parentID *int64) (models.Folder, error)
+}
+
+//go:generate mockery --name=FolderUpdater --output=./mocks
+type FolderUpdater interface {
+	UpdateFolder(ctx context.Context, id int64, userID int64, name string, parentID *int64) error
+}
+
+type FolderDeleter interface {
+	DeleteFolder(ctx context.Context, id int64, userID int64) error
+}
+
+//go:generate mockery --name=LinkMover --output=./mocks
+type LinkMover interface {
+	MoveLinks(ctx context.Context, userID int64, linkIDs []int64, folderID *int64) error
+}
+
+// NewList returns all folders of the user. Clients build the tree from parentId.
+func NewList(log *slog.Logger, folderLister FolderLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.folders.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		folders, err := folderLister.ListFolders(r.Context(), userID)
+		if err != nil {
+			log.Error("failed to list folders", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		render.JSON(w, r, ListResponse{Folders: folders})
+	}
+}
+
+// NewCreate adds a folder of the user at the root or inside parentId.
+func NewCreate(log *slog.Logger, folderCreator FolderCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.folders.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req CreateRequest
+		if !decodeRequest(w, r, log, &req) {
+			return
+		}
+
+		folder, err := folderCreator.CreateFolder(r.Context(), userID, req.Name, req.ParentID)
+		if err != nil {
+			renderError(w, r, log, err, "failed to create folder")
+			return
+		}
+
+		log.Info("folder created", slog.Int64("id", folder.ID))
+
+		render.JSON(w, r, folder)
+	}
+}
+
+// NewUpdate renames the user's folder /folders/{id} or moves it to another parent.
+func NewUpdate(log *slog.Logger, folderUpdater FolderUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.folders.NewUpdate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := folderID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req UpdateRequest
+		if !decodeRequest(w, r, log, &req) {
+			return
+		}
+
+		if req.Name == "" && req.ParentID == nil {
+			log.Info("nothing to change")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field Name or ParentID is required"))
+			return
+		}
+
+		if err := folderUpdater.UpdateFolder(r.Context(), id, userID, req.Name, req.ParentID); err != nil {
+			renderError(w, r, log, err, "failed to update folder")
+			return
+		}
+
+		log.Info("folder updated", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NewDelete deletes the user's folder /folders/{id}. Its links and
+// subfolders are moved to its parent.
+func NewDelete(log *slog.Logger, folderDeleter FolderDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.folders.NewDelete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := folderID(w, r, log)
+		if !ok {
+			return
+		}
+
+		if err := folderDeleter.DeleteFolder(r.Context(), id, userID); err != nil {
+			renderError(w, r, log, err, "failed to delete folder")
+			return
+		}
+
+		log.Info("folder deleted", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NewMove puts the user's links into a folder. Either all links are moved
+// or, if one of them is not found, none of them.
+func NewMove(log *slog.Logger, linkMover LinkMover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.folders.NewMove"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req MoveRequest
+		if !decodeRequest(w, r, log, &req) {
+			return
+		}
+
+		err := linkMover.MoveLinks(r.Context(), userID, req.IDs, req.FolderID)
+		if errors.Is(err, storage.ErrAliasNotFound) {
+			log.Info("some links not found")
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("some links not found"))
+			return
+		}
+		if err != nil {
+			renderError(w, r, log, err, "failed to move links")
+			return
+		}
+
+		log.Info("links moved", slog.Int("links", len(req.IDs)))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// renderError answers with the status matching the folder storage error.
+func renderError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, msg string) {
+	switch {
+	case errors.Is(err, storage.ErrFolderNotFound):
+		log.Info("folder not found", sl.Err(err))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error("folder not found"))
+	case errors.Is(err, storage.ErrFolderExist):
+		log.Info("folder already exists", sl.Err(err))
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, resp.Error("folder with this name already exists"))
+	case errors.Is(err, storage.ErrFolderCycle):
+		log.Info("folder cycle", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(storage.ErrFolderCycle.Error()))
+	default:
+		log.Error(msg, sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error(msg))
+	}
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, log *slog.Logger, req any) bool {
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		log.Error("failed to decode request body", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("failed to decode request"))
+		return false
+	}
+
+	switch req := req.(type) {
+	case *CreateRequest:
+		req.Name = strings.TrimSpace(req.Name)
+	case *UpdateRequest:
+		req.Name = strings.TrimSpace(req.Name)
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		validateErr := err.(validator.ValidationErrors)
+
+		log.Info("invalid request", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(resp.ValidationError(validateErr)))
+		return false
+	}
+
+	return true
+}
+
+func folderID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		log.Info("invalid folder id", slog.String("id", chi.URLParam(r, "id")))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("invalid folder id"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func userIDFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	// Берем из контекста данные JWT токена
+	claims, err := jwtlib.GetClaimsFromContext(r.Context())
+	if err != nil {
+		log.Error("failed to get claims from context")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to get claims"))
+		return 0, false
+	}
+
+	userIDAny, ok := claims["uid"]
+	if !ok {
+		log.Error("failed to get field uid from claims")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("internal error"))
+		return 0, false
+	}
+
+	return int64(userIDAny.(float64)), true
+}
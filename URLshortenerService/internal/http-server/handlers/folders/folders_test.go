@@ -0,0 +1,257 @@
+package folders_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/folders"
+	"URLshortener/internal/http-server/handlers/folders/mocks"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func withClaims(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+}
+
+func ptr(v int64) *int64 {
+	return &v
+}
+
+func TestCreateHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		folder     string
+		parentID   *int64
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Root folder",
+			input:      `{"name": " Projects "}`,
+			folder:     "Projects",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Subfolder",
+			input:      `{"name": "2024", "parentId": 3}`,
+			folder:     "2024",
+			parentID:   ptr(3),
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Parent not found",
+			input:      `{"name": "2024", "parentId": 4}`,
+			folder:     "2024",
+			parentID:   ptr(4),
+			mockError:  storage.ErrFolderNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "folder not found",
+		},
+		{
+			name:       "Already exists",
+			input:      `{"name": "Projects"}`,
+			folder:     "Projects",
+			mockError:  storage.ErrFolderExist,
+			respStatus: http.StatusConflict,
+			respError:  "folder with this name already exists",
+		},
+		{
+			name:       "Empty name",
+			input:      `{"name": ""}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "field Name is a required field",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			creatorMock := mocks.NewFolderCreator(t)
+			if tc.folder != "" {
+				creatorMock.On("CreateFolder", mock.Anything, int64(1), tc.folder, tc.parentID).
+					Return(models.Folder{ID: 1, Name: tc.folder, ParentID: tc.parentID}, tc.mockError).
+					Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/folders", strings.NewReader(tc.input))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			folders.NewCreate(slogdiscard.NewDiscardLogger(), creatorMock).ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				models.Folder
+				Error string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.folder, resp.Name)
+				require.Equal(t, tc.parentID, resp.ParentID)
+			}
+		})
+	}
+}
+
+func TestUpdateHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		folder     string
+		parentID   *int64
+		callMock   bool
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Rename",
+			input:      `{"name": "Archive"}`,
+			folder:     "Archive",
+			callMock:   true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Move to root",
+			input:      `{"parentId": 0}`,
+			parentID:   ptr(0),
+			callMock:   true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Move into itself",
+			input:      `{"parentId": 5}`,
+			parentID:   ptr(5),
+			callMock:   true,
+			mockError:  storage.ErrFolderCycle,
+			respStatus: http.StatusBadRequest,
+			respError:  storage.ErrFolderCycle.Error(),
+		},
+		{
+			name:       "Nothing to change",
+			input:      `{}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "field Name or ParentID is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			updaterMock := mocks.NewFolderUpdater(t)
+			if tc.callMock {
+				updaterMock.On("UpdateFolder", mock.Anything, int64(5), int64(1), tc.folder, tc.parentID).
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Patch("/folders/{id}", folders.NewUpdate(slogdiscard.NewDiscardLogger(), updaterMock))
+
+			req := httptest.NewRequest(http.MethodPatch, "/folders/5", strings.NewReader(tc.input))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				Error string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
+
+func TestMoveHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		ids        []int64
+		folderID   *int64
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Into folder",
+			input:      `{"ids": [1, 2], "folderId": 3}`,
+			ids:        []int64{1, 2},
+			folderID:   ptr(3),
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Out of folder",
+			input:      `{"ids": [1], "folderId": null}`,
+			ids:        []int64{1},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Links not found",
+			input:      `{"ids": [1, 9], "folderId": 3}`,
+			ids:        []int64{1, 9},
+			folderID:   ptr(3),
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "some links not found",
+		},
+		{
+			name:       "Folder not found",
+			input:      `{"ids": [1], "folderId": 4}`,
+			ids:        []int64{1},
+			folderID:   ptr(4),
+			mockError:  storage.ErrFolderNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "folder not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			moverMock := mocks.NewLinkMover(t)
+			moverMock.On("MoveLinks", mock.Anything, int64(1), tc.ids, tc.folderID).
+				Return(tc.mockError).
+				Once()
+
+			req := httptest.NewRequest(http.MethodPost, "/links/folder", strings.NewReader(tc.input))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			folders.NewMove(slogdiscard.NewDiscardLogger(), moverMock).ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				Error string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
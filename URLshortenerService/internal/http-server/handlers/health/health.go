@@ -0,0 +1,79 @@
+package health
+
+import (
+	"URLshortener/internal/lib/buildinfo"
+	"URLshortener/internal/lib/logger/sl"
+	"context"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Check is a dependency the service cannot serve requests without.
+type Check struct {
+	Name   string
+	Pinger Pinger
+}
+
+type Response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// NewLiveness answers as long as the process serves http at all.
+func NewLiveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, Response{Status: StatusOK})
+	}
+}
+
+// NewReadiness pings every check and answers 503 if any of them fails or
+// does not answer within the timeout. The reasons are only logged, since
+// the endpoint is public.
+func NewReadiness(log *slog.Logger, timeout time.Duration, checks ...Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.NewReadiness"
+
+		log := log.With(
+			slog.String("op", op),
+		)
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		res := Response{Status: StatusOK, Checks: make(map[string]string, len(checks))}
+		for _, check := range checks {
+			if err := check.Pinger.Ping(ctx); err != nil {
+				log.Error("dependency is unavailable", slog.String("check", check.Name), sl.Err(err))
+				res.Checks[check.Name] = StatusUnavailable
+				res.Status = StatusUnavailable
+				continue
+			}
+			res.Checks[check.Name] = StatusOK
+		}
+
+		if res.Status != StatusOK {
+			render.Status(r, http.StatusServiceUnavailable)
+		}
+		render.JSON(w, r, res)
+	}
+}
+
+// NewVersion returns the build metadata of the binary.
+func NewVersion() http.HandlerFunc {
+	info := buildinfo.Get()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, info)
+	}
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"URLshortener/internal/lib/buildinfo"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestReadiness(t *testing.T) {
+	ok := pingerFunc(func(context.Context) error { return nil })
+	down := pingerFunc(func(context.Context) error { return errors.New("connection refused") })
+	hanging := pingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	cases := []struct {
+		name       string
+		checks     []Check
+		wantStatus int
+		wantBody   Response
+	}{
+		{
+			name:       "all up",
+			checks:     []Check{{Name: "database", Pinger: ok}},
+			wantStatus: http.StatusOK,
+			wantBody:   Response{Status: StatusOK, Checks: map[string]string{"database": StatusOK}},
+		},
+		{
+			name:       "database down",
+			checks:     []Check{{Name: "database", Pinger: down}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   Response{Status: StatusUnavailable, Checks: map[string]string{"database": StatusUnavailable}},
+		},
+		{
+			name:       "ping times out",
+			checks:     []Check{{Name: "database", Pinger: ok}, {Name: "sessions", Pinger: hanging}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: Response{Status: StatusUnavailable, Checks: map[string]string{
+				"database": StatusOK,
+				"sessions": StatusUnavailable,
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewReadiness(slog.New(slog.NewTextHandler(io.Discard, nil)), 50*time.Millisecond, tc.checks...)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, tc.wantStatus, rr.Code)
+
+			var body Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.wantBody, body)
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	version := buildinfo.Version
+	buildinfo.Version = "v1.2.3"
+	t.Cleanup(func() { buildinfo.Version = version })
+
+	rr := httptest.NewRecorder()
+	NewVersion().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body buildinfo.Info
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, "v1.2.3", body.Version)
+	require.NotEmpty(t, body.GoVersion)
+}
@@ -0,0 +1,214 @@
+package qr
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/api"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	qrlib "URLshortener/internal/lib/qr"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+type request struct {
+	Format string `validate:"oneof=png svg"`
+	Size   int    `validate:"min=64,max=2048"`
+	Level  string `validate:"oneof=L M Q H"`
+	Margin int    `validate:"min=0,max=16"`
+	FG     string
+	BG     string
+}
+
+//go:generate mockery --name=AliasResolver --output=./mocks
+type AliasResolver interface {
+	ResolveAlias(ctx context.Context, host string, alias string) (models.AliasNote, error)
+}
+
+type ImageCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+}
+
+// New returns a public handler that renders a QR code of the short URL.
+// The format is taken from ?format= or the extension: /{alias}/qr.svg.
+// Rendered images are cached by the short URL and drawing options.
+func New(log *slog.Logger, aliasResolver AliasResolver, cache ImageCache, publicURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.qr.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		req, opts, ok := parseRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		note, err := aliasResolver.ResolveAlias(r.Context(), api.RequestHost(r), alias)
+		switch {
+		case errors.Is(err, storage.ErrAliasNotFound):
+			log.Info("alias not found", slog.String("alias", alias))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+			return
+		case err != nil:
+			log.Error("failed to get url", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		if note.Expired(time.Now()) {
+			log.Info("link expired", slog.String("alias", alias))
+
+			render.Status(r, http.StatusGone)
+			render.JSON(w, r, resp.Error("link expired"))
+			return
+		}
+
+		shortURL := models.ShortURL(publicURL, note.Domain, note.Alias)
+		key := fmt.Sprintf("%s|%s|%d|%s|%d|%s|%s", shortURL, req.Format, req.Size, req.Level, req.Margin, req.FG, req.BG)
+
+		image, ok := cache.Get(key)
+		if !ok {
+			if req.Format == FormatSVG {
+				image, err = qrlib.SVG(shortURL, opts)
+			} else {
+				image, err = qrlib.PNG(shortURL, opts)
+			}
+			if err != nil {
+				log.Error("failed to render qr code", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to render qr code"))
+				return
+			}
+
+			cache.Set(key, image)
+		}
+
+		if req.Format == FormatSVG {
+			w.Header().Set("Content-Type", "image/svg+xml")
+		} else {
+			w.Header().Set("Content-Type", "image/png")
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(image)))
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := w.Write(image); err != nil {
+			log.Info("failed to write qr code", sl.Err(err))
+		}
+	}
+}
+
+// parseRequest reads ?format=png|svg&size=<px>&level=L|M|Q|H&margin=<modules>&fg=<hex>&bg=<hex>.
+// By default a black on white 256px PNG with level M and a margin of 4 modules is rendered.
+func parseRequest(w http.ResponseWriter, r *http.Request, log *slog.Logger) (request, qrlib.Options, bool) {
+	q := r.URL.Query()
+
+	req := request{
+		Format: strings.ToLower(q.Get("format")),
+		Size:   256,
+		Level:  strings.ToUpper(q.Get("level")),
+		Margin: 4,
+		FG:     strings.ToLower(strings.TrimPrefix(q.Get("fg"), "#")),
+		BG:     strings.ToLower(strings.TrimPrefix(q.Get("bg"), "#")),
+	}
+	if req.Format == "" {
+		if format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string); format != "" {
+			req.Format = format
+		} else {
+			req.Format = FormatPNG
+		}
+	}
+	if req.Level == "" {
+		req.Level = "M"
+	}
+	if req.FG == "" {
+		req.FG = "000000"
+	}
+	if req.BG == "" {
+		req.BG = "ffffff"
+	}
+
+	for field, dst := range map[string]*int{"size": &req.Size, "margin": &req.Margin} {
+		v := q.Get(field)
+		if v == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			log.Info("invalid "+field+" parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field "+field+" must be a number"))
+			return request{}, qrlib.Options{}, false
+		}
+		*dst = n
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		validateErr := err.(validator.ValidationErrors)
+
+		log.Info("invalid request", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(resp.ValidationError(validateErr)))
+		return request{}, qrlib.Options{}, false
+	}
+
+	fg, err := qrlib.ParseColor(req.FG)
+	if err != nil {
+		log.Info("invalid fg parameter", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("field fg must be a hex color"))
+		return request{}, qrlib.Options{}, false
+	}
+
+	bg, err := qrlib.ParseColor(req.BG)
+	if err != nil {
+		log.Info("invalid bg parameter", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("field bg must be a hex color"))
+		return request{}, qrlib.Options{}, false
+	}
+
+	level, _ := qrlib.ParseLevel(req.Level)
+
+	return req, qrlib.Options{
+		Size:       req.Size,
+		Level:      level,
+		Margin:     req.Margin,
+		Foreground: fg,
+		Background: bg,
+	}, true
+}
@@ -0,0 +1,168 @@
+package qr_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/qr"
+	"URLshortener/internal/http-server/handlers/qr/mocks"
+	"URLshortener/internal/lib/cache"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+	"bytes"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQRHandler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		path        string
+		alias       string
+		note        models.AliasNote
+		mockError   error
+		respStatus  int
+		contentType string
+	}{
+		{
+			name:        "PNG by default",
+			path:        "/google/qr",
+			alias:       "google",
+			note:        models.AliasNote{Alias: "google", Url: "https://www.google.com/"},
+			respStatus:  http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:        "SVG by extension",
+			path:        "/google/qr.svg",
+			alias:       "google",
+			note:        models.AliasNote{Alias: "google", Url: "https://www.google.com/"},
+			respStatus:  http.StatusOK,
+			contentType: "image/svg+xml",
+		},
+		{
+			name:        "SVG by query with options",
+			path:        "/google/qr?format=svg&size=512&level=h&margin=0&fg=%23336699&bg=fff",
+			alias:       "google",
+			note:        models.AliasNote{Alias: "google", Url: "https://www.google.com/"},
+			respStatus:  http.StatusOK,
+			contentType: "image/svg+xml",
+		},
+		{
+			name:       "Invalid size",
+			path:       "/google/qr?size=10",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid color",
+			path:       "/google/qr?fg=red",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid format",
+			path:       "/google/qr?format=gif",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Expired",
+			path: "/google/qr",
+			note: models.AliasNote{
+				Alias:     "google",
+				Url:       "https://www.google.com/",
+				ExpiresAt: ptr(time.Now().Add(-time.Hour)),
+			},
+			alias:      "google",
+			respStatus: http.StatusGone,
+		},
+		{
+			name:       "Not found",
+			path:       "/unknown/qr",
+			alias:      "unknown",
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Storage error",
+			path:       "/google/qr",
+			alias:      "google",
+			mockError:  errors.New("unexpected error"),
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolverMock := mocks.NewAliasResolver(t)
+			if tc.alias != "" {
+				resolverMock.On("ResolveAlias", mock.Anything, "example.com", tc.alias).Return(tc.note, tc.mockError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Use(middleware.URLFormat)
+			r.Get("/{alias}/qr", qr.New(
+				slogdiscard.NewDiscardLogger(),
+				resolverMock,
+				cache.New[string, []byte](10, time.Minute),
+				"https://svsevs.ru/",
+			))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+			if tc.contentType != "" {
+				require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestQRHandler_Cache(t *testing.T) {
+	note := models.AliasNote{Alias: "google", Url: "https://www.google.com/"}
+
+	resolverMock := mocks.NewAliasResolver(t)
+	resolverMock.On("ResolveAlias", mock.Anything, "example.com", "google").Return(note, nil).Twice()
+
+	images := cache.New[string, []byte](10, time.Minute)
+
+	r := chi.NewRouter()
+	r.Get("/{alias}/qr", qr.New(slogdiscard.NewDiscardLogger(), resolverMock, images, "https://svsevs.ru"))
+
+	var bodies [][]byte
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/google/qr?size=300", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		bodies = append(bodies, rr.Body.Bytes())
+	}
+
+	require.Equal(t, 1, images.Len())
+	require.Equal(t, bodies[0], bodies[1])
+
+	img, err := png.Decode(bytes.NewReader(bodies[0]))
+	require.NoError(t, err)
+	require.Equal(t, 300, img.Bounds().Dx())
+
+	// другие параметры рисуются отдельно
+	resolverMock.On("ResolveAlias", mock.Anything, "example.com", "google").Return(note, nil).Once()
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/google/qr?format=svg", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.True(t, strings.HasPrefix(rr.Body.String(), "<svg"))
+	require.Equal(t, 2, images.Len())
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
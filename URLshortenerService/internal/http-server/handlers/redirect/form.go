@@ -0,0 +1,44 @@
+package redirect
+
+import (
+	"html/template"
+	"net/http"
+)
+
+var formTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta name="robots" content="noindex">
+    <title>Ссылка защищена паролем</title>
+    <style>
+        body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+        form { display: flex; flex-direction: column; gap: 12px; width: 280px; }
+        .error { color: #c0392b; }
+    </style>
+</head>
+<body>
+    <form method="post" action="">
+        <h3>Ссылка /{{.Alias}} защищена паролем</h3>
+        {{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+        <input type="password" name="password" placeholder="Пароль" autofocus required>
+        <button type="submit">Перейти</button>
+    </form>
+</body>
+</html>
+`))
+
+func renderForm(w http.ResponseWriter, status int, alias string, errMsg string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	_ = formTemplate.Execute(w, struct {
+		Alias string
+		Error string
+	}{
+		Alias: alias,
+		Error: errMsg,
+	})
+}
@@ -1,27 +1,59 @@
 package redirect
 
 import (
-	jwtlib "URLshortener/internal/jwt"
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/api"
 	resp "URLshortener/internal/lib/api/response"
 	"URLshortener/internal/lib/logger/sl"
 	"URLshortener/internal/storage"
+	"context"
 	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"golang.org/x/crypto/bcrypt"
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
-type URLGetter interface {
-	GetURL(alias string, userID int64) (string, error)
+// PasswordHeader carries the password of a protected link for non-browser clients.
+const PasswordHeader = "X-Link-Password"
+
+//go:generate mockery --name=AliasResolver --output=./mocks
+type AliasResolver interface {
+	ResolveAlias(ctx context.Context, host string, alias string) (models.AliasNote, error)
+	ConsumeClick(ctx context.Context, id int64) error
+}
+
+//go:generate mockery --name=ClickRecorder --output=./mocks
+type ClickRecorder interface {
+	Record(click models.Click)
 }
 
-func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
+// AttemptLimiter reserves a password attempt before the password is checked,
+// so parallel guesses can not exceed the limit. succeed is called only when
+// the password is correct.
+type AttemptLimiter interface {
+	Allow(key string) (succeed func(), ok bool, retryAfter time.Duration)
+}
+
+// New returns a public handler that redirects the visitor to the URL behind the alias.
+// It does not require a token, so short links work from any client.
+// Password-protected links are redirected only after the password is verified.
+func New(
+	log *slog.Logger,
+	aliasResolver AliasResolver,
+	clickRecorder ClickRecorder,
+	attemptLimiter AttemptLimiter,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.redirect.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -35,24 +67,7 @@ func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
 			return
 		}
 
-		claims, err := jwtlib.GetClaimsFromContext(r.Context())
-		if err != nil {
-			log.Error("failed to get claims from context")
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error("failed to get claims"))
-			return
-		}
-
-		userIDAny, ok := claims["uid"]
-		if !ok {
-			log.Error("failed to get field uid from claims")
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error("internal error"))
-			return
-		}
-		userID := int64(userIDAny.(float64))
-
-		resURL, err := urlGetter.GetURL(alias, userID)
+		note, err := aliasResolver.ResolveAlias(r.Context(), api.RequestHost(r), alias)
 		switch {
 		case errors.Is(err, storage.ErrAliasNotFound):
 			log.Info("alias not found", slog.String("alias", alias))
@@ -68,11 +83,128 @@ func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
 			return
 		}
 
-		log.Info("got url", slog.String("url", resURL))
+		if note.Expired(time.Now()) {
+			log.Info("link expired", slog.String("alias", alias))
+
+			render.Status(r, http.StatusGone)
+			render.JSON(w, r, resp.Error("link expired"))
+			return
+		}
+
+		code := StatusCode(note.RedirectCode)
+
+		if note.Protected {
+			if !checkPassword(w, r, log, note, attemptLimiter) {
+				return
+			}
+
+			// Иначе браузер закеширует редирект и пароль больше не спросит
+			w.Header().Set("Cache-Control", "no-store")
+			code = http.StatusFound
+			if r.Method == http.MethodPost {
+				code = http.StatusSeeOther
+			}
+		}
+
+		// Ссылки с лимитом переходов списывают клик синхронно, иначе лимит можно обойти
+		if note.MaxClicks != nil {
+			err := aliasResolver.ConsumeClick(r.Context(), note.ID)
+			switch {
+			case errors.Is(err, storage.ErrLinkExpired):
+				log.Info("link click limit reached", slog.String("alias", alias))
+
+				render.Status(r, http.StatusGone)
+				render.JSON(w, r, resp.Error("link expired"))
+				return
+			case err != nil:
+				log.Error("failed to consume click", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("internal error"))
+				return
+			}
+		}
+
+		log.Info("got url", slog.String("url", note.Url))
+
+		clickRecorder.Record(models.Click{
+			URLID:     note.ID,
+			Alias:     note.Alias,
+			ClickedAt: time.Now(),
+			Referrer:  r.Referer(),
+			UserAgent: r.UserAgent(),
+			IP:        clientIP(r),
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+
+		http.Redirect(w, r, note.Url, code)
+	}
+}
+
+// checkPassword verifies the password of a protected link. It writes the
+// password form or an error response itself and returns false if the
+// visitor must not be redirected. The password is accepted from the header
+// or the form only: a query parameter would end up in access logs and spans.
+func checkPassword(
+	w http.ResponseWriter,
+	r *http.Request,
+	log *slog.Logger,
+	note models.AliasNote,
+	attemptLimiter AttemptLimiter,
+) bool {
+	password := r.Header.Get(PasswordHeader)
+	if password == "" && r.Method == http.MethodPost {
+		password = r.PostFormValue("password")
+	}
+
+	if password == "" {
+		renderForm(w, http.StatusOK, note.Alias, "")
+		return false
+	}
+
+	// Одинаковые алиасы на разных доменах — разные ссылки
+	key := note.Domain + "/" + note.Alias
+
+	succeed, ok, retryAfter := attemptLimiter.Allow(key)
+	if !ok {
+		log.Warn("too many password attempts", slog.String("alias", note.Alias))
+
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		render.Status(r, http.StatusTooManyRequests)
+		render.JSON(w, r, resp.Error("too many attempts"))
+		return false
+	}
+
+	if err := bcrypt.CompareHashAndPassword(note.PasswordHash, []byte(password)); err != nil {
+		log.Info("invalid link password", slog.String("alias", note.Alias))
+
+		renderForm(w, http.StatusForbidden, note.Alias, "Неверный пароль")
+		return false
+	}
+
+	// После опечаток верный пароль не должен оставлять ссылку на грани блокировки
+	succeed()
+
+	return true
+}
+
+// StatusCode returns code if it is one of the supported redirect statuses
+// and http.StatusFound otherwise.
+func StatusCode(code int) int {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return code
+	default:
+		return http.StatusFound
+	}
+}
 
-		//http.Redirect(w, r, resURL, http.StatusFound)
-		w.Header().Set("Location", resURL)
-		w.WriteHeader(http.StatusOK)
-		return
+// clientIP strips the port from RemoteAddr, which is already set from
+// X-Real-IP by the realip middleware.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
+	return host
 }
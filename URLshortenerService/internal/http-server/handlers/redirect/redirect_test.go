@@ -0,0 +1,243 @@
+package redirect_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/redirect"
+	"URLshortener/internal/http-server/handlers/redirect/mocks"
+	"URLshortener/internal/lib/attempts"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		host      string
+		domain    string
+		alias     string
+		url       string
+		code      int
+		expiresAt *time.Time
+		maxClicks *int64
+		consume   error
+		mockError error
+		status    int
+	}{
+		{
+			name:   "Found by default",
+			alias:  "test_alias",
+			url:    "https://www.google.com/",
+			status: http.StatusFound,
+		},
+		{
+			name:   "Permanent redirect",
+			alias:  "perm",
+			url:    "https://www.google.com/",
+			code:   http.StatusMovedPermanently,
+			status: http.StatusMovedPermanently,
+		},
+		{
+			name:   "Temporary redirect",
+			alias:  "temp",
+			url:    "https://www.google.com/",
+			code:   http.StatusTemporaryRedirect,
+			status: http.StatusTemporaryRedirect,
+		},
+		{
+			name:      "Expired by time",
+			alias:     "expired",
+			url:       "https://www.google.com/",
+			expiresAt: ptr(time.Now().Add(-time.Minute)),
+			status:    http.StatusGone,
+		},
+		{
+			name:      "Not expired yet",
+			alias:     "fresh",
+			url:       "https://www.google.com/",
+			expiresAt: ptr(time.Now().Add(time.Hour)),
+			status:    http.StatusFound,
+		},
+		{
+			name:      "Click consumed",
+			alias:     "once",
+			url:       "https://www.google.com/",
+			maxClicks: ptr(int64(1)),
+			status:    http.StatusFound,
+		},
+		{
+			name:      "Click limit reached concurrently",
+			alias:     "raced",
+			url:       "https://www.google.com/",
+			maxClicks: ptr(int64(1)),
+			consume:   storage.ErrLinkExpired,
+			status:    http.StatusGone,
+		},
+		{
+			name:   "Custom domain",
+			host:   "Go.Example.com:443",
+			domain: "go.example.com",
+			alias:  "branded",
+			url:    "https://www.google.com/",
+			status: http.StatusFound,
+		},
+		{
+			name:      "Alias not found",
+			alias:     "missing",
+			mockError: storage.ErrAliasNotFound,
+			status:    http.StatusNotFound,
+		},
+		{
+			name:      "Storage error",
+			alias:     "broken",
+			mockError: errors.New("unexpected error"),
+			status:    http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolverMock := mocks.NewAliasResolver(t)
+			resolverMock.On("ResolveAlias", mock.Anything, tc.domain, tc.alias).
+				Return(models.AliasNote{
+					ID:           1,
+					Url:          tc.url,
+					Alias:        tc.alias,
+					RedirectCode: tc.code,
+					ExpiresAt:    tc.expiresAt,
+					MaxClicks:    tc.maxClicks,
+				}, tc.mockError).
+				Once()
+
+			if tc.maxClicks != nil {
+				resolverMock.On("ConsumeClick", mock.Anything, int64(1)).Return(tc.consume).Once()
+			}
+
+			redirected := tc.mockError == nil && tc.status != http.StatusGone
+
+			recorderMock := mocks.NewClickRecorder(t)
+			if redirected {
+				recorderMock.On("Record", mock.MatchedBy(func(c models.Click) bool {
+					return c.Alias == tc.alias && c.IP == "10.0.0.1" && c.Referrer == "https://example.com/"
+				})).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), resolverMock, recorderMock, attempts.New(5, time.Minute)))
+
+			req, err := http.NewRequest(http.MethodGet, "/"+tc.alias, nil)
+			require.NoError(t, err)
+			req.Host = tc.host
+			req.RemoteAddr = "10.0.0.1:12345"
+			req.Header.Set("Referer", "https://example.com/")
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			if redirected {
+				require.Equal(t, tc.url, rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestRedirectHandler_Password(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	note := models.AliasNote{
+		ID:           1,
+		Url:          "https://www.google.com/",
+		Alias:        "private",
+		RedirectCode: http.StatusMovedPermanently,
+		Protected:    true,
+		PasswordHash: hash,
+	}
+
+	resolverMock := mocks.NewAliasResolver(t)
+	resolverMock.On("ResolveAlias", mock.Anything, "example.com", "private").Return(note, nil)
+
+	recorderMock := mocks.NewClickRecorder(t)
+	recorderMock.On("Record", mock.Anything)
+
+	limiter := attempts.New(2, time.Minute)
+	handler := redirect.New(slogdiscard.NewDiscardLogger(), resolverMock, recorderMock, limiter)
+
+	r := chi.NewRouter()
+	r.Get("/{alias}", handler)
+	r.Post("/{alias}", handler)
+
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// без пароля показывается форма
+	rr := do(httptest.NewRequest(http.MethodGet, "/private", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `name="password"`)
+
+	// пароль в заголовке, постоянный редирект заменяется временным
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set(redirect.PasswordHeader, "secret")
+	rr = do(req)
+	require.Equal(t, http.StatusFound, rr.Code)
+	require.Equal(t, note.Url, rr.Header().Get("Location"))
+	require.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+	// пароль из формы
+	form := url.Values{"password": {"secret"}}.Encode()
+	req = httptest.NewRequest(http.MethodPost, "/private", strings.NewReader(form))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = do(req)
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+
+	// пароль в query не принимается, он попал бы в логи
+	rr = do(httptest.NewRequest(http.MethodGet, "/private?password=secret", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `name="password"`)
+
+	withPassword := func(password string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set(redirect.PasswordHeader, password)
+		return req
+	}
+
+	// верный пароль сбрасывает счетчик ошибок
+	require.Equal(t, http.StatusForbidden, do(withPassword("wrong")).Code)
+	require.Equal(t, http.StatusFound, do(withPassword("secret")).Code)
+	require.Equal(t, http.StatusForbidden, do(withPassword("wrong")).Code)
+	require.Equal(t, http.StatusFound, do(withPassword("secret")).Code)
+
+	// неверный пароль, затем блокировка
+	for i := 0; i < 2; i++ {
+		rr = do(withPassword("wrong"))
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	}
+
+	rr = do(withPassword("secret"))
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.NotEmpty(t, rr.Header().Get("Retry-After"))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
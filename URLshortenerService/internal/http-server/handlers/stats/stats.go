@@ -0,0 +1,206 @@
+package stats
+
+import (
+	"URLshortener/internal/domain/models"
+	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultPeriod = 30 * 24 * time.Hour
+
+type request struct {
+	Bucket string    `validate:"oneof=hour day week"`
+	From   time.Time `validate:"required"`
+	To     time.Time `validate:"required,gtfield=From"`
+}
+
+type Response struct {
+	models.Stats
+	Alias  string    `json:"alias,omitempty"`
+	Domain string    `json:"domain,omitempty"`
+	Bucket string    `json:"bucket"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+type LinkStatsProvider interface {
+	GetLinkStats(ctx context.Context, domain string, alias string, userID int64, bucket string, from, to time.Time) (models.Stats, error)
+}
+
+type UserStatsProvider interface {
+	GetUserStats(ctx context.Context, userID int64, bucket string, from, to time.Time) (models.Stats, error)
+}
+
+// NewLinkStats returns clicks of a single link owned by the user.
+// Links on a custom domain are selected with ?domain=<host>.
+func NewLinkStats(log *slog.Logger, provider LinkStatsProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.stats.NewLinkStats"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("invalid request"))
+			return
+		}
+
+		req, ok := parseRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		domain := strings.ToLower(r.URL.Query().Get("domain"))
+
+		stats, err := provider.GetLinkStats(r.Context(), domain, alias, userID, req.Bucket, req.From, req.To)
+		switch {
+		case errors.Is(err, storage.ErrAliasNotFound):
+			log.Info("alias not found", slog.String("alias", alias))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("alias not found"))
+			return
+		case err != nil:
+			log.Error("failed to get link stats", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Stats:  stats,
+			Alias:  alias,
+			Domain: domain,
+			Bucket: req.Bucket,
+			From:   req.From,
+			To:     req.To,
+		})
+	}
+}
+
+// NewUserStats returns clicks of all links owned by the user.
+func NewUserStats(log *slog.Logger, provider UserStatsProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.stats.NewUserStats"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		req, ok := parseRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		stats, err := provider.GetUserStats(r.Context(), userID, req.Bucket, req.From, req.To)
+		if err != nil {
+			log.Error("failed to get user stats", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		render.JSON(w, r, Response{
+			Stats:  stats,
+			Bucket: req.Bucket,
+			From:   req.From,
+			To:     req.To,
+		})
+	}
+}
+
+func userIDFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	// Берем из контекста данные JWT токена
+	claims, err := jwtlib.GetClaimsFromContext(r.Context())
+	if err != nil {
+		log.Error("failed to get claims from context")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to get claims"))
+		return 0, false
+	}
+
+	userIDAny, ok := claims["uid"]
+	if !ok {
+		log.Error("failed to get field uid from claims")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("internal error"))
+		return 0, false
+	}
+
+	return int64(userIDAny.(float64)), true
+}
+
+// parseRequest reads ?bucket=hour|day|week&from=<RFC3339>&to=<RFC3339>.
+// By default the last 30 days are returned by days.
+func parseRequest(w http.ResponseWriter, r *http.Request, log *slog.Logger) (request, bool) {
+	q := r.URL.Query()
+
+	req := request{
+		Bucket: q.Get("bucket"),
+		To:     time.Now().UTC(),
+	}
+	if req.Bucket == "" {
+		req.Bucket = "day"
+	}
+
+	if to := q.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			log.Info("invalid to parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field to must be RFC3339 time"))
+			return request{}, false
+		}
+		req.To = t
+	}
+
+	req.From = req.To.Add(-defaultPeriod)
+	if from := q.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			log.Info("invalid from parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field from must be RFC3339 time"))
+			return request{}, false
+		}
+		req.From = t
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		validateErr := err.(validator.ValidationErrors)
+
+		log.Info("invalid request", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(resp.ValidationError(validateErr)))
+		return request{}, false
+	}
+
+	return req, true
+}
@@ -0,0 +1,349 @@
+package tags
+
+import (
+	"URLshortener/internal/domain/models"
+	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Request struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+// BulkRequest adds and removes tags of several links at once.
+type BulkRequest struct {
+	IDs    []int64  `json:"ids" validate:"required,min=1,max=1000,dive,min=1"`
+	Add    []string `json:"add,omitempty" validate:"max=50,dive,required,max=50"`
+	Remove []string `json:"remove,omitempty" validate:"max=50,dive,required,max=50"`
+}
+
+type ListResponse struct {
+	Tags []models.Tag `json:"tags"`
+}
+
+type TagLister interface {
+	ListTags(ctx context.Context, userID int64) ([]models.Tag, error)
+}
+
+//go:generate mockery --name=TagCreator --output=./mocks
+type TagCreator interface {
+	CreateTag(ctx context.Context, userID int64, name string) (models.Tag, error)
+}
+
+type TagRenamer interface {
+	RenameTag(ctx context.Context, id int64, userID int64, name string) error
+}
+
+type TagDeleter interface {
+	DeleteTag(ctx context.Context, id int64, userID int64) error
+}
+
+//go:generate mockery --name=LinkTagger --output=./mocks
+type LinkTagger interface {
+	TagLinks(ctx context.Context, userID int64, linkIDs []int64, add []string, remove []string) error
+}
+
+// NewList returns all tags of the user with the number of tagged links.
+func NewList(log *slog.Logger, tagLister TagLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.tags.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		tags, err := tagLister.ListTags(r.Context(), userID)
+		if err != nil {
+			log.Error("failed to list tags", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		render.JSON(w, r, ListResponse{Tags: tags})
+	}
+}
+
+// NewCreate adds a tag of the user. Tag names are case-insensitive.
+func NewCreate(log *slog.Logger, tagCreator TagCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.tags.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		req, ok := decodeRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		tag, err := tagCreator.CreateTag(r.Context(), userID, req.Name)
+		switch {
+		case errors.Is(err, storage.ErrTagExist):
+			log.Info("tag already exists", slog.String("name", req.Name))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("tag already exists"))
+			return
+		case err != nil:
+			log.Error("failed to create tag", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to create tag"))
+			return
+		}
+
+		log.Info("tag created", slog.Int64("id", tag.ID))
+
+		render.JSON(w, r, tag)
+	}
+}
+
+// NewRename changes the name of the user's tag /tags/{id}.
+func NewRename(log *slog.Logger, tagRenamer TagRenamer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.tags.NewRename"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := tagID(w, r, log)
+		if !ok {
+			return
+		}
+
+		req, ok := decodeRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		err := tagRenamer.RenameTag(r.Context(), id, userID, req.Name)
+		switch {
+		case errors.Is(err, storage.ErrTagNotFound):
+			log.Info("tag not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("tag not found"))
+			return
+		case errors.Is(err, storage.ErrTagExist):
+			log.Info("tag already exists", slog.String("name", req.Name))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("tag already exists"))
+			return
+		case err != nil:
+			log.Error("failed to rename tag", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to rename tag"))
+			return
+		}
+
+		log.Info("tag renamed", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NewDelete removes the user's tag /tags/{id} from all links and deletes it.
+func NewDelete(log *slog.Logger, tagDeleter TagDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.tags.NewDelete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := tagID(w, r, log)
+		if !ok {
+			return
+		}
+
+		err := tagDeleter.DeleteTag(r.Context(), id, userID)
+		switch {
+		case errors.Is(err, storage.ErrTagNotFound):
+			log.Info("tag not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("tag not found"))
+			return
+		case err != nil:
+			log.Error("failed to delete tag", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to delete tag"))
+			return
+		}
+
+		log.Info("tag deleted", slog.Int64("id", id))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NewBulk adds and removes tags of the user's links. Missing tags are
+// created. Either all links are changed or, if one of them is not found,
+// none of them.
+func NewBulk(log *slog.Logger, linkTagger LinkTagger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.tags.NewBulk"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req BulkRequest
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("failed to decode request"))
+			return
+		}
+
+		req.Add = NormalizeNames(req.Add)
+		req.Remove = NormalizeNames(req.Remove)
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Info("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(resp.ValidationError(validateErr)))
+			return
+		}
+
+		if len(req.Add) == 0 && len(req.Remove) == 0 {
+			log.Info("nothing to change")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field Add or Remove is required"))
+			return
+		}
+
+		err := linkTagger.TagLinks(r.Context(), userID, req.IDs, req.Add, req.Remove)
+		switch {
+		case errors.Is(err, storage.ErrAliasNotFound):
+			log.Info("some links not found")
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("some links not found"))
+			return
+		case err != nil:
+			log.Error("failed to tag links", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to tag links"))
+			return
+		}
+
+		log.Info("links tagged", slog.Int("links", len(req.IDs)))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NormalizeNames trims and lowercases tag names and drops duplicates and empty names.
+func NormalizeNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	var normalized []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, log *slog.Logger) (Request, bool) {
+	var req Request
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		log.Error("failed to decode request body", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("failed to decode request"))
+		return Request{}, false
+	}
+
+	req.Name = strings.ToLower(strings.TrimSpace(req.Name))
+
+	if err := validator.New().Struct(req); err != nil {
+		validateErr := err.(validator.ValidationErrors)
+
+		log.Info("invalid request", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(resp.ValidationError(validateErr)))
+		return Request{}, false
+	}
+
+	return req, true
+}
+
+func tagID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		log.Info("invalid tag id", slog.String("id", chi.URLParam(r, "id")))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("invalid tag id"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func userIDFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	// Берем из контекста данные JWT токена
+	claims, err := jwtlib.GetClaimsFromContext(r.Context())
+	if err != nil {
+		log.Error("failed to get claims from context")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to get claims"))
+		return 0, false
+	}
+
+	userIDAny, ok := claims["uid"]
+	if !ok {
+		log.Error("failed to get field uid from claims")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("internal error"))
+		return 0, false
+	}
+
+	return int64(userIDAny.(float64)), true
+}
@@ -0,0 +1,169 @@
+package tags_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/tags"
+	"URLshortener/internal/http-server/handlers/tags/mocks"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func withClaims(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+}
+
+func TestCreateHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		tag        string
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Success",
+			input:      `{"name": " Work "}`,
+			tag:        "work",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Already exists",
+			input:      `{"name": "work"}`,
+			tag:        "work",
+			mockError:  storage.ErrTagExist,
+			respStatus: http.StatusConflict,
+			respError:  "tag already exists",
+		},
+		{
+			name:       "Empty name",
+			input:      `{"name": "  "}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "field Name is a required field",
+		},
+		{
+			name:       "Storage error",
+			input:      `{"name": "work"}`,
+			tag:        "work",
+			mockError:  errors.New("unexpected error"),
+			respStatus: http.StatusInternalServerError,
+			respError:  "failed to create tag",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			creatorMock := mocks.NewTagCreator(t)
+			if tc.tag != "" {
+				creatorMock.On("CreateTag", mock.Anything, int64(1), tc.tag).
+					Return(models.Tag{ID: 1, Name: tc.tag}, tc.mockError).
+					Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/tags", strings.NewReader(tc.input))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			tags.NewCreate(slogdiscard.NewDiscardLogger(), creatorMock).ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				models.Tag
+				Error string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.tag, resp.Name)
+			}
+		})
+	}
+}
+
+func TestBulkHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		ids        []int64
+		add        []string
+		remove     []string
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Success",
+			input:      `{"ids": [1, 2], "add": ["Work", "work", " news "], "remove": ["old"]}`,
+			ids:        []int64{1, 2},
+			add:        []string{"work", "news"},
+			remove:     []string{"old"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Links not found",
+			input:      `{"ids": [1, 3], "add": ["work"]}`,
+			ids:        []int64{1, 3},
+			add:        []string{"work"},
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "some links not found",
+		},
+		{
+			name:       "No links",
+			input:      `{"ids": [], "add": ["work"]}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "field IDs is not valid",
+		},
+		{
+			name:       "Nothing to change",
+			input:      `{"ids": [1], "add": [" "]}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "field Add or Remove is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			taggerMock := mocks.NewLinkTagger(t)
+			if tc.ids != nil {
+				taggerMock.On("TagLinks", mock.Anything, int64(1), tc.ids, tc.add, tc.remove).
+					Return(tc.mockError).
+					Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/links/tags", strings.NewReader(tc.input))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			tags.NewBulk(slogdiscard.NewDiscardLogger(), taggerMock).ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				Error string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
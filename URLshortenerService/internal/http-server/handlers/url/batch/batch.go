@@ -0,0 +1,519 @@
+package batch
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/save"
+	jwtlib "URLshortener/internal/jwt"
+	aliaslib "URLshortener/internal/lib/alias"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/lib/screening"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ModeAtomic  = "atomic"
+	ModePartial = "partial"
+
+	StatusCreated = "created"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// maxBodySize limits the request body regardless of the number of items.
+const maxBodySize = 10 << 20
+
+type Request struct {
+	Mode  string         `json:"mode,omitempty"`
+	Items []save.Request `json:"items"`
+}
+
+type ItemResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	ID     int64  `json:"id,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Alias  string `json:"alias,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Response struct {
+	Mode    string       `json:"mode,omitempty"`
+	Created int          `json:"created"`
+	Failed  int          `json:"failed"`
+	Items   []ItemResult `json:"items,omitempty"`
+	Error   string       `json:"message,omitempty"`
+}
+
+//go:generate mockery --name=BatchSaver --output=./mocks
+type BatchSaver interface {
+	SaveURL(ctx context.Context, note models.AliasNote, userID int64, limits quota.Limits) (int64, error)
+	SaveURLs(ctx context.Context, notes []models.AliasNote, userID int64, limits quota.Limits) ([]int64, int, error)
+}
+
+type AliasAllocator interface {
+	Generate(ctx context.Context) (string, error)
+	Allocate(ctx context.Context, try func(alias string) error) (string, error)
+	Attempts() int
+}
+
+// New creates up to maxItems links in one request. Items are sent as JSON
+// {"mode": "...", "items": [...]} or as CSV with a header row (url, alias,
+// domain, redirectCode, expiresAt, maxClicks) and ?mode= in the query.
+// In atomic mode (the default) either all links are created or none,
+// in partial mode every valid item is stored on its own. If urlScreener is
+// not nil, items with destinations it rejects fail. If quotaChecker is not
+// nil, nothing is stored unless all valid items fit in the user's quota.
+func New(log *slog.Logger, batchSaver BatchSaver, aliasAllocator AliasAllocator, urlScreener save.URLScreener, quotaChecker save.QuotaChecker, maxItems int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.batch.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		actor, err := jwtlib.GetActorFromContext(r.Context())
+		if err != nil {
+			log.Error("failed to get actor from claims", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, Response{Error: "failed to get claims"})
+			return
+		}
+
+		req, err := decodeRequest(w, r)
+		if err != nil {
+			log.Info("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: err.Error()})
+			return
+		}
+
+		if req.Mode == "" {
+			req.Mode = ModeAtomic
+		}
+		if req.Mode != ModeAtomic && req.Mode != ModePartial {
+			log.Info("invalid mode", slog.String("mode", req.Mode))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: "field mode must be atomic or partial"})
+			return
+		}
+
+		if len(req.Items) == 0 || len(req.Items) > maxItems {
+			log.Info("invalid number of items", slog.Int("items", len(req.Items)))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: fmt.Sprintf("batch must contain from 1 to %d items", maxItems)})
+			return
+		}
+
+		log.Info("batch decoded", slog.String("mode", req.Mode), slog.Int("items", len(req.Items)))
+
+		b := batch{
+			log:       log,
+			saver:     batchSaver,
+			allocator: aliasAllocator,
+			screener:  urlScreener,
+			userID:    actor.UserID,
+			aliases:   make(map[string]int, len(req.Items)),
+		}
+		b.prepare(r.Context(), req.Items)
+
+		resp := Response{Mode: req.Mode, Items: b.results}
+
+		if quotaChecker != nil {
+			if err := quotaChecker.Check(r.Context(), actor, b.valid()); err != nil {
+				skipPending(&resp)
+				if errors.Is(err, quota.ErrExceeded) {
+					log.Info("link quota exceeded", sl.Err(err))
+					resp.Error = err.Error()
+					render.Status(r, http.StatusForbidden)
+				} else {
+					log.Error("failed to check quota", sl.Err(err))
+					resp.Error = "failed to check quota"
+					render.Status(r, http.StatusInternalServerError)
+				}
+				render.JSON(w, r, resp)
+				return
+			}
+			b.limits = quotaChecker.Limits(actor)
+		}
+
+		if req.Mode == ModeAtomic {
+			render.Status(r, b.saveAtomic(r.Context(), &resp))
+		} else {
+			b.savePartial(r.Context())
+		}
+
+		for _, item := range resp.Items {
+			switch item.Status {
+			case StatusCreated:
+				resp.Created++
+			case StatusFailed:
+				resp.Failed++
+			}
+		}
+
+		log.Info("batch processed", slog.Int("created", resp.Created), slog.Int("failed", resp.Failed))
+
+		render.JSON(w, r, resp)
+	}
+}
+
+type batch struct {
+	log       *slog.Logger
+	saver     BatchSaver
+	allocator AliasAllocator
+	screener  save.URLScreener
+	userID    int64
+	limits    quota.Limits
+
+	results []ItemResult
+	// notes are nil for invalid items
+	notes []*models.AliasNote
+	// generated marks items without an alias in the request
+	generated []bool
+	// aliases maps domains and aliases of the batch to the index of the item using them
+	aliases map[string]int
+}
+
+// prepare validates the items and builds the links to store. Invalid items
+// get the failed status and have no note.
+func (b *batch) prepare(ctx context.Context, items []save.Request) {
+	b.results = make([]ItemResult, len(items))
+	b.notes = make([]*models.AliasNote, len(items))
+	b.generated = make([]bool, len(items))
+
+	for i, item := range items {
+		b.results[i] = ItemResult{Index: i, URL: item.URL, Alias: item.Alias}
+
+		if err := save.Validate(item); err != nil {
+			b.results[i].Status = StatusFailed
+			b.results[i].Error = err.Error()
+			continue
+		}
+
+		if b.screener != nil {
+			if err := b.screener.Screen(ctx, item.URL); err != nil {
+				b.results[i].Status = StatusFailed
+				b.results[i].Error = "internal error"
+				if errors.Is(err, screening.ErrRejected) {
+					b.results[i].Error = err.Error()
+				} else {
+					b.log.Error("failed to screen destination", sl.Err(err))
+				}
+				continue
+			}
+		}
+
+		note, err := save.NewNote(item)
+		if err != nil {
+			b.log.Error("failed to prepare link", sl.Err(err))
+			b.results[i].Status = StatusFailed
+			b.results[i].Error = "internal error"
+			continue
+		}
+
+		if note.Alias == "" {
+			b.generated[i] = true
+			if note.Alias, err = b.generateAlias(ctx, note.Domain); err != nil {
+				b.log.Error("failed to generate alias", sl.Err(err))
+				b.results[i].Status = StatusFailed
+				b.results[i].Error = "failed to generate a free alias"
+				continue
+			}
+		}
+
+		// Повтор алиаса внутри пакета всё равно упадёт на уникальном индексе
+		if first, ok := b.aliases[aliasKey(note.Domain, note.Alias)]; ok {
+			b.results[i].Status = StatusFailed
+			b.results[i].Error = fmt.Sprintf("alias is already used by item %d", first)
+			continue
+		}
+		b.aliases[aliasKey(note.Domain, note.Alias)] = i
+
+		b.results[i].Alias = note.Alias
+		b.notes[i] = &note
+	}
+}
+
+// valid returns the number of items that passed prepare.
+func (b *batch) valid() int {
+	n := 0
+	for _, note := range b.notes {
+		if note != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// generateAlias returns a generated alias not used by other items of the batch
+// on the same domain.
+func (b *batch) generateAlias(ctx context.Context, domain string) (string, error) {
+	for i := 0; i < b.allocator.Attempts(); i++ {
+		alias, err := b.allocator.Generate(ctx)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := b.aliases[aliasKey(domain, alias)]; !ok {
+			return alias, nil
+		}
+	}
+
+	return "", aliaslib.ErrNoFreeAlias
+}
+
+func (b *batch) saveAtomic(ctx context.Context, resp *Response) int {
+	valid := make([]models.AliasNote, 0, len(b.notes))
+	indexes := make([]int, 0, len(b.notes))
+	for i, note := range b.notes {
+		if note == nil {
+			continue
+		}
+		valid = append(valid, *note)
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) != len(b.notes) {
+		skipPending(resp)
+		return http.StatusBadRequest
+	}
+
+	var ids []int64
+	var failed int
+	var err error
+	for attempt := 1; ; attempt++ {
+		ids, failed, err = b.saver.SaveURLs(ctx, valid, b.userID, b.limits)
+		if err == nil || failed < 0 || !errors.Is(err, storage.ErrAliasExist) ||
+			!b.generated[indexes[failed]] || attempt >= b.allocator.Attempts() {
+			break
+		}
+
+		// Занят сгенерированный алиас: меняем только его и повторяем транзакцию
+		alias, genErr := b.generateAlias(ctx, valid[failed].Domain)
+		if genErr != nil {
+			break
+		}
+
+		b.log.Info("generated alias is taken, retrying batch", slog.String("alias", valid[failed].Alias))
+
+		delete(b.aliases, aliasKey(valid[failed].Domain, valid[failed].Alias))
+		b.aliases[aliasKey(valid[failed].Domain, alias)] = indexes[failed]
+		valid[failed].Alias = alias
+		resp.Items[indexes[failed]].Alias = alias
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if failed >= 0 {
+			item := &resp.Items[indexes[failed]]
+			item.Status = StatusFailed
+			item.Error = itemError(err)
+			switch {
+			case errors.Is(err, storage.ErrAliasExist):
+				status = http.StatusConflict
+			case errors.Is(err, storage.ErrDomainNotFound), errors.Is(err, storage.ErrDomainNotVerified):
+				status = http.StatusBadRequest
+			}
+		}
+
+		b.log.Info("batch rolled back", slog.Int("item", failed), sl.Err(err))
+
+		skipPending(resp)
+		switch {
+		case errors.Is(err, quota.ErrExceeded):
+			status = http.StatusForbidden
+			resp.Error = err.Error()
+		case status == http.StatusInternalServerError:
+			resp.Error = "failed to add aliases"
+		}
+		return status
+	}
+
+	for i, id := range ids {
+		item := &resp.Items[indexes[i]]
+		item.Status = StatusCreated
+		item.ID = id
+	}
+
+	return http.StatusOK
+}
+
+func (b *batch) savePartial(ctx context.Context) {
+	for i, note := range b.notes {
+		if note == nil {
+			continue
+		}
+
+		item := &b.results[i]
+
+		var id int64
+		var err error
+		if b.generated[i] {
+			// Первый кандидат уже сгенерирован в prepare, при конфликте генерируются новые
+			candidate := note.Alias
+			item.Alias, err = b.allocator.Allocate(ctx, func(alias string) error {
+				if candidate != "" {
+					alias, candidate = candidate, ""
+				}
+
+				generated := *note
+				generated.Alias = alias
+
+				var err error
+				id, err = b.saver.SaveURL(ctx, generated, b.userID, b.limits)
+				return err
+			})
+		} else {
+			id, err = b.saver.SaveURL(ctx, *note, b.userID, b.limits)
+		}
+		if err != nil {
+			if itemError(err) == "internal error" {
+				b.log.Error("failed to add alias", slog.Int("item", i), sl.Err(err))
+			}
+
+			item.Status = StatusFailed
+			item.Alias = note.Alias
+			item.Error = itemError(err)
+			continue
+		}
+
+		item.Status = StatusCreated
+		item.ID = id
+	}
+}
+
+// skipPending marks the items that were not stored because of another item.
+func skipPending(resp *Response) {
+	for i := range resp.Items {
+		if resp.Items[i].Status == "" {
+			resp.Items[i].Status = StatusSkipped
+		}
+	}
+}
+
+func aliasKey(domain, alias string) string {
+	return domain + "/" + alias
+}
+
+func itemError(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrDomainNotFound), errors.Is(err, storage.ErrDomainNotVerified):
+		return save.DomainError(err)
+	case errors.Is(err, storage.ErrAliasExist):
+		return storage.ErrAliasExist.Error()
+	case errors.Is(err, aliaslib.ErrNoFreeAlias):
+		return aliaslib.ErrNoFreeAlias.Error()
+	case errors.Is(err, quota.ErrExceeded):
+		return err.Error()
+	default:
+		return "internal error"
+	}
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request) (Request, error) {
+	body := http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		items, err := decodeCSV(body)
+		if err != nil {
+			return Request{}, err
+		}
+		return Request{Mode: r.URL.Query().Get("mode"), Items: items}, nil
+	}
+
+	var req Request
+	if err := render.DecodeJSON(body, &req); err != nil {
+		return Request{}, errors.New("failed to decode request")
+	}
+	if req.Mode == "" {
+		req.Mode = r.URL.Query().Get("mode")
+	}
+
+	return req, nil
+}
+
+// decodeCSV reads items from CSV. The header names the columns, only url is required.
+func decodeCSV(body io.Reader) ([]save.Request, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("failed to read csv header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch name {
+		case "url", "alias", "domain", "redirectcode", "expiresat", "maxclicks":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown csv column %q", name)
+		}
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("csv header must contain url")
+	}
+
+	var items []save.Request
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv line %d", line)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		item := save.Request{URL: field("url"), Alias: field("alias"), Domain: field("domain")}
+
+		if v := field("redirectcode"); v != "" {
+			if item.RedirectCode, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("csv line %d: redirectCode must be a number", line)
+			}
+		}
+		if v := field("expiresat"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("csv line %d: expiresAt must be RFC3339 time", line)
+			}
+			item.ExpiresAt = &t
+		}
+		if v := field("maxclicks"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("csv line %d: maxClicks must be a number", line)
+			}
+			item.MaxClicks = &n
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,255 @@
+package batch_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/batch"
+	"URLshortener/internal/http-server/handlers/url/batch/mocks"
+	"URLshortener/internal/http-server/handlers/url/save"
+	"URLshortener/internal/lib/alias"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/lib/random"
+	"URLshortener/internal/lib/screening"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type linkCounter struct {
+	total, recent int
+}
+
+func (c linkCounter) CountLinks(context.Context, int64, time.Time) (int, int, error) {
+	return c.total, c.recent, nil
+}
+
+func TestBatchHandler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		setup       func(m *mocks.BatchSaver)
+		quota       *quota.Limits
+		respStatus  int
+		respError   string
+		statuses    []string
+	}{
+		{
+			name: "Atomic success",
+			body: `{"items": [{"url": "https://google.com", "alias": "google"}, {"url": "https://ya.ru"}]}`,
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURLs", mock.Anything, mock.MatchedBy(func(notes []models.AliasNote) bool {
+					return len(notes) == 2 && notes[0].Alias == "google" && notes[1].Alias != ""
+				}), int64(1), quota.Limits{}).Return([]int64{1, 2}, -1, nil).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{batch.StatusCreated, batch.StatusCreated},
+		},
+		{
+			name:       "Atomic invalid item",
+			body:       `{"items": [{"url": "https://google.com"}, {"url": "not a url"}]}`,
+			respStatus: http.StatusBadRequest,
+			statuses:   []string{batch.StatusSkipped, batch.StatusFailed},
+		},
+		{
+			name:       "Atomic reserved alias",
+			body:       `{"items": [{"url": "https://google.com"}, {"url": "https://ya.ru", "alias": "urls"}]}`,
+			respStatus: http.StatusBadRequest,
+			statuses:   []string{batch.StatusSkipped, batch.StatusFailed},
+		},
+		{
+			name:       "Atomic duplicate alias in batch",
+			body:       `{"items": [{"url": "https://google.com", "alias": "a"}, {"url": "https://ya.ru", "alias": "a"}]}`,
+			respStatus: http.StatusBadRequest,
+			statuses:   []string{batch.StatusSkipped, batch.StatusFailed},
+		},
+		{
+			name: "Atomic alias exists",
+			body: `{"items": [{"url": "https://google.com", "alias": "a"}, {"url": "https://ya.ru", "alias": "b"}]}`,
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURLs", mock.Anything, mock.Anything, int64(1), quota.Limits{}).Return(nil, 1, storage.ErrAliasExist).Once()
+			},
+			respStatus: http.StatusConflict,
+			statuses:   []string{batch.StatusSkipped, batch.StatusFailed},
+		},
+		{
+			name: "Atomic storage error",
+			body: `{"items": [{"url": "https://google.com"}]}`,
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURLs", mock.Anything, mock.Anything, int64(1), quota.Limits{}).Return(nil, -1, errors.New("unexpected error")).Once()
+			},
+			respStatus: http.StatusInternalServerError,
+			respError:  "failed to add aliases",
+			statuses:   []string{batch.StatusSkipped},
+		},
+		{
+			name: "Partial",
+			body: `{"mode": "partial", "items": [{"url": "https://google.com", "alias": "a"}, {"url": "bad"}, {"url": "https://ya.ru", "alias": "b"}]}`,
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool { return n.Alias == "a" }), int64(1), quota.Limits{}).Return(int64(1), nil).Once()
+				m.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool { return n.Alias == "b" }), int64(1), quota.Limits{}).Return(int64(0), storage.ErrAliasExist).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{batch.StatusCreated, batch.StatusFailed, batch.StatusFailed},
+		},
+		{
+			name:        "CSV partial",
+			query:       "?mode=partial",
+			contentType: "text/csv",
+			body:        "url,alias,redirectCode\nhttps://google.com,a,301\nhttps://ya.ru,,\n",
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+					return n.Alias == "a" && n.RedirectCode == http.StatusMovedPermanently
+				}), int64(1), quota.Limits{}).Return(int64(1), nil).Once()
+				m.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+					return n.Url == "https://ya.ru" && n.Alias != ""
+				}), int64(1), quota.Limits{}).Return(int64(2), nil).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{batch.StatusCreated, batch.StatusCreated},
+		},
+		{
+			name:        "CSV unknown column",
+			contentType: "text/csv",
+			body:        "url,title\nhttps://google.com,Google\n",
+			respStatus:  http.StatusBadRequest,
+			respError:   `unknown csv column "title"`,
+		},
+		{
+			name:       "Invalid mode",
+			body:       `{"mode": "sometimes", "items": [{"url": "https://google.com"}]}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "field mode must be atomic or partial",
+		},
+		{
+			name:       "Too many items",
+			body:       `{"items": [{"url": "https://a.ru"}, {"url": "https://b.ru"}, {"url": "https://c.ru"}, {"url": "https://d.ru"}]}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "batch must contain from 1 to 3 items",
+		},
+		{
+			name: "Atomic generated alias taken",
+			body: `{"items": [{"url": "https://google.com", "alias": "a"}, {"url": "https://ya.ru"}]}`,
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURLs", mock.Anything, mock.Anything, int64(1), quota.Limits{}).Return(nil, 1, storage.ErrAliasExist).Once()
+				m.On("SaveURLs", mock.Anything, mock.Anything, int64(1), quota.Limits{}).Return([]int64{1, 2}, -1, nil).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{batch.StatusCreated, batch.StatusCreated},
+		},
+		{
+			name:       "Atomic rejected destination",
+			body:       `{"items": [{"url": "https://google.com"}, {"url": "javascript:alert(1)"}]}`,
+			respStatus: http.StatusBadRequest,
+			statuses:   []string{batch.StatusSkipped, batch.StatusFailed},
+		},
+		{
+			name:  "Partial rejected destination",
+			query: "?mode=partial",
+			body:  `{"items": [{"url": "https://google.com", "alias": "g"}, {"url": "javascript:alert(1)"}]}`,
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURL", mock.Anything, mock.MatchedBy(func(note models.AliasNote) bool {
+					return note.Alias == "g"
+				}), int64(1), quota.Limits{}).Return(int64(1), nil).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{batch.StatusCreated, batch.StatusFailed},
+		},
+		{
+			name:       "Quota exceeded",
+			body:       `{"items": [{"url": "https://google.com"}, {"url": "https://ya.ru"}, {"url": "not a url"}]}`,
+			quota:      &quota.Limits{MaxDaily: 3},
+			respStatus: http.StatusForbidden,
+			respError:  "link quota exceeded: at most 3 links per day",
+			statuses:   []string{batch.StatusSkipped, batch.StatusSkipped, batch.StatusFailed},
+		},
+		{
+			name:  "Within quota",
+			body:  `{"items": [{"url": "https://google.com", "alias": "g"}, {"url": "not a url"}]}`,
+			query: "?mode=partial",
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURL", mock.Anything, mock.AnythingOfType("models.AliasNote"), int64(1), quota.Limits{MaxDaily: 3}).Return(int64(1), nil).Once()
+			},
+			quota:      &quota.Limits{MaxDaily: 3},
+			respStatus: http.StatusOK,
+			statuses:   []string{batch.StatusCreated, batch.StatusFailed},
+		},
+		{
+			name: "Quota used up concurrently",
+			body: `{"items": [{"url": "https://google.com", "alias": "g"}]}`,
+			setup: func(m *mocks.BatchSaver) {
+				m.On("SaveURLs", mock.Anything, mock.Anything, int64(1), quota.Limits{MaxDaily: 3}).
+					Return(nil, -1, fmt.Errorf("%w: at most 3 links per day", quota.ErrExceeded)).Once()
+			},
+			quota:      &quota.Limits{MaxDaily: 3},
+			respStatus: http.StatusForbidden,
+			respError:  "link quota exceeded: at most 3 links per day",
+			statuses:   []string{batch.StatusSkipped},
+		},
+		{
+			name:       "Empty batch",
+			body:       `{"items": []}`,
+			respStatus: http.StatusBadRequest,
+			respError:  "batch must contain from 1 to 3 items",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			saverMock := mocks.NewBatchSaver(t)
+			if tc.setup != nil {
+				tc.setup(saverMock)
+			}
+
+			generator, err := alias.NewRandom(6, random.Base62)
+			require.NoError(t, err)
+
+			screener := screening.New(screening.Schemes("http", "https"))
+
+			var quotaChecker save.QuotaChecker
+			if tc.quota != nil {
+				quotaChecker = quota.New(linkCounter{total: 2, recent: 2}, nil, *tc.quota)
+			}
+
+			handler := batch.New(slogdiscard.NewDiscardLogger(), saverMock, alias.NewAllocator(generator, 3), screener, quotaChecker, 3)
+
+			req := httptest.NewRequest(http.MethodPost, "/batch"+tc.query, strings.NewReader(tc.body))
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			} else {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp batch.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			var statuses []string
+			for _, item := range resp.Items {
+				statuses = append(statuses, item.Status)
+			}
+			require.Equal(t, tc.statuses, statuses)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	resp "URLshortener/internal/lib/api/response"
 	"URLshortener/internal/lib/logger/sl"
 	"URLshortener/internal/storage"
+	"context"
 	"errors"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -22,7 +23,7 @@ type response struct {
 }
 
 type Deleter interface {
-	DeleteAlias(id int64, userID int64) error
+	DeleteAlias(ctx context.Context, id int64, userID int64) error
 	//DeleteURL(url string) error
 }
 
@@ -78,7 +79,7 @@ func New(log *slog.Logger, deleter Deleter) http.HandlerFunc {
 			return
 		}
 
-		err = deleter.DeleteAlias(req.ID, userID)
+		err = deleter.DeleteAlias(r.Context(), req.ID, userID)
 		switch {
 		case errors.Is(err, storage.ErrAliasNotFound):
 			log.Error("alias not found", slog.Int64("aliasID", req.ID))
@@ -3,6 +3,7 @@ package deleteUserData
 import (
 	jwtlib "URLshortener/internal/jwt"
 	resp "URLshortener/internal/lib/api/response"
+	"context"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"log/slog"
@@ -10,7 +11,7 @@ import (
 )
 
 type Deleter interface {
-	DeleteUserData(userID int64) error
+	DeleteUserData(ctx context.Context, userID int64) error
 }
 
 func New(log *slog.Logger, deleter Deleter) http.HandlerFunc {
@@ -55,7 +56,7 @@ func New(log *slog.Logger, deleter Deleter) http.HandlerFunc {
 			return
 		}
 
-		if err := deleter.DeleteUserData(userID); err != nil {
+		if err := deleter.DeleteUserData(r.Context(), userID); err != nil {
 			log.Error("failed to delete user's data")
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, resp.Error("internal error"))
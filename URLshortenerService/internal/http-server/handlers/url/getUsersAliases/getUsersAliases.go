@@ -2,29 +2,55 @@ package getUsersAliases
 
 import (
 	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/tags"
 	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
 	"URLshortener/internal/storage"
+	"context"
 	"errors"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+const defaultLimit = 50
+
 type errorResponse struct {
 	Error string `json:"message,omitempty"`
 }
 
-//go:generate mockery --name=URLSaver --output=./mocks
-type UsersDataProvider interface {
-	GetUserUrls(userID int64) ([]models.AliasNote, error)
+type request struct {
+	Limit       int        `validate:"min=1,max=500"`
+	Sort        string     `validate:"oneof=created alias clicks"`
+	Order       string     `validate:"oneof=asc desc"`
+	Search      string     `validate:"max=200"`
+	Status      string     `validate:"omitempty,oneof=active expired"`
+	CreatedFrom *time.Time `validate:"omitempty"`
+	CreatedTo   *time.Time `validate:"omitempty"`
+	Tags        []string   `validate:"max=10,dive,max=50"`
+	FolderID    *int64     `validate:"omitempty,min=1"`
+}
+
+//go:generate mockery --name=LinkLister --output=./mocks
+type LinkLister interface {
+	ListUserLinks(ctx context.Context, userID int64, filter models.LinkFilter) (models.LinkPage, error)
 }
 
-func New(log *slog.Logger, usersDataProvider UsersDataProvider) http.HandlerFunc {
+// New returns a page of the user's links.
+// Query: ?limit=&cursor=&sort=created|alias|clicks&order=asc|desc&search=&from=&to=&status=active|expired&tag=&folder=.
+// tag can be repeated to list links that have all of the tags.
+// The next page is requested with the nextCursor of the previous response and the same filters.
+// Every link comes with its full short URL on publicURL or on its custom domain.
+func New(log *slog.Logger, linkLister LinkLister, publicURL string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.getUsersAliases.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
@@ -47,21 +73,121 @@ func New(log *slog.Logger, usersDataProvider UsersDataProvider) http.HandlerFunc
 		}
 		userID := int64(userIDAny.(float64))
 
-		// Запись в storage
-		usersAliases, err := usersDataProvider.GetUserUrls(userID)
+		filter, ok := parseRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		page, err := linkLister.ListUserLinks(r.Context(), userID, filter)
+		switch {
+		case errors.Is(err, storage.ErrInvalidCursor):
+			log.Info("invalid cursor", sl.Err(err))
 
-		if err != nil && !errors.Is(err, storage.ErrAliasNotFound) {
-			log.Error("failed to get aliases", slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, errorResponse{Error: "invalid cursor"})
+			return
+		case err != nil:
+			log.Error("failed to get aliases", sl.Err(err))
 
 			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, errorResponse{Error: "failed to add alias"})
+			render.JSON(w, r, errorResponse{Error: "failed to get aliases"})
 			return
 		}
 
-		if usersAliases == nil {
-			usersAliases = []models.AliasNote{}
+		for i := range page.Items {
+			page.Items[i].ShortURL = models.ShortURL(publicURL, page.Items[i].Domain, page.Items[i].Alias)
 		}
 
-		render.JSON(w, r, usersAliases)
+		render.JSON(w, r, page)
+	}
+}
+
+func parseRequest(w http.ResponseWriter, r *http.Request, log *slog.Logger) (models.LinkFilter, bool) {
+	q := r.URL.Query()
+
+	req := request{
+		Limit:  defaultLimit,
+		Sort:   q.Get("sort"),
+		Order:  q.Get("order"),
+		Search: q.Get("search"),
+		Status: q.Get("status"),
+		Tags:   tags.NormalizeNames(q["tag"]),
 	}
+	if req.Sort == "" {
+		req.Sort = models.SortCreated
+	}
+	// Новые и популярные ссылки сверху, алиасы по алфавиту
+	if req.Order == "" {
+		req.Order = "desc"
+		if req.Sort == models.SortAlias {
+			req.Order = "asc"
+		}
+	}
+
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			log.Info("invalid limit parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, errorResponse{Error: "field limit must be a number"})
+			return models.LinkFilter{}, false
+		}
+		req.Limit = n
+	}
+
+	if folder := q.Get("folder"); folder != "" {
+		id, err := strconv.ParseInt(folder, 10, 64)
+		if err != nil {
+			log.Info("invalid folder parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, errorResponse{Error: "field folder must be a number"})
+			return models.LinkFilter{}, false
+		}
+		req.FolderID = &id
+	}
+
+	for field, dst := range map[string]**time.Time{"from": &req.CreatedFrom, "to": &req.CreatedTo} {
+		v := q.Get(field)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			log.Info("invalid "+field+" parameter", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, errorResponse{Error: "field " + field + " must be RFC3339 time"})
+			return models.LinkFilter{}, false
+		}
+		*dst = &t
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		validateErr := err.(validator.ValidationErrors)
+
+		log.Info("invalid request", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, errorResponse{Error: resp.ValidationError(validateErr)})
+		return models.LinkFilter{}, false
+	}
+
+	if req.CreatedFrom != nil && req.CreatedTo != nil && !req.CreatedTo.After(*req.CreatedFrom) {
+		log.Info("invalid created range")
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, errorResponse{Error: "field to must be after from"})
+		return models.LinkFilter{}, false
+	}
+
+	return models.LinkFilter{
+		Search:      req.Search,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Status:      req.Status,
+		Tags:        req.Tags,
+		FolderID:    req.FolderID,
+		Sort:        req.Sort,
+		Desc:        req.Order == "desc",
+		Limit:       req.Limit,
+		Cursor:      q.Get("cursor"),
+	}, true
 }
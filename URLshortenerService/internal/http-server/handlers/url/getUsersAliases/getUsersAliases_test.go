@@ -0,0 +1,162 @@
+package getUsersAliases_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/getUsersAliases"
+	"URLshortener/internal/http-server/handlers/url/getUsersAliases/mocks"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetUsersAliasesHandler(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	folderID := int64(3)
+
+	testCases := []struct {
+		name       string
+		query      string
+		filter     *models.LinkFilter
+		page       models.LinkPage
+		shortURLs  []string
+		mockError  error
+		respError  string
+		respStatus int
+	}{
+		{
+			name:       "Defaults",
+			query:      "",
+			filter:     &models.LinkFilter{Sort: models.SortCreated, Desc: true, Limit: 50},
+			page:       models.LinkPage{Items: []models.LinkItem{{AliasNote: models.AliasNote{ID: 1, Alias: "google"}}}, NextCursor: "abc", Total: 2},
+			shortURLs:  []string{"https://svsevs.ru/google"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:   "Custom domain",
+			query:  "",
+			filter: &models.LinkFilter{Sort: models.SortCreated, Desc: true, Limit: 50},
+			page: models.LinkPage{Items: []models.LinkItem{
+				{AliasNote: models.AliasNote{ID: 1, Alias: "promo", Domain: "go.example.com"}},
+			}, Total: 1},
+			shortURLs:  []string{"https://go.example.com/promo"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:  "All filters",
+			query: "?limit=10&cursor=abc&sort=alias&search=goo&from=2025-01-01T00:00:00Z&status=active",
+			filter: &models.LinkFilter{
+				Search:      "goo",
+				CreatedFrom: &from,
+				Status:      models.StatusActive,
+				Sort:        models.SortAlias,
+				Limit:       10,
+				Cursor:      "abc",
+			},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:  "Tags and folder",
+			query: "?tag=Work&tag=news&tag=work&folder=3",
+			filter: &models.LinkFilter{
+				Tags:     []string{"work", "news"},
+				FolderID: &folderID,
+				Sort:     models.SortCreated,
+				Desc:     true,
+				Limit:    50,
+			},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Invalid folder",
+			query:      "?folder=inbox",
+			respError:  "field folder must be a number",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid limit",
+			query:      "?limit=1000",
+			respError:  "field Limit is not valid",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid sort",
+			query:      "?sort=url",
+			respError:  "field Sort is not valid",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid range",
+			query:      "?from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z",
+			respError:  "field to must be after from",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid cursor",
+			query:      "?cursor=zzz",
+			filter:     &models.LinkFilter{Sort: models.SortCreated, Desc: true, Limit: 50, Cursor: "zzz"},
+			mockError:  storage.ErrInvalidCursor,
+			respError:  "invalid cursor",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Storage error",
+			query:      "?order=asc",
+			filter:     &models.LinkFilter{Sort: models.SortCreated, Limit: 50},
+			mockError:  errors.New("unexpected error"),
+			respError:  "failed to get aliases",
+			respStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			listerMock := mocks.NewLinkLister(t)
+			if tc.filter != nil {
+				listerMock.On("ListUserLinks", mock.Anything, int64(1), *tc.filter).Return(tc.page, tc.mockError).Once()
+			}
+
+			handler := getUsersAliases.New(slogdiscard.NewDiscardLogger(), listerMock, "https://svsevs.ru/")
+
+			req := httptest.NewRequest(http.MethodGet, "/urls"+tc.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp struct {
+					Error string `json:"message"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+				return
+			}
+
+			var page models.LinkPage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+			require.Equal(t, tc.page.Total, page.Total)
+			require.Equal(t, tc.page.NextCursor, page.NextCursor)
+
+			var shortURLs []string
+			for _, item := range page.Items {
+				shortURLs = append(shortURLs, item.ShortURL)
+			}
+			require.Equal(t, tc.shortURLs, shortURLs)
+		})
+	}
+}
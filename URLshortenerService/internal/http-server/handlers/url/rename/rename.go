@@ -0,0 +1,147 @@
+package rename
+
+import (
+	"URLshortener/internal/domain/models"
+	jwtlib "URLshortener/internal/jwt"
+	aliaslib "URLshortener/internal/lib/alias"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Ссылку можно указать либо по id, либо по алиасу
+// С graceDays старый алиас еще столько дней ведет на ссылку
+type request struct {
+	ID        int64  `json:"urlId" validate:"required_without=Alias"`
+	Alias     string `json:"alias" validate:"required_without=ID"`
+	NewAlias  string `json:"newAlias" validate:"required"`
+	GraceDays int    `json:"graceDays,omitempty" validate:"min=0"`
+}
+
+type Response struct {
+	ID            int64      `json:"id,omitempty"`
+	Alias         string     `json:"alias,omitempty"`
+	OldAlias      string     `json:"oldAlias,omitempty"`
+	ShortURL      string     `json:"shortUrl,omitempty"`
+	RedirectUntil *time.Time `json:"redirectUntil,omitempty"`
+	Error         string     `json:"message,omitempty"`
+}
+
+//go:generate mockery --name=AliasRenamer --output=./mocks
+type AliasRenamer interface {
+	RenameAlias(ctx context.Context, id int64, alias string, newAlias string, actor models.Actor, keepOldUntil *time.Time) (models.AliasNote, string, error)
+}
+
+// New changes the alias of the user's link. Clicks stay with the link.
+// The old alias can be kept leading to the link for up to maxGrace.
+func New(log *slog.Logger, renamer AliasRenamer, publicURL string, maxGrace time.Duration) http.HandlerFunc {
+	maxGraceDays := int(maxGrace / (24 * time.Hour))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.rename.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		if r.Header.Get("Content-Type") != "application/json" {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: "invalid Content-Type"})
+			return
+		}
+
+		// Берем из контекста данные JWT токена, автор изменения попадет в историю ссылки
+		actor, err := jwtlib.GetActorFromContext(r.Context())
+		if err != nil {
+			log.Error("failed to get actor from claims", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, Response{Error: "failed to get claims"})
+			return
+		}
+
+		var req request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: "failed to decode request"})
+			return
+		}
+
+		log.Info("request body decoded", slog.Int64("urlId", req.ID), slog.String("alias", req.Alias),
+			slog.String("newAlias", req.NewAlias))
+
+		if err := validator.New().Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+
+			log.Info("invalid request", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: resp.ValidationError(validateErr)})
+			return
+		}
+
+		// Алиас становится сегментом пути короткой ссылки
+		if err := aliaslib.Validate(req.NewAlias); err != nil {
+			log.Info("invalid new alias", slog.String("newAlias", req.NewAlias), sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: err.Error()})
+			return
+		}
+
+		if req.GraceDays > maxGraceDays {
+			log.Info("grace period too long", slog.Int("graceDays", req.GraceDays))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: fmt.Sprintf("field GraceDays must be at most %d", maxGraceDays)})
+			return
+		}
+
+		var keepOldUntil *time.Time
+		if req.GraceDays > 0 {
+			until := time.Now().UTC().Add(time.Duration(req.GraceDays) * 24 * time.Hour)
+			keepOldUntil = &until
+		}
+
+		note, oldAlias, err := renamer.RenameAlias(r.Context(), req.ID, req.Alias, req.NewAlias, actor, keepOldUntil)
+		switch {
+		case errors.Is(err, storage.ErrAliasNotFound):
+			log.Info("alias not found", slog.Int64("urlId", req.ID), slog.String("alias", req.Alias))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: "alias not found"})
+			return
+		case errors.Is(err, storage.ErrAliasNotOwned):
+			log.Info("alias belongs to another user", slog.String("alias", req.Alias))
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, Response{Error: "alias belongs to another user"})
+			return
+		case errors.Is(err, storage.ErrAliasExist):
+			log.Info("new alias already exists", slog.String("newAlias", req.NewAlias))
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, Response{Error: "Такой алиас уже существует"})
+			return
+		case err != nil:
+			log.Error("failed to rename alias", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, Response{Error: "failed to rename alias"})
+			return
+		}
+
+		log.Info("alias renamed", slog.Int64("id", note.ID), slog.String("alias", note.Alias))
+
+		render.JSON(w, r, Response{
+			ID:            note.ID,
+			Alias:         note.Alias,
+			OldAlias:      oldAlias,
+			ShortURL:      models.ShortURL(publicURL, note.Domain, note.Alias),
+			RedirectUntil: keepOldUntil,
+		})
+	}
+}
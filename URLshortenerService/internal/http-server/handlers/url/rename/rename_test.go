@@ -0,0 +1,170 @@
+package rename_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/rename"
+	"URLshortener/internal/http-server/handlers/url/rename/mocks"
+	"URLshortener/internal/lib/alias"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var actor = models.Actor{UserID: 1, Role: "user"}
+
+func TestRenameHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		id         int64
+		alias      string
+		newAlias   string
+		oldAlias   string
+		grace      bool
+		respError  string
+		mockError  error
+		respStatus int
+	}{
+		{
+			name:       "Success by id",
+			input:      `{"urlId": 1, "newAlias": "new"}`,
+			id:         1,
+			newAlias:   "new",
+			oldAlias:   "old",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Success by alias with grace period",
+			input:      `{"alias": "old", "newAlias": "new", "graceDays": 7}`,
+			alias:      "old",
+			newAlias:   "new",
+			oldAlias:   "old",
+			grace:      true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Empty new alias",
+			input:      `{"urlId": 1}`,
+			respError:  "field NewAlias is a required field",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Neither id nor alias",
+			input:      `{"newAlias": "new"}`,
+			respError:  "field ID is a required field, field Alias is a required field",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "New alias with slash",
+			input:      `{"urlId": 1, "newAlias": "a/b"}`,
+			respError:  alias.ErrInvalidAlias.Error(),
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Reserved new alias",
+			input:      `{"urlId": 1, "newAlias": "Stats"}`,
+			respError:  alias.ErrReservedAlias.Error(),
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Grace period too long",
+			input:      `{"urlId": 1, "newAlias": "new", "graceDays": 31}`,
+			respError:  "field GraceDays must be at most 30",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "storage error: alias exists",
+			input:      `{"urlId": 1, "newAlias": "taken"}`,
+			id:         1,
+			newAlias:   "taken",
+			respError:  "Такой алиас уже существует",
+			mockError:  storage.ErrAliasExist,
+			respStatus: http.StatusConflict,
+		},
+		{
+			name:       "storage error: alias not found",
+			input:      `{"alias": "missing", "newAlias": "new"}`,
+			alias:      "missing",
+			newAlias:   "new",
+			respError:  "alias not found",
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "storage error: alias of another user",
+			input:      `{"alias": "foreign", "newAlias": "new"}`,
+			alias:      "foreign",
+			newAlias:   "new",
+			respError:  "alias belongs to another user",
+			mockError:  storage.ErrAliasNotOwned,
+			respStatus: http.StatusForbidden,
+		},
+		{
+			name:       "storage error: other error",
+			input:      `{"urlId": 1, "newAlias": "new"}`,
+			id:         1,
+			newAlias:   "new",
+			respError:  "failed to rename alias",
+			mockError:  errors.New("unexpected error"),
+			respStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "invalid JSON input",
+			input:      `{"urlId": 1, "newAlias": "new"`,
+			respError:  "failed to decode request",
+			respStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			aliasRenamerMock := mocks.NewAliasRenamer(t)
+
+			if tc.respError == "" || tc.mockError != nil {
+				aliasRenamerMock.On("RenameAlias", mock.Anything, tc.id, tc.alias, tc.newAlias, actor,
+					mock.MatchedBy(func(until *time.Time) bool {
+						return (until != nil) == tc.grace
+					})).
+					Return(models.AliasNote{ID: 1, Alias: tc.newAlias}, tc.oldAlias, tc.mockError).
+					Once()
+			}
+
+			handler := rename.New(slogdiscard.NewDiscardLogger(), aliasRenamerMock, "https://sho.rt", 30*24*time.Hour)
+
+			req, err := http.NewRequest(http.MethodPatch, "/alias", bytes.NewReader([]byte(tc.input)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1), "role": "user"}))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp rename.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, tc.newAlias, resp.Alias)
+				require.Equal(t, "https://sho.rt/"+tc.newAlias, resp.ShortURL)
+				require.Equal(t, tc.oldAlias, resp.OldAlias)
+				require.Equal(t, tc.grace, resp.RedirectUntil != nil)
+			}
+		})
+	}
+}
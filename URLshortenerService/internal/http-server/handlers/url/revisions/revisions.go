@@ -0,0 +1,194 @@
+package revisions
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/save"
+	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/lib/screening"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ListResponse struct {
+	Revisions []models.Revision `json:"revisions"`
+}
+
+type RollbackResponse struct {
+	ID       int64  `json:"id"`
+	Url      string `json:"url"`
+	Alias    string `json:"alias"`
+	ShortURL string `json:"shortUrl"`
+}
+
+//go:generate mockery --name=RevisionLister --output=./mocks
+type RevisionLister interface {
+	ListRevisions(ctx context.Context, id int64, userID int64) ([]models.Revision, error)
+}
+
+//go:generate mockery --name=LinkRollbacker --output=./mocks
+type LinkRollbacker interface {
+	GetRevision(ctx context.Context, id int64, revisionID int64, userID int64) (models.Revision, error)
+	RollbackLink(ctx context.Context, id int64, revisionID int64, actor models.Actor) (models.AliasNote, error)
+}
+
+// NewList returns the revisions of the user's link /links/{id}/revisions, newest first.
+func NewList(log *slog.Logger, revisionLister RevisionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.revisions.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		actor, ok := actorFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := pathID(w, r, log, "id", "invalid link id")
+		if !ok {
+			return
+		}
+
+		revisions, err := revisionLister.ListRevisions(r.Context(), id, actor.UserID)
+		switch {
+		case errors.Is(err, storage.ErrAliasNotFound):
+			log.Info("link not found", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("link not found"))
+			return
+		case err != nil:
+			log.Error("failed to list revisions", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		render.JSON(w, r, ListResponse{Revisions: revisions})
+	}
+}
+
+// NewRollback returns the user's link /links/{id}/revisions/{revision}/rollback
+// to the destination and the alias it had before the revision. If urlScreener
+// is not nil, the destination must pass it again: it may have been saved
+// before screening or blocklisted since.
+func NewRollback(log *slog.Logger, linkRollbacker LinkRollbacker, urlScreener save.URLScreener, publicURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.revisions.NewRollback"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		actor, ok := actorFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := pathID(w, r, log, "id", "invalid link id")
+		if !ok {
+			return
+		}
+
+		revisionID, ok := pathID(w, r, log, "revision", "invalid revision id")
+		if !ok {
+			return
+		}
+
+		// Ревизии не меняются, поэтому проверенный адрес совпадает с восстановленным
+		if urlScreener != nil {
+			revision, err := linkRollbacker.GetRevision(r.Context(), id, revisionID, actor.UserID)
+			if err != nil {
+				rollbackFailed(w, r, log, id, revisionID, err)
+				return
+			}
+
+			if err := urlScreener.Screen(r.Context(), revision.OldURL); err != nil {
+				if errors.Is(err, screening.ErrRejected) {
+					log.Info("destination rejected", slog.String("url", revision.OldURL), sl.Err(err))
+					render.Status(r, http.StatusBadRequest)
+					render.JSON(w, r, resp.Error(err.Error()))
+					return
+				}
+
+				log.Error("failed to screen destination", sl.Err(err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to check url"))
+				return
+			}
+		}
+
+		note, err := linkRollbacker.RollbackLink(r.Context(), id, revisionID, actor)
+		if err != nil {
+			rollbackFailed(w, r, log, id, revisionID, err)
+			return
+		}
+
+		log.Info("link rolled back", slog.Int64("id", id), slog.Int64("revision", revisionID))
+
+		render.JSON(w, r, RollbackResponse{
+			ID:       note.ID,
+			Url:      note.Url,
+			Alias:    note.Alias,
+			ShortURL: models.ShortURL(publicURL, note.Domain, note.Alias),
+		})
+	}
+}
+
+// rollbackFailed writes the response for an error of the rollback storage calls.
+func rollbackFailed(w http.ResponseWriter, r *http.Request, log *slog.Logger, id int64, revisionID int64, err error) {
+	switch {
+	case errors.Is(err, storage.ErrAliasNotFound):
+		log.Info("link not found", slog.Int64("id", id))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error("link not found"))
+	case errors.Is(err, storage.ErrRevisionNotFound):
+		log.Info("revision not found", slog.Int64("id", id), slog.Int64("revision", revisionID))
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error("revision not found"))
+	case errors.Is(err, storage.ErrAliasExist):
+		log.Info("old alias is taken", slog.Int64("id", id), slog.Int64("revision", revisionID))
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, resp.Error("alias of the revision is taken by another link"))
+	default:
+		log.Error("failed to roll back link", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to roll back link"))
+	}
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, log *slog.Logger, param string, message string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil || id <= 0 {
+		log.Info(message, slog.String(param, chi.URLParam(r, param)))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(message))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func actorFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (models.Actor, bool) {
+	// Берем из контекста данные JWT токена
+	actor, err := jwtlib.GetActorFromContext(r.Context())
+	if err != nil {
+		log.Error("failed to get actor from claims", sl.Err(err))
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to get claims"))
+		return models.Actor{}, false
+	}
+
+	return actor, true
+}
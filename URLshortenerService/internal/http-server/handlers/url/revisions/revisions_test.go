@@ -0,0 +1,257 @@
+package revisions_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/revisions"
+	"URLshortener/internal/http-server/handlers/url/revisions/mocks"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/lib/screening"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var actor = models.Actor{UserID: 1, Role: "user"}
+
+func withClaims(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1), "role": "user"}))
+}
+
+func TestListHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		path       string
+		callMock   bool
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Success",
+			path:       "/links/5/revisions",
+			callMock:   true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Link not found",
+			path:       "/links/5/revisions",
+			callMock:   true,
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "link not found",
+		},
+		{
+			name:       "Invalid id",
+			path:       "/links/abc/revisions",
+			respStatus: http.StatusBadRequest,
+			respError:  "invalid link id",
+		},
+		{
+			name:       "Storage error",
+			path:       "/links/5/revisions",
+			callMock:   true,
+			mockError:  errors.New("unexpected error"),
+			respStatus: http.StatusInternalServerError,
+			respError:  "internal error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			listerMock := mocks.NewRevisionLister(t)
+			if tc.callMock {
+				listerMock.On("ListRevisions", mock.Anything, int64(5), int64(1)).
+					Return([]models.Revision{{ID: 2, URLID: 5, Change: models.ChangeURL, OldURL: "https://a.com", NewURL: "https://b.com"}}, tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/links/{id}/revisions", revisions.NewList(slogdiscard.NewDiscardLogger(), listerMock))
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				Error     string            `json:"message"`
+				Revisions []models.Revision `json:"revisions"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Len(t, resp.Revisions, 1)
+				require.Equal(t, "https://a.com", resp.Revisions[0].OldURL)
+			}
+		})
+	}
+}
+
+func TestRollbackHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		path       string
+		callMock   bool
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Success",
+			path:       "/links/5/revisions/2/rollback",
+			callMock:   true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Revision not found",
+			path:       "/links/5/revisions/2/rollback",
+			callMock:   true,
+			mockError:  storage.ErrRevisionNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "revision not found",
+		},
+		{
+			name:       "Link not found",
+			path:       "/links/5/revisions/2/rollback",
+			callMock:   true,
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "link not found",
+		},
+		{
+			name:       "Old alias taken",
+			path:       "/links/5/revisions/2/rollback",
+			callMock:   true,
+			mockError:  storage.ErrAliasExist,
+			respStatus: http.StatusConflict,
+			respError:  "alias of the revision is taken by another link",
+		},
+		{
+			name:       "Invalid revision id",
+			path:       "/links/5/revisions/0/rollback",
+			respStatus: http.StatusBadRequest,
+			respError:  "invalid revision id",
+		},
+		{
+			name:       "Storage error",
+			path:       "/links/5/revisions/2/rollback",
+			callMock:   true,
+			mockError:  errors.New("unexpected error"),
+			respStatus: http.StatusInternalServerError,
+			respError:  "failed to roll back link",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rollbackerMock := mocks.NewLinkRollbacker(t)
+			if tc.callMock {
+				rollbackerMock.On("RollbackLink", mock.Anything, int64(5), int64(2), actor).
+					Return(models.AliasNote{ID: 5, Url: "https://a.com", Alias: "old"}, tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Post("/links/{id}/revisions/{revision}/rollback",
+				revisions.NewRollback(slogdiscard.NewDiscardLogger(), rollbackerMock, nil, "https://sho.rt"))
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				Error string `json:"message"`
+				revisions.RollbackResponse
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, "https://sho.rt/old", resp.ShortURL)
+				require.Equal(t, "https://a.com", resp.Url)
+			}
+		})
+	}
+}
+
+func TestRollbackHandler_Screening(t *testing.T) {
+	testCases := []struct {
+		name       string
+		oldURL     string
+		callMock   bool
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Allowed destination",
+			oldURL:     "https://a.com",
+			callMock:   true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Rejected destination",
+			oldURL:     "javascript:alert(1)",
+			respStatus: http.StatusBadRequest,
+			respError:  screening.ErrScheme.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rollbackerMock := mocks.NewLinkRollbacker(t)
+			rollbackerMock.On("GetRevision", mock.Anything, int64(5), int64(2), int64(1)).
+				Return(models.Revision{ID: 2, URLID: 5, OldURL: tc.oldURL}, nil).
+				Once()
+			if tc.callMock {
+				rollbackerMock.On("RollbackLink", mock.Anything, int64(5), int64(2), actor).
+					Return(models.AliasNote{ID: 5, Url: tc.oldURL, Alias: "old"}, nil).
+					Once()
+			}
+
+			screener := screening.New(screening.Schemes("http", "https"))
+
+			r := chi.NewRouter()
+			r.Post("/links/{id}/revisions/{revision}/rollback",
+				revisions.NewRollback(slogdiscard.NewDiscardLogger(), rollbackerMock, screener, "https://sho.rt"))
+
+			req := httptest.NewRequest(http.MethodPost, "/links/5/revisions/2/rollback", nil)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				Error string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
@@ -2,16 +2,24 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	models "URLshortener/internal/domain/models"
+
+	quota "URLshortener/internal/lib/quota"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // URLSaver is an autogenerated mock type for the URLSaver type
 type URLSaver struct {
 	mock.Mock
 }
 
-// SaveURL provides a mock function with given fields: urlToSave, alias
-func (_m *URLSaver) SaveURL(urlToSave string, alias string) (int64, error) {
-	ret := _m.Called(urlToSave, alias)
+// SaveURL provides a mock function with given fields: ctx, note, userID, limits
+func (_m *URLSaver) SaveURL(ctx context.Context, note models.AliasNote, userID int64, limits quota.Limits) (int64, error) {
+	ret := _m.Called(ctx, note, userID, limits)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveURL")
@@ -19,17 +27,17 @@ func (_m *URLSaver) SaveURL(urlToSave string, alias string) (int64, error) {
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (int64, error)); ok {
-		return rf(urlToSave, alias)
+	if rf, ok := ret.Get(0).(func(context.Context, models.AliasNote, int64, quota.Limits) (int64, error)); ok {
+		return rf(ctx, note, userID, limits)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) int64); ok {
-		r0 = rf(urlToSave, alias)
+	if rf, ok := ret.Get(0).(func(context.Context, models.AliasNote, int64, quota.Limits) int64); ok {
+		r0 = rf(ctx, note, userID, limits)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(urlToSave, alias)
+	if rf, ok := ret.Get(1).(func(context.Context, models.AliasNote, int64, quota.Limits) error); ok {
+		r1 = rf(ctx, note, userID, limits)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,40 +1,99 @@
 package save
 
 import (
+	"URLshortener/internal/domain/models"
 	jwtlib "URLshortener/internal/jwt"
+	aliaslib "URLshortener/internal/lib/alias"
 	resp "URLshortener/internal/lib/api/response"
 	"URLshortener/internal/lib/logger/sl"
-	"URLshortener/internal/lib/random"
+	"URLshortener/internal/lib/pagemeta"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/lib/screening"
 	"URLshortener/internal/storage"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type Request struct {
-	URL   string `json:"url" validate:"required,url"`
-	Alias string `json:"alias,omitempty"`
+	URL          string     `json:"url" validate:"required,url"`
+	Alias        string     `json:"alias,omitempty"`
+	Domain       string     `json:"domain,omitempty" validate:"omitempty,hostname_rfc1123"`
+	RedirectCode int        `json:"redirectCode,omitempty" validate:"omitempty,oneof=301 302 307 308"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	MaxClicks    *int64     `json:"maxClicks,omitempty" validate:"omitempty,min=1"`
+	Password     string     `json:"password,omitempty" validate:"omitempty,max=72"`
+	Title        string     `json:"title,omitempty" validate:"max=200"`
+	Description  string     `json:"description,omitempty" validate:"max=1000"`
+	Notes        string     `json:"notes,omitempty" validate:"max=5000"`
 }
 
 type Response struct {
-	Id    int64  `json:"id,omitempty"`
-	Url   string `json:"url,omitempty"`
-	Alias string `json:"alias,omitempty"`
-	Error string `json:"message,omitempty"`
+	Id           int64      `json:"id,omitempty"`
+	Url          string     `json:"url,omitempty"`
+	Alias        string     `json:"alias,omitempty"`
+	Domain       string     `json:"domain,omitempty"`
+	RedirectCode int        `json:"redirectCode,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	MaxClicks    *int64     `json:"maxClicks,omitempty"`
+	Protected    bool       `json:"protected,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Description  string     `json:"description,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	Error        string     `json:"message,omitempty"`
 }
 
-// TODO: move to config
-const AliasLength = 6
+var ErrExpiresInPast = errors.New("field ExpiresAt must be in the future")
 
 //go:generate mockery --name=URLSaver --output=./mocks
 type URLSaver interface {
-	SaveURL(urlToSave string, alias string, userID int64) (int64, error)
+	SaveURL(ctx context.Context, note models.AliasNote, userID int64, limits quota.Limits) (int64, error)
 }
 
-func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
+// AliasAllocator generates an alias for links created without one and
+// retries while the generated alias is taken.
+type AliasAllocator interface {
+	Allocate(ctx context.Context, try func(alias string) error) (string, error)
+}
+
+// MetadataFetcher reads the title and description of the destination page.
+//
+//go:generate mockery --name=MetadataFetcher --output=./mocks
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, url string) (pagemeta.Metadata, error)
+}
+
+// URLScreener checks that a destination is safe to redirect to. Rejected
+// destinations get an error wrapping screening.ErrRejected.
+//
+//go:generate mockery --name=URLScreener --output=./mocks
+type URLScreener interface {
+	Screen(ctx context.Context, rawURL string) error
+}
+
+// QuotaChecker checks that the user may create n more links. Exceeded quotas
+// are reported with an error wrapping quota.ErrExceeded. Check rejects early,
+// the storage enforces Limits again when the links are stored.
+//
+//go:generate mockery --name=QuotaChecker --output=./mocks
+type QuotaChecker interface {
+	Check(ctx context.Context, actor models.Actor, n int) error
+	Limits(actor models.Actor) quota.Limits
+}
+
+// New creates a link of the user. If urlScreener is not nil, the destination
+// must pass it, if quotaChecker is not nil, the link must fit in the quota of
+// the user's role. If metadataFetcher is not nil, the title and description the
+// request leaves empty are taken from the destination page.
+func New(log *slog.Logger, urlSaver URLSaver, aliasAllocator AliasAllocator, urlScreener URLScreener, quotaChecker QuotaChecker, metadataFetcher MetadataFetcher) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.save.New"
 
@@ -43,32 +102,14 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		// Берем из контекста данные JWT токена
-		claims, err := jwtlib.GetClaimsFromContext(r.Context())
+		actor, err := jwtlib.GetActorFromContext(r.Context())
 		if err != nil {
-			log.Error("failed to get claims from context")
+			log.Error("failed to get actor from claims", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, Response{Error: "failed to get claims"})
 			return
 		}
-
-		userIDAny, ok := claims["uid"]
-		if !ok {
-			log.Error("failed to get field uid from claims")
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, Response{Error: "internal error"})
-			return
-		}
-		userID := int64(userIDAny.(float64))
-
-		//roleAny, ok := claims["role"]
-		//if !ok {
-		//	log.Error("failed to get field role from claims")
-		//	render.Status(r, http.StatusInternalServerError)
-		//	render.JSON(w, r, resp.Error("internal error"))
-		//	return
-		//}
-		//role := roleAny.(string)
+		userID := actor.UserID
 
 		// Считывание из JSON
 		var req Request
@@ -79,31 +120,114 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
 			return
 		}
 
-		log.Info("request body decoded", slog.Any("request", req))
+		log.Info("request body decoded", slog.String("url", req.URL), slog.String("alias", req.Alias))
 		// Валидация считанной структуры
-		if err := validator.New().Struct(req); err != nil {
-			validateErr := err.(validator.ValidationErrors)
-
-			log.Error("invalid request", sl.Err(err))
+		if err := Validate(req); err != nil {
+			log.Info("invalid request", sl.Err(err))
 
 			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, Response{Error: resp.ValidationError(validateErr)})
+			render.JSON(w, r, Response{Error: err.Error()})
+			return
+		}
+
+		if urlScreener != nil {
+			if err := urlScreener.Screen(r.Context(), req.URL); err != nil {
+				if errors.Is(err, screening.ErrRejected) {
+					log.Info("destination rejected", slog.String("url", req.URL), sl.Err(err))
+
+					render.Status(r, http.StatusBadRequest)
+					render.JSON(w, r, Response{Error: err.Error()})
+					return
+				}
+
+				log.Error("failed to screen destination", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, Response{Error: "failed to check url"})
+				return
+			}
+		}
+
+		var limits quota.Limits
+		if quotaChecker != nil {
+			if err := quotaChecker.Check(r.Context(), actor, 1); err != nil {
+				if errors.Is(err, quota.ErrExceeded) {
+					log.Info("link quota exceeded", sl.Err(err))
+
+					render.Status(r, http.StatusForbidden)
+					render.JSON(w, r, Response{Error: err.Error()})
+					return
+				}
+
+				log.Error("failed to check quota", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, Response{Error: "failed to check quota"})
+				return
+			}
+			limits = quotaChecker.Limits(actor)
+		}
+
+		note, err := NewNote(req)
+		if err != nil {
+			log.Error("failed to prepare link", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, Response{Error: "internal error"})
 			return
 		}
 
-		// Проверка на наличие alias в запросе
-		// TODO: проверка на ошибку одинаковых рандомных имен
-		alias := req.Alias
-		if alias == "" {
-			alias = random.NewRandomString(AliasLength)
+		// Метаданные страницы не обязательны, ссылка создается и без них
+		if metadataFetcher != nil && (note.Title == "" || note.Description == "") {
+			meta, err := metadataFetcher.Fetch(r.Context(), note.Url)
+			if err != nil {
+				log.Warn("failed to fetch page metadata", slog.String("url", note.Url), sl.Err(err))
+			}
+			if note.Title == "" {
+				note.Title = meta.Title
+			}
+			if note.Description == "" {
+				note.Description = meta.Description
+			}
 		}
+
 		// Запись в storage
-		id, err := urlSaver.SaveURL(req.URL, alias, userID)
+		var id int64
+		if note.Alias == "" {
+			note.Alias, err = aliasAllocator.Allocate(r.Context(), func(alias string) error {
+				generated := note
+				generated.Alias = alias
+
+				var err error
+				id, err = urlSaver.SaveURL(r.Context(), generated, userID, limits)
+				return err
+			})
+		} else {
+			id, err = urlSaver.SaveURL(r.Context(), note, userID, limits)
+		}
 		switch {
+		case errors.Is(err, quota.ErrExceeded):
+			log.Info("link quota exceeded", sl.Err(err))
+
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, Response{Error: err.Error()})
+			return
+		case errors.Is(err, aliaslib.ErrNoFreeAlias):
+			log.Error("failed to generate a free alias", sl.Err(err))
+
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, Response{Error: "failed to generate a free alias, try again"})
+			return
+		case errors.Is(err, storage.ErrDomainNotFound), errors.Is(err, storage.ErrDomainNotVerified):
+			log.Info("domain is not available", slog.String("domain", note.Domain), sl.Err(err))
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, Response{Error: DomainError(err)})
+			return
 		case errors.Is(err, storage.ErrAliasExist):
 			log.Info("alias already exists", slog.String("url", req.URL))
 
-			render.Status(r, http.StatusBadRequest)
+			render.Status(r, http.StatusConflict)
 			render.JSON(w, r, Response{Error: "Такой алиас уже существует"})
 			return
 		case err != nil:
@@ -117,9 +241,85 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
 		log.Info("url added", slog.Int64("id", id))
 
 		render.JSON(w, r, Response{
-			Id:    id,
-			Url:   req.URL,
-			Alias: alias,
+			Id:           id,
+			Url:          note.Url,
+			Alias:        note.Alias,
+			Domain:       note.Domain,
+			RedirectCode: note.RedirectCode,
+			ExpiresAt:    note.ExpiresAt,
+			MaxClicks:    note.MaxClicks,
+			Protected:    note.Protected,
+			Title:        note.Title,
+			Description:  note.Description,
+			Notes:        note.Notes,
 		})
 	}
 }
+
+// Validate checks the request with the rules shared by single and batch
+// creation. The text of the returned error can be shown to the client.
+func Validate(req Request) error {
+	if err := validator.New().Struct(req); err != nil {
+		var validateErr validator.ValidationErrors
+		if errors.As(err, &validateErr) {
+			return errors.New(resp.ValidationError(validateErr))
+		}
+		return err
+	}
+
+	// Пустой алиас генерируется, его проверять не нужно
+	if req.Alias != "" {
+		if err := aliaslib.Validate(req.Alias); err != nil {
+			return err
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return ErrExpiresInPast
+	}
+
+	return nil
+}
+
+// NewNote turns a valid request into a link to store: it fills the default
+// redirect code and hashes the password. The alias stays empty if it was
+// not requested and has to be generated by the caller.
+func NewNote(req Request) (models.AliasNote, error) {
+	const op = "handlers.url.save.NewNote"
+
+	note := models.AliasNote{
+		Url:          req.URL,
+		Alias:        req.Alias,
+		Domain:       strings.ToLower(req.Domain),
+		RedirectCode: req.RedirectCode,
+		ExpiresAt:    req.ExpiresAt,
+		MaxClicks:    req.MaxClicks,
+		Title:        strings.TrimSpace(req.Title),
+		Description:  strings.TrimSpace(req.Description),
+		Notes:        req.Notes,
+	}
+
+	if note.RedirectCode == 0 {
+		note.RedirectCode = http.StatusFound
+	}
+
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return models.AliasNote{}, fmt.Errorf("%s: %w", op, err)
+		}
+		note.PasswordHash = hash
+		note.Protected = true
+	}
+
+	return note, nil
+}
+
+// DomainError returns the message shown to the client when a link cannot be
+// created on the requested domain.
+func DomainError(err error) string {
+	if errors.Is(err, storage.ErrDomainNotVerified) {
+		return storage.ErrDomainNotVerified.Error()
+	}
+	return storage.ErrDomainNotFound.Error()
+}
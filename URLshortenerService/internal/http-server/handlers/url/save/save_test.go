@@ -2,6 +2,7 @@ package save_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,12 +10,20 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"URLshortener/internal/domain/models"
 	"URLshortener/internal/http-server/handlers/url/save"
 	"URLshortener/internal/http-server/handlers/url/save/mocks"
+	"URLshortener/internal/lib/alias"
 	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/lib/pagemeta"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/lib/random"
+	"URLshortener/internal/lib/screening"
+	"URLshortener/internal/storage"
 )
 
 func TestSaveHandler(t *testing.T) {
@@ -22,6 +31,8 @@ func TestSaveHandler(t *testing.T) {
 		name      string
 		alias     string
 		url       string
+		code      int
+		extra     string
 		respError string
 		mockError error
 		status    int
@@ -52,16 +63,65 @@ func TestSaveHandler(t *testing.T) {
 			respError: "field URL is not a valid URL",
 			status:    http.StatusBadRequest,
 		},
+		{
+			name:      "Invalid redirect code",
+			url:       "https://google.com",
+			alias:     "some_alias",
+			code:      200,
+			respError: "field RedirectCode is not valid",
+			status:    http.StatusBadRequest,
+		},
+		{
+			name:      "Expiration in the past",
+			url:       "https://google.com",
+			alias:     "some_alias",
+			extra:     `, "expiresAt": "2000-01-01T00:00:00Z"`,
+			respError: "field ExpiresAt must be in the future",
+			status:    http.StatusBadRequest,
+		},
+		{
+			name:      "Invalid max clicks",
+			url:       "https://google.com",
+			alias:     "some_alias",
+			extra:     `, "maxClicks": 0`,
+			respError: "field MaxClicks is not valid",
+			status:    http.StatusBadRequest,
+		},
+		{
+			name:      "Alias with a dot",
+			url:       "https://google.com",
+			alias:     "promo.html",
+			respError: alias.ErrInvalidAlias.Error(),
+			status:    http.StatusBadRequest,
+		},
+		{
+			name:      "Reserved alias",
+			url:       "https://google.com",
+			alias:     "healthz",
+			respError: alias.ErrReservedAlias.Error(),
+			status:    http.StatusBadRequest,
+		},
+		{
+			name:   "With limits",
+			url:    "https://google.com",
+			alias:  "limited",
+			extra:  `, "expiresAt": "2999-01-01T00:00:00Z", "maxClicks": 1`,
+			status: http.StatusOK,
+		},
 		{
 			name:      "SaveURL Error",
 			alias:     "test_alias",
 			url:       "https://google.com",
-			respError: "failed to add url",
+			respError: "failed to add alias",
 			mockError: errors.New("unexpected error"),
 			status:    http.StatusInternalServerError,
 		},
 	}
 
+	generator, err := alias.NewRandom(6, random.Base62)
+	require.NoError(t, err)
+	aliases := alias.NewAllocator(generator, 3)
+
 	for _, tc := range cases {
 		tc := tc
 
@@ -71,17 +131,20 @@ func TestSaveHandler(t *testing.T) {
 			urlSaverMock := mocks.NewURLSaver(t)
 
 			if tc.respError == "" || tc.mockError != nil {
-				urlSaverMock.On("SaveURL", tc.url, mock.AnythingOfType("string")).
+				urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(note models.AliasNote) bool {
+					return note.Url == tc.url && note.Alias != "" && note.RedirectCode == http.StatusFound
+				}), int64(1), quota.Limits{}).
 					Return(int64(1), tc.mockError).
 					Once()
 			}
 
-			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock)
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, aliases, nil, nil, nil)
 
-			input := fmt.Sprintf(`{"url": "%s", "alias": "%s"}`, tc.url, tc.alias)
+			input := fmt.Sprintf(`{"url": "%s", "alias": "%s", "redirectCode": %d%s}`, tc.url, tc.alias, tc.code, tc.extra)
 
 			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(input)))
 			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
 
 			rr := httptest.NewRecorder()
 			handler.ServeHTTP(rr, req)
@@ -101,3 +164,279 @@ func TestSaveHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestSaveHandler_GeneratedAliasTaken(t *testing.T) {
+	generator, err := alias.NewRandom(6, random.Base62)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		failures int
+		status   int
+	}{
+		{
+			name:     "Retried",
+			failures: 2,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "Attempts exhausted",
+			failures: 3,
+			status:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlSaverMock := mocks.NewURLSaver(t)
+			urlSaverMock.On("SaveURL", mock.Anything, mock.Anything, int64(1), quota.Limits{}).
+				Return(int64(0), storage.ErrAliasExist).
+				Times(tc.failures)
+			if tc.failures < 3 {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.Anything, int64(1), quota.Limits{}).Return(int64(1), nil).Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, alias.NewAllocator(generator, 3), nil, nil, nil)
+
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "https://google.com"}`)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			if tc.status == http.StatusOK {
+				require.Len(t, resp.Alias, 6)
+			}
+		})
+	}
+}
+
+func TestSaveHandler_Metadata(t *testing.T) {
+	generator, err := alias.NewRandom(6, random.Base62)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		input       string
+		fetch       bool
+		fetchError  error
+		title       string
+		description string
+	}{
+		{
+			name:        "Fetched",
+			input:       `{"url": "https://google.com", "notes": "for the team"}`,
+			fetch:       true,
+			title:       "Google",
+			description: "Search the world's information",
+		},
+		{
+			name:        "Request title kept",
+			input:       `{"url": "https://google.com", "title": " Search "}`,
+			fetch:       true,
+			title:       "Search",
+			description: "Search the world's information",
+		},
+		{
+			name:        "Not fetched when set",
+			input:       `{"url": "https://google.com", "title": "Search", "description": "Engine"}`,
+			title:       "Search",
+			description: "Engine",
+		},
+		{
+			name:       "Fetch failed",
+			input:      `{"url": "https://google.com"}`,
+			fetch:      true,
+			fetchError: errors.New("timeout"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fetcherMock := mocks.NewMetadataFetcher(t)
+			if tc.fetch {
+				meta := pagemeta.Metadata{Title: "Google", Description: "Search the world's information"}
+				if tc.fetchError != nil {
+					meta = pagemeta.Metadata{}
+				}
+				fetcherMock.On("Fetch", mock.Anything, "https://google.com").Return(meta, tc.fetchError).Once()
+			}
+
+			urlSaverMock := mocks.NewURLSaver(t)
+			urlSaverMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(note models.AliasNote) bool {
+				return note.Title == tc.title && note.Description == tc.description
+			}), int64(1), quota.Limits{}).
+				Return(int64(1), nil).
+				Once()
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, alias.NewAllocator(generator, 3), nil, nil, fetcherMock)
+
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(tc.input)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.title, resp.Title)
+			require.Equal(t, tc.description, resp.Description)
+		})
+	}
+}
+
+func TestSaveHandler_Screening(t *testing.T) {
+	generator, err := alias.NewRandom(6, random.Base62)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		url         string
+		screenError error
+		status      int
+		respError   string
+	}{
+		{
+			name:   "Allowed",
+			url:    "https://google.com",
+			status: http.StatusOK,
+		},
+		{
+			name:        "Rejected",
+			url:         "http://127.0.0.1/admin",
+			screenError: screening.ErrPrivateAddress,
+			status:      http.StatusBadRequest,
+			respError:   "destination is not allowed: private or loopback address",
+		},
+		{
+			name:        "Screening failed",
+			url:         "https://google.com",
+			screenError: errors.New("reputation service is down"),
+			status:      http.StatusInternalServerError,
+			respError:   "failed to check url",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			screenerMock := mocks.NewURLScreener(t)
+			screenerMock.On("Screen", mock.Anything, tc.url).Return(tc.screenError).Once()
+
+			urlSaverMock := mocks.NewURLSaver(t)
+			if tc.screenError == nil {
+				urlSaverMock.On("SaveURL", mock.Anything, mock.AnythingOfType("models.AliasNote"), int64(1), quota.Limits{}).
+					Return(int64(1), nil).
+					Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, alias.NewAllocator(generator, 3), screenerMock, nil, nil)
+
+			input := fmt.Sprintf(`{"url": %q}`, tc.url)
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(input)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
+
+func TestSaveHandler_Quota(t *testing.T) {
+	generator, err := alias.NewRandom(6, random.Base62)
+	require.NoError(t, err)
+
+	actor := models.Actor{UserID: 1, Role: "user"}
+	limits := quota.Limits{MaxDaily: 10}
+
+	cases := []struct {
+		name       string
+		quotaError error
+		saveError  error
+		status     int
+		respError  string
+	}{
+		{
+			name:   "Within quota",
+			status: http.StatusOK,
+		},
+		{
+			name:      "Quota used up concurrently",
+			saveError: fmt.Errorf("%w: at most 10 links per day", quota.ErrExceeded),
+			status:    http.StatusForbidden,
+			respError: "link quota exceeded: at most 10 links per day",
+		},
+		{
+			name:       "Quota exceeded",
+			quotaError: fmt.Errorf("%w: at most 10 links per day", quota.ErrExceeded),
+			status:     http.StatusForbidden,
+			respError:  "link quota exceeded: at most 10 links per day",
+		},
+		{
+			name:       "Quota check failed",
+			quotaError: errors.New("db is down"),
+			status:     http.StatusInternalServerError,
+			respError:  "failed to check quota",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			quotaMock := mocks.NewQuotaChecker(t)
+			quotaMock.On("Check", mock.Anything, actor, 1).Return(tc.quotaError).Once()
+
+			urlSaverMock := mocks.NewURLSaver(t)
+			if tc.quotaError == nil {
+				quotaMock.On("Limits", actor).Return(limits).Once()
+				urlSaverMock.On("SaveURL", mock.Anything, mock.AnythingOfType("models.AliasNote"), int64(1), limits).
+					Return(int64(1), tc.saveError).
+					Once()
+			}
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, alias.NewAllocator(generator, 3), nil, quotaMock, nil)
+
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(`{"url": "https://google.com"}`)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1), "role": "user"}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var resp save.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package transfer
+
+import (
+	"URLshortener/internal/domain/models"
+	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// csvColumns is the header of exported CSV. Import accepts the same header.
+var csvColumns = []string{"url", "alias", "redirectCode", "expiresAt", "maxClicks", "clicksUsed", "protected", "createdAt", "clicks", "domain", "title", "description", "notes"}
+
+//go:generate mockery --name=LinkExporter --output=./mocks
+type LinkExporter interface {
+	ExportUserLinks(ctx context.Context, userID int64, fn func(models.LinkItem) error) error
+}
+
+// NewExport streams all links of the user with their click totals as CSV
+// (the default) or NDJSON: ?format=csv|ndjson or /export.ndjson.
+// Passwords are not exported, protected links are only marked.
+func NewExport(log *slog.Logger, exporter LinkExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.transfer.NewExport"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format, _ = r.Context().Value(middleware.URLFormatCtxKey).(string)
+		}
+		if format == "" {
+			format = FormatCSV
+		}
+
+		var write func(models.LinkItem) error
+		var flush func() error
+
+		switch format {
+		case FormatCSV:
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="links.csv"`)
+
+			// Заголовок остаётся в буфере writer'а до первых строк
+			cw := csv.NewWriter(w)
+			_ = cw.Write(csvColumns)
+			write = func(item models.LinkItem) error {
+				return cw.Write(csvRecord(item))
+			}
+			flush = func() error {
+				cw.Flush()
+				return cw.Error()
+			}
+		case FormatNDJSON:
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", `attachment; filename="links.ndjson"`)
+
+			enc := json.NewEncoder(w)
+			write = func(item models.LinkItem) error {
+				return enc.Encode(item)
+			}
+			flush = func() error { return nil }
+		default:
+			log.Info("invalid format", slog.String("format", format))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field format must be csv or ndjson"))
+			return
+		}
+
+		exported := 0
+		err := exporter.ExportUserLinks(r.Context(), userID, func(item models.LinkItem) error {
+			exported++
+			return write(item)
+		})
+		if err == nil {
+			err = flush()
+		}
+		if err != nil {
+			// Если строки уже ушли клиенту, статус ответа поменять нельзя
+			log.Error("failed to export links", slog.Int("exported", exported), sl.Err(err))
+			if exported == 0 {
+				w.Header().Del("Content-Disposition")
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to export links"))
+			}
+			return
+		}
+
+		log.Info("links exported", slog.Int("count", exported))
+	}
+}
+
+func csvRecord(item models.LinkItem) []string {
+	return []string{
+		item.Url,
+		item.Alias,
+		strconv.Itoa(item.RedirectCode),
+		formatTime(item.ExpiresAt),
+		formatInt(item.MaxClicks),
+		strconv.FormatInt(item.ClicksUsed, 10),
+		strconv.FormatBool(item.Protected),
+		item.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(item.Clicks, 10),
+		item.Domain,
+		item.Title,
+		item.Description,
+		item.Notes,
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatInt(n *int64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatInt(*n, 10)
+}
+
+func userIDFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	// Берем из контекста данные JWT токена
+	claims, err := jwtlib.GetClaimsFromContext(r.Context())
+	if err != nil {
+		log.Error("failed to get claims from context")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to get claims"))
+		return 0, false
+	}
+
+	userIDAny, ok := claims["uid"]
+	if !ok {
+		log.Error("failed to get field uid from claims")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("internal error"))
+		return 0, false
+	}
+
+	return int64(userIDAny.(float64)), true
+}
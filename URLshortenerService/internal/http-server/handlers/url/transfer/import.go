@@ -0,0 +1,525 @@
+package transfer
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/save"
+	jwtlib "URLshortener/internal/jwt"
+	aliaslib "URLshortener/internal/lib/alias"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/lib/screening"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+
+	StatusCreated     = "created"
+	StatusOverwritten = "overwritten"
+	StatusRenamed     = "renamed"
+	StatusSkipped     = "skipped"
+	StatusFailed      = "failed"
+)
+
+const (
+	maxImportSize = 50 << 20
+	// maxRenameAttempts limits the search of a free alias-N name.
+	maxRenameAttempts = 100
+)
+
+// Item is a link read from an import file. The fields match the export.
+type Item struct {
+	URL          string     `json:"url"`
+	Alias        string     `json:"alias"`
+	Domain       string     `json:"domain"`
+	RedirectCode int        `json:"redirectCode"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+	MaxClicks    *int64     `json:"maxClicks"`
+	ClicksUsed   int64      `json:"clicksUsed"`
+	CreatedAt    *time.Time `json:"createdAt"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Notes        string     `json:"notes"`
+}
+
+type ItemResult struct {
+	Index         int    `json:"index"`
+	Status        string `json:"status"`
+	ID            int64  `json:"id,omitempty"`
+	Alias         string `json:"alias,omitempty"`
+	Domain        string `json:"domain,omitempty"`
+	OriginalAlias string `json:"originalAlias,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun     bool           `json:"dryRun"`
+	OnConflict string         `json:"onConflict"`
+	Summary    map[string]int `json:"summary"`
+	Items      []ItemResult   `json:"items"`
+	Error      string         `json:"message,omitempty"`
+}
+
+//go:generate mockery --name=LinkImporter --output=./mocks
+type LinkImporter interface {
+	SaveURL(ctx context.Context, note models.AliasNote, userID int64, limits quota.Limits) (int64, error)
+	OverwriteLink(ctx context.Context, note models.AliasNote, userID int64) (int64, error)
+	GetAliasOwner(ctx context.Context, domain string, alias string) (int64, error)
+}
+
+// AliasAllocator generates aliases for imported links without one.
+type AliasAllocator interface {
+	Generate(ctx context.Context) (string, error)
+	Allocate(ctx context.Context, try func(alias string) error) (string, error)
+}
+
+// NewImport reads links in the export format (CSV or NDJSON, chosen by
+// Content-Type or ?format=) and stores them one by one.
+// ?onConflict=skip|overwrite|rename decides what happens to aliases that
+// already exist, ?dryRun=true only reports what would be done. If urlScreener
+// is not nil, items with destinations it rejects fail. If quotaChecker is not
+// nil, new links beyond the user's quota fail, overwrites are not counted.
+func NewImport(log *slog.Logger, linkImporter LinkImporter, aliasAllocator AliasAllocator, urlScreener save.URLScreener, quotaChecker save.QuotaChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.transfer.NewImport"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		actor, err := jwtlib.GetActorFromContext(r.Context())
+		if err != nil {
+			log.Error("failed to get actor from claims", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to get claims"))
+			return
+		}
+
+		q := r.URL.Query()
+
+		onConflict := q.Get("onConflict")
+		if onConflict == "" {
+			onConflict = ConflictSkip
+		}
+		if onConflict != ConflictSkip && onConflict != ConflictOverwrite && onConflict != ConflictRename {
+			log.Info("invalid conflict policy", slog.String("onConflict", onConflict))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error("field onConflict must be skip, overwrite or rename"))
+			return
+		}
+
+		dryRun := false
+		if v := q.Get("dryRun"); v != "" {
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				log.Info("invalid dryRun parameter", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error("field dryRun must be a boolean"))
+				return
+			}
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+		next, err := newItemReader(r, body)
+		if err != nil {
+			log.Info("invalid import file", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.Error(err.Error()))
+			return
+		}
+
+		imp := importer{
+			log:        log,
+			storage:    linkImporter,
+			allocator:  aliasAllocator,
+			screener:   urlScreener,
+			quota:      quotaChecker,
+			actor:      actor,
+			onConflict: onConflict,
+			dryRun:     dryRun,
+			claimed:    make(map[string]bool),
+		}
+		if quotaChecker != nil {
+			imp.limits = quotaChecker.Limits(actor)
+		}
+
+		response := ImportResponse{
+			DryRun:     dryRun,
+			OnConflict: onConflict,
+			Summary:    make(map[string]int),
+			Items:      []ItemResult{},
+		}
+
+		for index := 0; ; index++ {
+			item, err := next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				// Уже обработанные строки остаются записанными, клиент видит их в items
+				log.Info("failed to read import file", slog.Int("index", index), sl.Err(err))
+				response.Error = fmt.Sprintf("item %d: %s", index, err.Error())
+				render.Status(r, http.StatusBadRequest)
+				break
+			}
+
+			result := imp.importItem(r.Context(), item)
+			result.Index = index
+			result.Domain = strings.ToLower(item.Domain)
+
+			response.Items = append(response.Items, result)
+			response.Summary[result.Status]++
+		}
+
+		log.Info("links imported", slog.Bool("dryRun", dryRun), slog.Any("summary", response.Summary))
+
+		render.JSON(w, r, response)
+	}
+}
+
+type importer struct {
+	log        *slog.Logger
+	storage    LinkImporter
+	allocator  AliasAllocator
+	screener   save.URLScreener
+	quota      save.QuotaChecker
+	limits     quota.Limits
+	actor      models.Actor
+	onConflict string
+	dryRun     bool
+	// claimed holds domains and aliases taken by earlier items of a dry run, which are not in the database
+	claimed map[string]bool
+}
+
+func (imp *importer) importItem(ctx context.Context, item Item) ItemResult {
+	req := save.Request{
+		URL:          item.URL,
+		Alias:        item.Alias,
+		Domain:       item.Domain,
+		RedirectCode: item.RedirectCode,
+		ExpiresAt:    item.ExpiresAt,
+		MaxClicks:    item.MaxClicks,
+		Title:        item.Title,
+		Description:  item.Description,
+		Notes:        item.Notes,
+	}
+
+	if err := save.Validate(req); err != nil {
+		return ItemResult{Status: StatusFailed, Alias: item.Alias, Error: err.Error()}
+	}
+
+	if imp.screener != nil {
+		if err := imp.screener.Screen(ctx, req.URL); err != nil {
+			if errors.Is(err, screening.ErrRejected) {
+				return ItemResult{Status: StatusFailed, Alias: item.Alias, Error: err.Error()}
+			}
+			imp.log.Error("failed to screen destination", sl.Err(err))
+			return ItemResult{Status: StatusFailed, Alias: item.Alias, Error: "internal error"}
+		}
+	}
+
+	note, err := save.NewNote(req)
+	if err != nil {
+		imp.log.Error("failed to prepare link", sl.Err(err))
+		return ItemResult{Status: StatusFailed, Alias: item.Alias, Error: "internal error"}
+	}
+	note.ClicksUsed = item.ClicksUsed
+	if item.CreatedAt != nil {
+		note.CreatedAt = *item.CreatedAt
+	}
+
+	// Ссылки без алиаса не конфликтуют, алиас для них генерируется
+	if note.Alias == "" {
+		return imp.createGenerated(ctx, note)
+	}
+
+	owner, exists, err := imp.aliasOwner(ctx, note.Domain, note.Alias)
+	if err != nil {
+		imp.log.Error("failed to check alias", sl.Err(err))
+		return ItemResult{Status: StatusFailed, Alias: note.Alias, Error: "internal error"}
+	}
+
+	if !exists {
+		return imp.create(ctx, note, StatusCreated)
+	}
+
+	switch imp.onConflict {
+	case ConflictOverwrite:
+		if owner != imp.actor.UserID {
+			return ItemResult{Status: StatusFailed, Alias: note.Alias, Error: storage.ErrAliasNotOwned.Error()}
+		}
+
+		result := ItemResult{Status: StatusOverwritten, Alias: note.Alias}
+		if imp.dryRun {
+			return result
+		}
+
+		result.ID, err = imp.storage.OverwriteLink(ctx, note, imp.actor.UserID)
+		if err != nil {
+			return imp.failed(note.Alias, err)
+		}
+		return result
+	case ConflictRename:
+		original := note.Alias
+
+		alias, err := imp.freeAlias(ctx, note.Domain, original)
+		if err != nil {
+			return imp.failed(original, err)
+		}
+
+		note.Alias = alias
+		result := imp.create(ctx, note, StatusRenamed)
+		result.OriginalAlias = original
+		return result
+	default:
+		return ItemResult{Status: StatusSkipped, Alias: note.Alias}
+	}
+}
+
+func (imp *importer) create(ctx context.Context, note models.AliasNote, status string) ItemResult {
+	result := ItemResult{Status: status, Alias: note.Alias}
+
+	if imp.dryRun {
+		imp.claimed[note.Domain+"/"+note.Alias] = true
+		return result
+	}
+
+	if err := imp.checkQuota(ctx); err != nil {
+		return imp.failed(note.Alias, err)
+	}
+
+	id, err := imp.storage.SaveURL(ctx, note, imp.actor.UserID, imp.limits)
+	if err != nil {
+		return imp.failed(note.Alias, err)
+	}
+
+	result.ID = id
+	return result
+}
+
+func (imp *importer) createGenerated(ctx context.Context, note models.AliasNote) ItemResult {
+	if imp.dryRun {
+		alias, err := imp.allocator.Generate(ctx)
+		if err != nil {
+			return imp.failed("", err)
+		}
+		return ItemResult{Status: StatusCreated, Alias: alias}
+	}
+
+	if err := imp.checkQuota(ctx); err != nil {
+		return imp.failed("", err)
+	}
+
+	var id int64
+	alias, err := imp.allocator.Allocate(ctx, func(alias string) error {
+		generated := note
+		generated.Alias = alias
+
+		var err error
+		id, err = imp.storage.SaveURL(ctx, generated, imp.actor.UserID, imp.limits)
+		return err
+	})
+	if err != nil {
+		return imp.failed("", err)
+	}
+
+	return ItemResult{Status: StatusCreated, ID: id, Alias: alias}
+}
+
+// checkQuota returns an error if one more link would exceed the user's quota.
+func (imp *importer) checkQuota(ctx context.Context) error {
+	if imp.quota == nil {
+		return nil
+	}
+	return imp.quota.Check(ctx, imp.actor, 1)
+}
+
+// aliasOwner reports who owns the alias on the domain, counting aliases
+// claimed earlier in a dry run.
+func (imp *importer) aliasOwner(ctx context.Context, domain, alias string) (int64, bool, error) {
+	if imp.claimed[domain+"/"+alias] {
+		return imp.actor.UserID, true, nil
+	}
+
+	owner, err := imp.storage.GetAliasOwner(ctx, domain, alias)
+	switch {
+	case errors.Is(err, storage.ErrAliasNotFound):
+		return 0, false, nil
+	case err != nil:
+		return 0, false, err
+	}
+
+	return owner, true, nil
+}
+
+// freeAlias finds the first unused alias of the form alias-2, alias-3, ... on the domain.
+func (imp *importer) freeAlias(ctx context.Context, domain, alias string) (string, error) {
+	for n := 2; n < maxRenameAttempts+2; n++ {
+		candidate := alias + "-" + strconv.Itoa(n)
+
+		_, exists, err := imp.aliasOwner(ctx, domain, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+
+	return "", storage.ErrAliasExist
+}
+
+func (imp *importer) failed(alias string, err error) ItemResult {
+	switch {
+	case errors.Is(err, storage.ErrAliasExist):
+		return ItemResult{Status: StatusFailed, Alias: alias, Error: storage.ErrAliasExist.Error()}
+	case errors.Is(err, storage.ErrAliasNotOwned):
+		return ItemResult{Status: StatusFailed, Alias: alias, Error: storage.ErrAliasNotOwned.Error()}
+	case errors.Is(err, storage.ErrDomainNotFound), errors.Is(err, storage.ErrDomainNotVerified):
+		return ItemResult{Status: StatusFailed, Alias: alias, Error: save.DomainError(err)}
+	case errors.Is(err, aliaslib.ErrNoFreeAlias):
+		return ItemResult{Status: StatusFailed, Alias: alias, Error: aliaslib.ErrNoFreeAlias.Error()}
+	case errors.Is(err, quota.ErrExceeded):
+		return ItemResult{Status: StatusFailed, Alias: alias, Error: err.Error()}
+	default:
+		imp.log.Error("failed to import link", slog.String("alias", alias), sl.Err(err))
+		return ItemResult{Status: StatusFailed, Alias: alias, Error: "internal error"}
+	}
+}
+
+// newItemReader returns a function reading import items one at a time until io.EOF.
+func newItemReader(r *http.Request, body io.Reader) (func() (Item, error), error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			format = FormatCSV
+		case "application/x-ndjson", "application/json":
+			format = FormatNDJSON
+		}
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVReader(body)
+	case FormatNDJSON:
+		dec := json.NewDecoder(body)
+		return func() (Item, error) {
+			var item Item
+			if err := dec.Decode(&item); err != nil {
+				if errors.Is(err, io.EOF) {
+					return Item{}, io.EOF
+				}
+				return Item{}, errors.New("invalid json")
+			}
+			return item, nil
+		}, nil
+	default:
+		return nil, errors.New("format must be csv or ndjson")
+	}
+}
+
+// newCSVReader reads CSV with a header row. Columns of the export that
+// cannot be imported, such as clicks, are ignored.
+func newCSVReader(body io.Reader) (func() (Item, error), error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("failed to read csv header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("csv header must contain url")
+	}
+
+	return func() (Item, error) {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return Item{}, io.EOF
+		}
+		if err != nil {
+			return Item{}, errors.New("invalid csv")
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		item := Item{
+			URL:         field("url"),
+			Alias:       field("alias"),
+			Domain:      field("domain"),
+			Title:       field("title"),
+			Description: field("description"),
+			Notes:       field("notes"),
+		}
+
+		if v := field("redirectcode"); v != "" {
+			if item.RedirectCode, err = strconv.Atoi(v); err != nil {
+				return Item{}, errors.New("redirectCode must be a number")
+			}
+		}
+		if item.ExpiresAt, err = parseTime(field("expiresat")); err != nil {
+			return Item{}, errors.New("expiresAt must be RFC3339 time")
+		}
+		if item.CreatedAt, err = parseTime(field("createdat")); err != nil {
+			return Item{}, errors.New("createdAt must be RFC3339 time")
+		}
+		if v := field("maxclicks"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return Item{}, errors.New("maxClicks must be a number")
+			}
+			item.MaxClicks = &n
+		}
+		if v := field("clicksused"); v != "" {
+			if item.ClicksUsed, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return Item{}, errors.New("clicksUsed must be a number")
+			}
+		}
+
+		return item, nil
+	}, nil
+}
+
+func parseTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
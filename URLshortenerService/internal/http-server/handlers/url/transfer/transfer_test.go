@@ -0,0 +1,358 @@
+package transfer_test
+
+import (
+	"URLshortener/internal/domain/models"
+	savemocks "URLshortener/internal/http-server/handlers/url/save/mocks"
+	"URLshortener/internal/http-server/handlers/url/transfer"
+	"URLshortener/internal/http-server/handlers/url/transfer/mocks"
+	"URLshortener/internal/lib/alias"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/lib/random"
+	"URLshortener/internal/lib/screening"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withClaims(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+}
+
+func newAllocator(t *testing.T) *alias.Allocator {
+	generator, err := alias.NewRandom(6, random.Base62)
+	require.NoError(t, err)
+	return alias.NewAllocator(generator, 3)
+}
+
+func TestExportHandler(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	items := []models.LinkItem{
+		{AliasNote: models.AliasNote{ID: 1, Url: "https://google.com", Alias: "google", Title: "Google", Notes: "search, mostly", RedirectCode: 302, CreatedAt: created}, Clicks: 5},
+		{AliasNote: models.AliasNote{ID: 2, Url: "https://ya.ru", Alias: "ya", Domain: "go.example.com", RedirectCode: 301, Protected: true, CreatedAt: created}},
+	}
+
+	exportAll := func(_ context.Context, _ int64, fn func(models.LinkItem) error) error {
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		exporterMock := mocks.NewLinkExporter(t)
+		exporterMock.On("ExportUserLinks", mock.Anything, int64(1), mock.Anything).Return(exportAll).Once()
+
+		rr := httptest.NewRecorder()
+		transfer.NewExport(slogdiscard.NewDiscardLogger(), exporterMock).
+			ServeHTTP(rr, withClaims(httptest.NewRequest(http.MethodGet, "/export", nil)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		require.Equal(t,
+			"url,alias,redirectCode,expiresAt,maxClicks,clicksUsed,protected,createdAt,clicks,domain,title,description,notes\n"+
+				"https://google.com,google,302,,,0,false,2025-01-01T12:00:00Z,5,,Google,,\"search, mostly\"\n"+
+				"https://ya.ru,ya,301,,,0,true,2025-01-01T12:00:00Z,0,go.example.com,,,\n",
+			rr.Body.String())
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		exporterMock := mocks.NewLinkExporter(t)
+		exporterMock.On("ExportUserLinks", mock.Anything, int64(1), mock.Anything).Return(exportAll).Once()
+
+		rr := httptest.NewRecorder()
+		transfer.NewExport(slogdiscard.NewDiscardLogger(), exporterMock).
+			ServeHTTP(rr, withClaims(httptest.NewRequest(http.MethodGet, "/export?format=ndjson", nil)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		require.Len(t, lines, 2)
+
+		var item models.LinkItem
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &item))
+		require.Equal(t, "google", item.Alias)
+		require.Equal(t, int64(5), item.Clicks)
+	})
+
+	t.Run("Storage error", func(t *testing.T) {
+		exporterMock := mocks.NewLinkExporter(t)
+		exporterMock.On("ExportUserLinks", mock.Anything, int64(1), mock.Anything).Return(errors.New("unexpected error")).Once()
+
+		rr := httptest.NewRecorder()
+		transfer.NewExport(slogdiscard.NewDiscardLogger(), exporterMock).
+			ServeHTTP(rr, withClaims(httptest.NewRequest(http.MethodGet, "/export", nil)))
+
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		transfer.NewExport(slogdiscard.NewDiscardLogger(), mocks.NewLinkExporter(t)).
+			ServeHTTP(rr, withClaims(httptest.NewRequest(http.MethodGet, "/export?format=xml", nil)))
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestImportHandler(t *testing.T) {
+	const csvBody = "url,alias,redirectCode,clicks\n" +
+		"https://google.com,google,301,10\n" +
+		"https://ya.ru,ya,,\n" +
+		"not a url,bad,,\n"
+
+	alias := func(a string) any {
+		return mock.MatchedBy(func(n models.AliasNote) bool { return n.Alias == a })
+	}
+
+	testCases := []struct {
+		name       string
+		query      string
+		setup      func(m *mocks.LinkImporter)
+		respStatus int
+		statuses   []string
+		aliases    []string
+	}{
+		{
+			name:  "Skip",
+			query: "",
+			setup: func(m *mocks.LinkImporter) {
+				m.On("GetAliasOwner", mock.Anything, "", "google").Return(int64(1), nil).Once()
+				m.On("GetAliasOwner", mock.Anything, "", "ya").Return(int64(0), storage.ErrAliasNotFound).Once()
+				m.On("SaveURL", mock.Anything, alias("ya"), int64(1), quota.Limits{}).Return(int64(7), nil).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{transfer.StatusSkipped, transfer.StatusCreated, transfer.StatusFailed},
+			aliases:    []string{"google", "ya", "bad"},
+		},
+		{
+			name:  "Overwrite",
+			query: "?onConflict=overwrite",
+			setup: func(m *mocks.LinkImporter) {
+				m.On("GetAliasOwner", mock.Anything, "", "google").Return(int64(1), nil).Once()
+				m.On("OverwriteLink", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+					return n.Alias == "google" && n.RedirectCode == http.StatusMovedPermanently
+				}), int64(1)).Return(int64(3), nil).Once()
+				m.On("GetAliasOwner", mock.Anything, "", "ya").Return(int64(2), nil).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{transfer.StatusOverwritten, transfer.StatusFailed, transfer.StatusFailed},
+			aliases:    []string{"google", "ya", "bad"},
+		},
+		{
+			name:  "Rename",
+			query: "?onConflict=rename",
+			setup: func(m *mocks.LinkImporter) {
+				m.On("GetAliasOwner", mock.Anything, "", "google").Return(int64(2), nil).Once()
+				m.On("GetAliasOwner", mock.Anything, "", "google-2").Return(int64(1), nil).Once()
+				m.On("GetAliasOwner", mock.Anything, "", "google-3").Return(int64(0), storage.ErrAliasNotFound).Once()
+				m.On("SaveURL", mock.Anything, alias("google-3"), int64(1), quota.Limits{}).Return(int64(8), nil).Once()
+				m.On("GetAliasOwner", mock.Anything, "", "ya").Return(int64(0), storage.ErrAliasNotFound).Once()
+				m.On("SaveURL", mock.Anything, alias("ya"), int64(1), quota.Limits{}).Return(int64(0), storage.ErrAliasExist).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{transfer.StatusRenamed, transfer.StatusFailed, transfer.StatusFailed},
+			aliases:    []string{"google-3", "ya", "bad"},
+		},
+		{
+			name:  "Dry run writes nothing",
+			query: "?dryRun=true&onConflict=rename",
+			setup: func(m *mocks.LinkImporter) {
+				m.On("GetAliasOwner", mock.Anything, "", "google").Return(int64(2), nil).Once()
+				m.On("GetAliasOwner", mock.Anything, "", "google-2").Return(int64(0), storage.ErrAliasNotFound).Once()
+				m.On("GetAliasOwner", mock.Anything, "", "ya").Return(int64(0), storage.ErrAliasNotFound).Once()
+			},
+			respStatus: http.StatusOK,
+			statuses:   []string{transfer.StatusRenamed, transfer.StatusCreated, transfer.StatusFailed},
+			aliases:    []string{"google-2", "ya", "bad"},
+		},
+		{
+			name:       "Invalid policy",
+			query:      "?onConflict=merge",
+			respStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			importerMock := mocks.NewLinkImporter(t)
+			if tc.setup != nil {
+				tc.setup(importerMock)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/import"+tc.query, strings.NewReader(csvBody))
+			req.Header.Set("Content-Type", "text/csv")
+
+			rr := httptest.NewRecorder()
+			transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, newAllocator(t), nil, nil).ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+			if tc.statuses == nil {
+				return
+			}
+
+			var resp transfer.ImportResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			var statuses, aliases []string
+			for _, item := range resp.Items {
+				statuses = append(statuses, item.Status)
+				aliases = append(aliases, item.Alias)
+			}
+			require.Equal(t, tc.statuses, statuses)
+			require.Equal(t, tc.aliases, aliases)
+		})
+	}
+}
+
+func TestImportHandler_NDJSON(t *testing.T) {
+	body := `{"url": "https://google.com", "alias": "google", "clicksUsed": 3, "createdAt": "2024-05-01T10:00:00Z"}
+{"url": "https://ya.ru", "alias": "ya"
+`
+
+	importerMock := mocks.NewLinkImporter(t)
+	importerMock.On("GetAliasOwner", mock.Anything, "", "google").Return(int64(0), storage.ErrAliasNotFound).Once()
+	importerMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+		return n.ClicksUsed == 3 && n.CreatedAt.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	}), int64(1), quota.Limits{}).Return(int64(1), nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	rr := httptest.NewRecorder()
+	transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, newAllocator(t), nil, nil).ServeHTTP(rr, withClaims(req))
+
+	// Битая строка останавливает импорт, но уже записанные ссылки видны в ответе
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var resp transfer.ImportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "item 1: invalid json", resp.Error)
+	require.Len(t, resp.Items, 1)
+	require.Equal(t, transfer.StatusCreated, resp.Items[0].Status)
+}
+
+func TestImportHandler_GeneratedAlias(t *testing.T) {
+	importerMock := mocks.NewLinkImporter(t)
+	importerMock.On("SaveURL", mock.Anything, mock.Anything, int64(1), quota.Limits{}).Return(int64(0), storage.ErrAliasExist).Once()
+	importerMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+		return len(n.Alias) == 6
+	}), int64(1), quota.Limits{}).Return(int64(7), nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(`{"url": "https://google.com"}`))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	rr := httptest.NewRecorder()
+	transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, newAllocator(t), nil, nil).ServeHTTP(rr, withClaims(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp transfer.ImportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 1)
+	require.Equal(t, transfer.StatusCreated, resp.Items[0].Status)
+	require.Equal(t, int64(7), resp.Items[0].ID)
+	require.Len(t, resp.Items[0].Alias, 6)
+}
+
+func TestImportHandler_CSVMetadata(t *testing.T) {
+	body := "url,alias,domain,title,notes\n" +
+		"https://google.com,google,go.example.com,Google,\"search, mostly\"\n"
+
+	importerMock := mocks.NewLinkImporter(t)
+	importerMock.On("GetAliasOwner", mock.Anything, "go.example.com", "google").Return(int64(0), storage.ErrAliasNotFound).Once()
+	importerMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+		return n.Domain == "go.example.com" && n.Title == "Google" && n.Notes == "search, mostly"
+	}), int64(1), quota.Limits{}).Return(int64(1), nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+
+	rr := httptest.NewRecorder()
+	transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, newAllocator(t), nil, nil).ServeHTTP(rr, withClaims(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp transfer.ImportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 1)
+	require.Equal(t, transfer.StatusCreated, resp.Items[0].Status)
+}
+
+func TestImportHandler_RejectedDestination(t *testing.T) {
+	body := `{"url": "javascript:alert(1)", "alias": "xss"}` + "\n" +
+		`{"url": "https://google.com", "alias": "google"}` + "\n" +
+		`{"url": "https://ya.ru", "alias": "trash"}`
+
+	importerMock := mocks.NewLinkImporter(t)
+	importerMock.On("GetAliasOwner", mock.Anything, "", "google").Return(int64(0), storage.ErrAliasNotFound).Once()
+	importerMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+		return n.Alias == "google"
+	}), int64(1), quota.Limits{}).Return(int64(1), nil).Once()
+
+	screener := screening.New(screening.Schemes("http", "https"))
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	rr := httptest.NewRecorder()
+	transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, newAllocator(t), screener, nil).ServeHTTP(rr, withClaims(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp transfer.ImportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 3)
+	require.Equal(t, transfer.StatusFailed, resp.Items[0].Status)
+	require.Equal(t, screening.ErrScheme.Error(), resp.Items[0].Error)
+	require.Equal(t, transfer.StatusCreated, resp.Items[1].Status)
+	require.Equal(t, transfer.StatusFailed, resp.Items[2].Status)
+	require.Equal(t, alias.ErrReservedAlias.Error(), resp.Items[2].Error)
+}
+
+func TestImportHandler_Quota(t *testing.T) {
+	body := `{"url": "https://google.com", "alias": "google"}` + "\n" +
+		`{"url": "https://ya.ru", "alias": "ya"}`
+
+	importerMock := mocks.NewLinkImporter(t)
+	importerMock.On("GetAliasOwner", mock.Anything, "", mock.Anything).Return(int64(0), storage.ErrAliasNotFound).Twice()
+	importerMock.On("SaveURL", mock.Anything, mock.MatchedBy(func(n models.AliasNote) bool {
+		return n.Alias == "google"
+	}), int64(1), quota.Limits{MaxLinks: 1}).Return(int64(1), nil).Once()
+
+	quotaMock := savemocks.NewQuotaChecker(t)
+	quotaMock.On("Limits", models.Actor{UserID: 1}).Return(quota.Limits{MaxLinks: 1}).Once()
+	quotaMock.On("Check", mock.Anything, models.Actor{UserID: 1}, 1).Return(nil).Once()
+	quotaMock.On("Check", mock.Anything, models.Actor{UserID: 1}, 1).Return(fmt.Errorf("%w: at most 1 links", quota.ErrExceeded)).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	rr := httptest.NewRecorder()
+	transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, newAllocator(t), nil, quotaMock).ServeHTTP(rr, withClaims(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp transfer.ImportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 2)
+	require.Equal(t, transfer.StatusCreated, resp.Items[0].Status)
+	require.Equal(t, transfer.StatusFailed, resp.Items[1].Status)
+	require.Equal(t, "link quota exceeded: at most 1 links", resp.Items[1].Error)
+}
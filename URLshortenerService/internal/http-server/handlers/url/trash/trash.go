@@ -0,0 +1,194 @@
+package trash
+
+import (
+	"URLshortener/internal/domain/models"
+	jwtlib "URLshortener/internal/jwt"
+	resp "URLshortener/internal/lib/api/response"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Item is a link in the trash with the time it will be purged at.
+type Item struct {
+	models.AliasNote
+	ShortURL string     `json:"shortUrl,omitempty"`
+	PurgeAt  *time.Time `json:"purgeAt,omitempty"`
+}
+
+type ListResponse struct {
+	Items []Item `json:"items"`
+}
+
+//go:generate mockery --name=TrashLister --output=./mocks
+type TrashLister interface {
+	ListTrash(ctx context.Context, userID int64) ([]models.AliasNote, error)
+}
+
+//go:generate mockery --name=LinkRestorer --output=./mocks
+type LinkRestorer interface {
+	RestoreLink(ctx context.Context, id int64, userID int64) (models.AliasNote, error)
+}
+
+//go:generate mockery --name=LinkPurger --output=./mocks
+type LinkPurger interface {
+	PurgeLink(ctx context.Context, id int64, userID int64) error
+}
+
+// NewList returns the user's deleted links that can still be restored.
+func NewList(log *slog.Logger, trashLister TrashLister, publicURL string, retention time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.trash.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		notes, err := trashLister.ListTrash(r.Context(), userID)
+		if err != nil {
+			log.Error("failed to list trash", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		items := make([]Item, 0, len(notes))
+		for _, note := range notes {
+			item := Item{AliasNote: note, ShortURL: models.ShortURL(publicURL, note.Domain, note.Alias)}
+			if note.DeletedAt != nil {
+				purgeAt := note.DeletedAt.Add(retention)
+				item.PurgeAt = &purgeAt
+			}
+			items = append(items, item)
+		}
+
+		render.JSON(w, r, ListResponse{Items: items})
+	}
+}
+
+// NewRestore takes the user's link /trash/{id} out of the trash.
+func NewRestore(log *slog.Logger, linkRestorer LinkRestorer, publicURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.trash.NewRestore"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := linkID(w, r, log)
+		if !ok {
+			return
+		}
+
+		note, err := linkRestorer.RestoreLink(r.Context(), id, userID)
+		switch {
+		case errors.Is(err, storage.ErrAliasNotFound):
+			log.Info("link not found in trash", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("link not found in trash"))
+			return
+		case err != nil:
+			log.Error("failed to restore link", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to restore link"))
+			return
+		}
+
+		log.Info("link restored", slog.Int64("id", id))
+
+		render.JSON(w, r, Item{AliasNote: note, ShortURL: models.ShortURL(publicURL, note.Domain, note.Alias)})
+	}
+}
+
+// NewPurge permanently removes the user's link /trash/{id} from the trash.
+func NewPurge(log *slog.Logger, linkPurger LinkPurger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.trash.NewPurge"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := userIDFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := linkID(w, r, log)
+		if !ok {
+			return
+		}
+
+		err := linkPurger.PurgeLink(r.Context(), id, userID)
+		switch {
+		case errors.Is(err, storage.ErrAliasNotFound):
+			log.Info("link not found in trash", slog.Int64("id", id))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("link not found in trash"))
+			return
+		case err != nil:
+			log.Error("failed to purge link", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to purge link"))
+			return
+		}
+
+		log.Info("link purged", slog.Int64("id", id))
+
+		render.NoContent(w, r)
+	}
+}
+
+func linkID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		log.Info("invalid link id", slog.String("id", chi.URLParam(r, "id")))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("invalid link id"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func userIDFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	// Берем из контекста данные JWT токена
+	claims, err := jwtlib.GetClaimsFromContext(r.Context())
+	if err != nil {
+		log.Error("failed to get claims from context")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("failed to get claims"))
+		return 0, false
+	}
+
+	userIDAny, ok := claims["uid"]
+	if !ok {
+		log.Error("failed to get field uid from claims")
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, resp.Error("internal error"))
+		return 0, false
+	}
+
+	return int64(userIDAny.(float64)), true
+}
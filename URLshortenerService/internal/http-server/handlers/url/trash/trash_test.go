@@ -0,0 +1,192 @@
+package trash_test
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/http-server/handlers/url/trash"
+	"URLshortener/internal/http-server/handlers/url/trash/mocks"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func withClaims(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1)}))
+}
+
+func TestListHandler(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	listerMock := mocks.NewTrashLister(t)
+	listerMock.On("ListTrash", mock.Anything, int64(1)).
+		Return([]models.AliasNote{{ID: 5, Url: "https://a.com", Alias: "a", DeletedAt: &deletedAt}}, nil).
+		Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/trash", nil)
+	rr := httptest.NewRecorder()
+	trash.NewList(slogdiscard.NewDiscardLogger(), listerMock, "https://sho.rt", 24*time.Hour).ServeHTTP(rr, withClaims(req))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp trash.ListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 1)
+	require.Equal(t, "https://sho.rt/a", resp.Items[0].ShortURL)
+	require.Equal(t, deletedAt.Add(24*time.Hour), *resp.Items[0].PurgeAt)
+}
+
+func TestRestoreHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		path       string
+		callMock   bool
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Success",
+			path:       "/trash/5/restore",
+			callMock:   true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "Not in trash",
+			path:       "/trash/5/restore",
+			callMock:   true,
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "link not found in trash",
+		},
+		{
+			name:       "Invalid id",
+			path:       "/trash/abc/restore",
+			respStatus: http.StatusBadRequest,
+			respError:  "invalid link id",
+		},
+		{
+			name:       "Storage error",
+			path:       "/trash/5/restore",
+			callMock:   true,
+			mockError:  errors.New("unexpected error"),
+			respStatus: http.StatusInternalServerError,
+			respError:  "failed to restore link",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			restorerMock := mocks.NewLinkRestorer(t)
+			if tc.callMock {
+				restorerMock.On("RestoreLink", mock.Anything, int64(5), int64(1)).
+					Return(models.AliasNote{ID: 5, Url: "https://a.com", Alias: "a"}, tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Post("/trash/{id}/restore", trash.NewRestore(slogdiscard.NewDiscardLogger(), restorerMock, "https://sho.rt"))
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp struct {
+				Error    string `json:"message"`
+				ShortURL string `json:"shortUrl"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+
+			if tc.respError == "" {
+				require.Equal(t, "https://sho.rt/a", resp.ShortURL)
+			}
+		})
+	}
+}
+
+func TestPurgeHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		path       string
+		callMock   bool
+		mockError  error
+		respStatus int
+		respError  string
+	}{
+		{
+			name:       "Success",
+			path:       "/trash/5",
+			callMock:   true,
+			respStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Not in trash",
+			path:       "/trash/5",
+			callMock:   true,
+			mockError:  storage.ErrAliasNotFound,
+			respStatus: http.StatusNotFound,
+			respError:  "link not found in trash",
+		},
+		{
+			name:       "Invalid id",
+			path:       "/trash/0",
+			respStatus: http.StatusBadRequest,
+			respError:  "invalid link id",
+		},
+		{
+			name:       "Storage error",
+			path:       "/trash/5",
+			callMock:   true,
+			mockError:  errors.New("unexpected error"),
+			respStatus: http.StatusInternalServerError,
+			respError:  "failed to purge link",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			purgerMock := mocks.NewLinkPurger(t)
+			if tc.callMock {
+				purgerMock.On("PurgeLink", mock.Anything, int64(5), int64(1)).
+					Return(tc.mockError).
+					Once()
+			}
+
+			r := chi.NewRouter()
+			r.Delete("/trash/{id}", trash.NewPurge(slogdiscard.NewDiscardLogger(), purgerMock))
+
+			req := httptest.NewRequest(http.MethodDelete, tc.path, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withClaims(req))
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			if tc.respError != "" {
+				var resp struct {
+					Error string `json:"message"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				require.Equal(t, tc.respError, resp.Error)
+			}
+		})
+	}
+}
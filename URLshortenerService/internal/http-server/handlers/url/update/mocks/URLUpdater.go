@@ -2,24 +2,84 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "URLshortener/internal/domain/models"
+)
 
 // URLUpdater is an autogenerated mock type for the URLUpdater type
 type URLUpdater struct {
 	mock.Mock
 }
 
-// UpdateURL provides a mock function with given fields: _a0, _a1
-func (_m *URLUpdater) UpdateURL(_a0 string, _a1 string) error {
-	ret := _m.Called(_a0, _a1)
+// SetLinkPassword provides a mock function with given fields: ctx, id, alias, hash, actor
+func (_m *URLUpdater) SetLinkPassword(ctx context.Context, id int64, alias string, hash []byte, actor models.Actor) error {
+	ret := _m.Called(ctx, id, alias, hash, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLinkPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, []byte, models.Actor) error); ok {
+		r0 = rf(ctx, id, alias, hash, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlias provides a mock function with given fields: ctx, id, newUrl, actor
+func (_m *URLUpdater) UpdateAlias(ctx context.Context, id int64, newUrl string, actor models.Actor) error {
+	ret := _m.Called(ctx, id, newUrl, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAlias")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, models.Actor) error); ok {
+		r0 = rf(ctx, id, newUrl, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAliasURL provides a mock function with given fields: ctx, newURL, alias, actor
+func (_m *URLUpdater) UpdateAliasURL(ctx context.Context, newURL string, alias string, actor models.Actor) error {
+	ret := _m.Called(ctx, newURL, alias, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAliasURL")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, models.Actor) error); ok {
+		r0 = rf(ctx, newURL, alias, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLinkMetadata provides a mock function with given fields: ctx, id, alias, meta, actor
+func (_m *URLUpdater) UpdateLinkMetadata(ctx context.Context, id int64, alias string, meta models.LinkMetadata, actor models.Actor) error {
+	ret := _m.Called(ctx, id, alias, meta, actor)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateURL")
+		panic("no return value specified for UpdateLinkMetadata")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, models.LinkMetadata, models.Actor) error); ok {
+		r0 = rf(ctx, id, alias, meta, actor)
 	} else {
 		r0 = ret.Error(0)
 	}
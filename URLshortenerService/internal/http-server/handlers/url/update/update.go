@@ -1,36 +1,63 @@
 package update
 
 import (
+	"URLshortener/internal/domain/models"
 	jwtlib "URLshortener/internal/jwt"
 	resp "URLshortener/internal/lib/api/response"
 	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/lib/screening"
 	"URLshortener/internal/storage"
+	"context"
 	"errors"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
 	"log/slog"
 	"net/http"
+	"strings"
 )
 
+// Ссылку можно указать либо по id, либо по алиасу
+// Пустой password снимает пароль со ссылки, отсутствующий оставляет как есть
+// Так же и с title, description и notes: пустая строка очищает поле
 type request struct {
-	ID     int64  `json:"urlId"`
-	NewUrl string `json:"newUrl" validate:"required,url"`
+	ID          int64   `json:"urlId" validate:"required_without=Alias"`
+	Alias       string  `json:"alias" validate:"required_without=ID"`
+	NewUrl      string  `json:"newUrl" validate:"required_without_all=Password Title Description Notes,omitempty,url"`
+	Password    *string `json:"password,omitempty" validate:"omitempty,max=72"`
+	Title       *string `json:"title,omitempty" validate:"omitempty,max=200"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Notes       *string `json:"notes,omitempty" validate:"omitempty,max=5000"`
 }
 
 type Response struct {
-	ID    int64  `json:"id,omitempty"`
-	Url   string `json:"url,omitempty"`
-	Error string `json:"message,omitempty"`
+	ID          int64   `json:"id,omitempty"`
+	Alias       string  `json:"alias,omitempty"`
+	Url         string  `json:"url,omitempty"`
+	Protected   *bool   `json:"protected,omitempty"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+	Error       string  `json:"message,omitempty"`
 }
 
 //go:generate mockery --name=URLUpdater --output=./mocks
-type Updater interface {
-	//UpdateAlias(oldAlias string, newAlias string, userID int64) error
-	UpdateAlias(id int64, newUrl string, userID int64) error
+type URLUpdater interface {
+	UpdateAlias(ctx context.Context, id int64, newUrl string, actor models.Actor) error
+	UpdateAliasURL(ctx context.Context, newURL string, alias string, actor models.Actor) error
+	SetLinkPassword(ctx context.Context, id int64, alias string, hash []byte, actor models.Actor) error
+	UpdateLinkMetadata(ctx context.Context, id int64, alias string, meta models.LinkMetadata, actor models.Actor) error
 }
 
-func New(log *slog.Logger, Updater Updater) http.HandlerFunc {
+//go:generate mockery --name=URLScreener --output=./mocks
+type URLScreener interface {
+	Screen(ctx context.Context, rawURL string) error
+}
+
+// New changes the destination, password and metadata of the user's link.
+// If urlScreener is not nil, a new destination must pass it.
+func New(log *slog.Logger, updater URLUpdater, urlScreener URLScreener) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.update.New"
 
@@ -45,24 +72,15 @@ func New(log *slog.Logger, Updater Updater) http.HandlerFunc {
 			return
 		}
 
-		// Берем из контекста данные JWT токена
-		claims, err := jwtlib.GetClaimsFromContext(r.Context())
+		// Берем из контекста данные JWT токена, автор изменения попадет в историю ссылки
+		actor, err := jwtlib.GetActorFromContext(r.Context())
 		if err != nil {
-			log.Error("failed to get claims from context")
+			log.Error("failed to get actor from claims", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, Response{Error: "failed to get claims"})
 			return
 		}
 
-		userIDAny, ok := claims["uid"]
-		if !ok {
-			log.Error("failed to get field uid from claims")
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, Response{Error: "internal error"})
-			return
-		}
-		userID := int64(userIDAny.(float64))
-
 		var req request
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
 			log.Error("failed to decode request body", sl.Err(err))
@@ -71,7 +89,13 @@ func New(log *slog.Logger, Updater Updater) http.HandlerFunc {
 			render.JSON(w, r, Response{Error: "failed to decode request"})
 			return
 		}
-		log.Info("request body decoded", slog.Any("request", req))
+		log.Info("request body decoded", slog.Int64("urlId", req.ID), slog.String("alias", req.Alias))
+
+		for _, field := range []*string{req.Title, req.Description} {
+			if field != nil {
+				*field = strings.TrimSpace(*field)
+			}
+		}
 
 		if err := validator.New().Struct(req); err != nil {
 			validateErr := err.(validator.ValidationErrors)
@@ -82,28 +106,98 @@ func New(log *slog.Logger, Updater Updater) http.HandlerFunc {
 			return
 		}
 
+		if req.NewUrl != "" && urlScreener != nil {
+			if err := urlScreener.Screen(r.Context(), req.NewUrl); err != nil {
+				if errors.Is(err, screening.ErrRejected) {
+					log.Info("destination rejected", slog.String("url", req.NewUrl), sl.Err(err))
+
+					render.Status(r, http.StatusBadRequest)
+					render.JSON(w, r, Response{Error: err.Error()})
+					return
+				}
+
+				log.Error("failed to screen destination", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, Response{Error: "failed to check url"})
+				return
+			}
+		}
+
 		// Обновление данных в storage
-		err = Updater.UpdateAlias(req.ID, req.NewUrl, userID)
-		switch {
-		case errors.Is(err, storage.ErrAliasNotFound):
-			log.Info("alias not found", slog.Int64("url", req.ID))
+		if req.NewUrl != "" {
+			if req.ID != 0 {
+				err = updater.UpdateAlias(r.Context(), req.ID, req.NewUrl, actor)
+			} else {
+				err = updater.UpdateAliasURL(r.Context(), req.NewUrl, req.Alias, actor)
+			}
+			if err != nil {
+				renderStorageError(w, r, log, req, err)
+				return
+			}
+		}
 
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, Response{Error: "alias not found"})
-			return
-		case err != nil:
-			log.Error("failed to update alias", sl.Err(err))
+		var protected *bool
+		if req.Password != nil {
+			var hash []byte
+			if *req.Password != "" {
+				hash, err = bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+				if err != nil {
+					log.Error("failed to hash password", sl.Err(err))
+
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, Response{Error: "failed to update alias"})
+					return
+				}
+			}
+
+			if err := updater.SetLinkPassword(r.Context(), req.ID, req.Alias, hash, actor); err != nil {
+				renderStorageError(w, r, log, req, err)
+				return
+			}
+
+			isProtected := hash != nil
+			protected = &isProtected
+		}
 
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, Response{Error: "failed to update alias"})
-			return
+		if req.Title != nil || req.Description != nil || req.Notes != nil {
+			meta := models.LinkMetadata{Title: req.Title, Description: req.Description, Notes: req.Notes}
+			if err := updater.UpdateLinkMetadata(r.Context(), req.ID, req.Alias, meta, actor); err != nil {
+				renderStorageError(w, r, log, req, err)
+				return
+			}
 		}
 
 		log.Info("url updated")
 
 		render.JSON(w, r, Response{
-			ID:  req.ID,
-			Url: req.NewUrl,
+			ID:          req.ID,
+			Alias:       req.Alias,
+			Url:         req.NewUrl,
+			Protected:   protected,
+			Title:       req.Title,
+			Description: req.Description,
+			Notes:       req.Notes,
 		})
 	}
 }
+
+func renderStorageError(w http.ResponseWriter, r *http.Request, log *slog.Logger, req request, err error) {
+	switch {
+	case errors.Is(err, storage.ErrAliasNotFound):
+		log.Info("alias not found", slog.Int64("url", req.ID), slog.String("alias", req.Alias))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, Response{Error: "alias not found"})
+	case errors.Is(err, storage.ErrAliasNotOwned):
+		log.Info("alias belongs to another user", slog.String("alias", req.Alias))
+
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, Response{Error: "alias belongs to another user"})
+	default:
+		log.Error("failed to update alias", sl.Err(err))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, Response{Error: "failed to update alias"})
+	}
+}
@@ -1,13 +1,17 @@
 package update_test
 
 import (
+	"URLshortener/internal/domain/models"
 	"URLshortener/internal/http-server/handlers/url/update"
 	"URLshortener/internal/http-server/handlers/url/update/mocks"
 	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/lib/screening"
 	"URLshortener/internal/storage"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -15,94 +19,91 @@ import (
 	"testing"
 )
 
+var actor = models.Actor{UserID: 1, Role: "user"}
+
 func TestUpdateHandler(t *testing.T) {
 	testCases := []struct {
-		name       string
-		input      string
-		url        string
-		alias      string
-		respError  string
-		mockError  error
-		respStatus int
+		name        string
+		input       string
+		contentType string
+		id          int64
+		alias       string
+		url         string
+		respError   string
+		mockError   error
+		respStatus  int
 	}{
 		{
-			name:       "Success",
-			input:      `{"url": "https://google.com", "alias": "google"}`,
+			name:       "Success by id",
+			input:      `{"urlId": 1, "newUrl": "https://google.com"}`,
+			id:         1,
 			url:        "https://google.com",
-			alias:      "google",
 			respStatus: http.StatusOK,
 		},
 		{
-			name:       "Empty alias",
-			input:      `{"url": "https://google.com"}`,
+			name:       "Success by alias",
+			input:      `{"alias": "google", "newUrl": "https://google.com"}`,
+			alias:      "google",
 			url:        "https://google.com",
-			alias:      "",
 			respStatus: http.StatusOK,
 		},
 		{
 			name:       "Empty url",
-			input:      `{"alias": "some_alias"}`,
-			url:        "",
-			alias:      "some_alias",
-			respError:  "field URL is a required field",
+			input:      `{"urlId": 1}`,
+			respError:  "field NewUrl is a required field",
 			respStatus: http.StatusBadRequest,
 		},
 		{
 			name:       "Invalid URL",
-			input:      `{"url": "some invalid URL", "alias": "some_alias"}`,
-			url:        "some invalid URL",
-			alias:      "some_alias",
-			respError:  "field URL is not a valid URL",
+			input:      `{"urlId": 1, "newUrl": "some invalid URL"}`,
+			respError:  "field NewUrl is not a valid URL",
 			respStatus: http.StatusBadRequest,
 		},
 		{
-			name:       "storage error: alias exist",
-			input:      `{"url": "https://google.com", "alias": "some_alias"}`,
-			url:        "https://google.com",
-			alias:      "some_alias",
-			respError:  "alias already exists",
-			mockError:  storage.ErrAliasExist,
+			name:       "Neither id nor alias",
+			input:      `{"newUrl": "https://google.com"}`,
+			respError:  "field ID is a required field, field Alias is a required field",
 			respStatus: http.StatusBadRequest,
 		},
 		{
-			name:       "storage error: url not found",
-			input:      `{"url": "https://google.com", "alias": "some_alias"}`,
+			name:       "storage error: alias not found",
+			input:      `{"urlId": 1, "newUrl": "https://google.com"}`,
+			id:         1,
 			url:        "https://google.com",
-			alias:      "some_alias",
-			respError:  "url not found",
-			mockError:  storage.ErrURLNotFound,
+			respError:  "alias not found",
+			mockError:  storage.ErrAliasNotFound,
 			respStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "storage error: alias of another user",
+			input:      `{"alias": "foreign", "newUrl": "https://google.com"}`,
+			alias:      "foreign",
+			url:        "https://google.com",
+			respError:  "alias belongs to another user",
+			mockError:  storage.ErrAliasNotOwned,
+			respStatus: http.StatusForbidden,
+		},
 		{
 			name:       "storage error: other error",
-			input:      `{"url": "https://google.com", "alias": "some_alias"}`,
+			input:      `{"urlId": 1, "newUrl": "https://google.com"}`,
+			id:         1,
 			url:        "https://google.com",
-			alias:      "some_alias",
-			respError:  "failed to update url",
+			respError:  "failed to update alias",
 			mockError:  errors.New("unexpected error"),
 			respStatus: http.StatusInternalServerError,
 		},
 		{
-			name:       "invalid JSON input 1",
-			input:      `{"url": "https://google.com", "alias": "some_alias"`,
-			url:        "https://google.com",
-			alias:      "some_alias",
+			name:       "invalid JSON input",
+			input:      `{"urlId": 1, "newUrl": "https://google.com"`,
 			respError:  "failed to decode request",
 			respStatus: http.StatusBadRequest,
 		},
 		{
-			name:       "incorrect but working JSON input 2",
-			input:      `{"uRl": "https://google.com", "alIas": "some_alias"}`,
-			url:        "https://google.com",
-			alias:      "some_alias",
-			respStatus: http.StatusOK,
-		},
-		{
-			name:       "incorrect but working JSON input 3",
-			input:      `{"url": "https://google.com", "alia": "some_alias"}`,
-			url:        "https://google.com",
-			alias:      "some_alias",
-			respStatus: http.StatusOK,
+			name:        "Invalid Content-Type",
+			input:       `{"urlId": 1, "newUrl": "https://google.com"}`,
+			contentType: "text/plain",
+			respError:   "invalid Content-Type",
+			respStatus:  http.StatusBadRequest,
 		},
 		{
 			name:       "Empty request body",
@@ -118,23 +119,30 @@ func TestUpdateHandler(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			urlUpdatorMock := mocks.NewURLUpdater(t)
+			urlUpdaterMock := mocks.NewURLUpdater(t)
 
 			if tc.respError == "" || tc.mockError != nil {
-				urlUpdatorMock.On("UpdateURL", tc.url, mock.AnythingOfType("string")).
-					Return(tc.mockError).
-					Once()
+				if tc.id != 0 {
+					urlUpdaterMock.On("UpdateAlias", mock.Anything, tc.id, tc.url, actor).
+						Return(tc.mockError).
+						Once()
+				} else {
+					urlUpdaterMock.On("UpdateAliasURL", mock.Anything, tc.url, tc.alias, actor).
+						Return(tc.mockError).
+						Once()
+				}
 			}
 			// новое тело экземпляра хендлера
-			handler := update.New(slogdiscard.NewDiscardLogger(), urlUpdatorMock)
+			handler := update.New(slogdiscard.NewDiscardLogger(), urlUpdaterMock, nil)
 
 			// новое тело запроса
-			req, err := http.NewRequest(http.MethodPatch, "/url", bytes.NewReader([]byte(tc.input)))
+			req, err := http.NewRequest(http.MethodPatch, "/", bytes.NewReader([]byte(tc.input)))
 			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1), "role": "user"}))
 
 			//Для теста на Content-Type
-			if tc.name == "Invalid Content-Type" {
-				req.Header.Set("Content-Type", "text/plain")
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
 			} else {
 				req.Header.Set("Content-Type", "application/json")
 			}
@@ -153,7 +161,170 @@ func TestUpdateHandler(t *testing.T) {
 			var resp update.Response
 
 			require.NoError(t, json.Unmarshal(body, &resp))
-			require.Equal(t, tc.respError, resp.Response.Error)
+			require.Equal(t, tc.respError, resp.Error)
+		})
+	}
+}
+
+func TestUpdateHandler_Password(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		protected bool
+	}{
+		{
+			name:      "Set password",
+			input:     `{"urlId": 1, "password": "secret"}`,
+			protected: true,
+		},
+		{
+			name:      "Clear password",
+			input:     `{"urlId": 1, "password": ""}`,
+			protected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlUpdaterMock := mocks.NewURLUpdater(t)
+			urlUpdaterMock.On("SetLinkPassword", mock.Anything, int64(1), "", mock.MatchedBy(func(hash []byte) bool {
+				return (hash != nil) == tc.protected
+			}), actor).
+				Return(nil).
+				Once()
+
+			handler := update.New(slogdiscard.NewDiscardLogger(), urlUpdaterMock, nil)
+
+			req, err := http.NewRequest(http.MethodPatch, "/", bytes.NewReader([]byte(tc.input)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1), "role": "user"}))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp update.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.NotNil(t, resp.Protected)
+			require.Equal(t, tc.protected, *resp.Protected)
+		})
+	}
+}
+
+func TestUpdateHandler_Metadata(t *testing.T) {
+	title, empty := "Docs", ""
+
+	testCases := []struct {
+		name  string
+		input string
+		alias string
+		meta  models.LinkMetadata
+	}{
+		{
+			name:  "Set title",
+			input: `{"urlId": 1, "title": " Docs "}`,
+			meta:  models.LinkMetadata{Title: &title},
+		},
+		{
+			name:  "Clear notes by alias",
+			input: `{"alias": "docs", "notes": ""}`,
+			alias: "docs",
+			meta:  models.LinkMetadata{Notes: &empty},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			id := int64(1)
+			if tc.alias != "" {
+				id = 0
+			}
+
+			urlUpdaterMock := mocks.NewURLUpdater(t)
+			urlUpdaterMock.On("UpdateLinkMetadata", mock.Anything, id, tc.alias, tc.meta, actor).
+				Return(nil).
+				Once()
+
+			handler := update.New(slogdiscard.NewDiscardLogger(), urlUpdaterMock, nil)
+
+			req, err := http.NewRequest(http.MethodPatch, "/", bytes.NewReader([]byte(tc.input)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1), "role": "user"}))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp update.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Empty(t, resp.Error)
+			require.Nil(t, resp.Protected)
+		})
+	}
+}
+
+func TestUpdateHandler_Screening(t *testing.T) {
+	testCases := []struct {
+		name        string
+		screenError error
+		respStatus  int
+		respError   string
+	}{
+		{
+			name:       "Allowed",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:        "Rejected",
+			screenError: screening.ErrBlocklisted,
+			respStatus:  http.StatusBadRequest,
+			respError:   "destination is not allowed: domain is blocklisted",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			screenerMock := mocks.NewURLScreener(t)
+			screenerMock.On("Screen", mock.Anything, "https://evil.com").Return(tc.screenError).Once()
+
+			urlUpdaterMock := mocks.NewURLUpdater(t)
+			if tc.screenError == nil {
+				urlUpdaterMock.On("UpdateAlias", mock.Anything, int64(1), "https://evil.com", actor).
+					Return(nil).
+					Once()
+			}
+
+			handler := update.New(slogdiscard.NewDiscardLogger(), urlUpdaterMock, screenerMock)
+
+			req, err := http.NewRequest(http.MethodPatch, "/", bytes.NewReader([]byte(`{"urlId": 1, "newUrl": "https://evil.com"}`)))
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": float64(1), "role": "user"}))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.respStatus, rr.Code)
+
+			var resp update.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
 		})
 	}
 }
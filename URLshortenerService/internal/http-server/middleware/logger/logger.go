@@ -2,6 +2,7 @@ package logger
 
 import (
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
 	"net/http"
 	"time"
@@ -22,6 +23,10 @@ func New(log *slog.Logger) func(next http.Handler) http.Handler {
 				slog.String("user_agent", r.UserAgent()),
 				slog.String("request_id", middleware.GetReqID(r.Context())),
 			)
+			// По trace_id запрос находится в трассе вместе с вызовами других сервисов
+			if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+				entry = entry.With(slog.String("trace_id", sc.TraceID().String()))
+			}
 
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
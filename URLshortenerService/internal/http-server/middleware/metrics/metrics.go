@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	metricslib "URLshortener/internal/lib/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// routeUnmatched labels requests that matched no route.
+const routeUnmatched = "unmatched"
+
+type RequestObserver interface {
+	ObserveRequest(route string, method string, status int, duration time.Duration)
+}
+
+type RedirectCounter interface {
+	Redirect(outcome string)
+}
+
+// New measures every request by the pattern of the chi route it matched.
+// It must be used on the router itself, so the route is known once the
+// request is served.
+func New(log *slog.Logger, observer RequestObserver) func(next http.Handler) http.Handler {
+	log.With(
+		slog.String("component", "middleware/metrics"),
+	).Info("metrics middleware enabled")
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			t1 := time.Now()
+			defer func() {
+				observer.ObserveRequest(routePattern(r), r.Method, status(ww), time.Since(t1))
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Redirects counts the outcomes of the redirect handler by the status it answered with.
+func Redirects(counter RedirectCounter) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			defer func() {
+				counter.Redirect(Outcome(status(ww)))
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Outcome maps a status of the redirect handler to the outcome of the redirect.
+// The password form of a protected link is shown with 200, a wrong password
+// gets 403. 429 comes from the rate limit of the visitor or from too many
+// password attempts on the link.
+func Outcome(status int) string {
+	switch {
+	case status >= 300 && status < 400:
+		return metricslib.RedirectFound
+	case status == http.StatusNotFound:
+		return metricslib.RedirectNotFound
+	case status == http.StatusGone:
+		return metricslib.RedirectExpired
+	case status == http.StatusOK:
+		return metricslib.RedirectPasswordRequired
+	case status == http.StatusForbidden:
+		return metricslib.RedirectBlocked
+	case status == http.StatusTooManyRequests:
+		return metricslib.RedirectRateLimited
+	default:
+		return metricslib.RedirectError
+	}
+}
+
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.RoutePattern() == "" {
+		return routeUnmatched
+	}
+	return rctx.RoutePattern()
+}
+
+// status treats a response without an explicit status as 200, as net/http does.
+func status(ww middleware.WrapResponseWriter) int {
+	if ww.Status() == 0 {
+		return http.StatusOK
+	}
+	return ww.Status()
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	metricslib "URLshortener/internal/lib/metrics"
+)
+
+type observed struct {
+	route  string
+	method string
+	status int
+}
+
+type recorder struct {
+	mu       sync.Mutex
+	requests []observed
+	outcomes []string
+}
+
+func (r *recorder) ObserveRequest(route string, method string, status int, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, observed{route: route, method: method, status: status})
+}
+
+func (r *recorder) Redirect(outcome string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes = append(r.outcomes, outcome)
+}
+
+func TestNew(t *testing.T) {
+	rec := &recorder{}
+
+	router := chi.NewRouter()
+	router.Use(New(slogdiscard.NewDiscardLogger(), rec))
+	router.Group(func(r chi.Router) {
+		r.With(Redirects(rec)).Get("/{alias}", func(w http.ResponseWriter, r *http.Request) {
+			if chi.URLParam(r, "alias") == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			http.Redirect(w, r, "https://example.com", http.StatusFound)
+		})
+	})
+	router.Post("/links/{id}/tags", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	for _, target := range []string{"/abc", "/missing", "/links/7/tags", "/a/b/c"} {
+		method := http.MethodGet
+		if target == "/links/7/tags" {
+			method = http.MethodPost
+		}
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+	}
+
+	// в метках шаблон маршрута, а не путь
+	require.Equal(t, []observed{
+		{route: "/{alias}", method: http.MethodGet, status: http.StatusFound},
+		{route: "/{alias}", method: http.MethodGet, status: http.StatusNotFound},
+		{route: "/links/{id}/tags", method: http.MethodPost, status: http.StatusOK},
+		{route: routeUnmatched, method: http.MethodGet, status: http.StatusNotFound},
+	}, rec.requests)
+
+	require.Equal(t, []string{metricslib.RedirectFound, metricslib.RedirectNotFound}, rec.outcomes)
+}
+
+func TestOutcome(t *testing.T) {
+	testCases := []struct {
+		status  int
+		outcome string
+	}{
+		{status: http.StatusMovedPermanently, outcome: metricslib.RedirectFound},
+		{status: http.StatusSeeOther, outcome: metricslib.RedirectFound},
+		{status: http.StatusPermanentRedirect, outcome: metricslib.RedirectFound},
+		{status: http.StatusNotFound, outcome: metricslib.RedirectNotFound},
+		{status: http.StatusGone, outcome: metricslib.RedirectExpired},
+		{status: http.StatusOK, outcome: metricslib.RedirectPasswordRequired},
+		{status: http.StatusForbidden, outcome: metricslib.RedirectBlocked},
+		{status: http.StatusTooManyRequests, outcome: metricslib.RedirectRateLimited},
+		{status: http.StatusBadRequest, outcome: metricslib.RedirectError},
+		{status: http.StatusInternalServerError, outcome: metricslib.RedirectError},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.outcome, Outcome(tc.status), http.StatusText(tc.status))
+	}
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	jwtlib "URLshortener/internal/jwt"
+	"URLshortener/internal/lib/logger/sl"
+	"github.com/go-chi/render"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KeyFunc returns the key of the bucket the request takes a token from.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys requests on the client address. It relies on the realip
+// middleware to put the address set by nginx into RemoteAddr.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// ByUser keys requests on the user ID from the JWT claims and falls back to
+// the client address for requests without them.
+func ByUser(r *http.Request) string {
+	actor, err := jwtlib.GetActorFromContext(r.Context())
+	if err != nil {
+		return ByIP(r)
+	}
+
+	return "user:" + strconv.FormatInt(actor.UserID, 10)
+}
+
+// New limits requests to the routes it wraps. Buckets of different names are
+// independent, so several limits can be stacked on one route. Requests over
+// the limit get 429 with Retry-After, all responses get X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (seconds until the bucket is full).
+// If the store fails, requests are let through.
+func New(log *slog.Logger, store Store, name string, limit Limit, key KeyFunc) func(next http.Handler) http.Handler {
+	disabled := limit.Requests <= 0 || limit.Per <= 0
+	if disabled {
+		log.Info("rate limit disabled", slog.String("limit", name))
+	} else {
+		log.Info("rate limit enabled",
+			slog.String("limit", name),
+			slog.Int("requests", limit.Requests),
+			slog.Duration("per", limit.Per),
+			slog.Int("burst", limit.burst()),
+		)
+	}
+
+	return func(next http.Handler) http.Handler {
+		if disabled {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			res, err := store.Take(r.Context(), name+":"+key(r), limit)
+			if err != nil {
+				log.Error("failed to take rate limit token", slog.String("limit", name), sl.Err(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("X-RateLimit-Reset", ceilSeconds(res.Reset))
+
+			if !res.Allowed {
+				log.Info("rate limit exceeded", slog.String("limit", name), slog.String("key", key(r)))
+
+				w.Header().Set("Retry-After", ceilSeconds(res.RetryAfter))
+				render.Status(r, http.StatusTooManyRequests)
+				render.JSON(w, r, map[string]string{
+					"message": "too many requests",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	// 1 запрос в секунду, до 3 подряд
+	limit := Limit{Requests: 60, Per: time.Minute, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		res, err := s.Take(context.Background(), "key", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, 3, res.Limit)
+		require.Equal(t, i, res.Remaining)
+	}
+
+	res, err := s.Take(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, time.Second, res.RetryAfter)
+	require.Equal(t, 3*time.Second, res.Reset)
+
+	// другие ключи не ограничиваются
+	res, err = s.Take(context.Background(), "other", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	now = now.Add(1500 * time.Millisecond)
+
+	res, err = s.Take(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+
+	now = now.Add(time.Hour)
+
+	res, err = s.Take(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 2, res.Remaining)
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("store is down")
+}
+
+func TestMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	handler := New(slogdiscard.NewDiscardLogger(), NewMemoryStore(), "api", Limit{Requests: 1, Per: time.Minute}, ByUser)(ok)
+
+	request := func(uid float64, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/urls", nil)
+		req.RemoteAddr = addr
+		if uid != 0 {
+			req = req.WithContext(context.WithValue(req.Context(), "claims", jwt.MapClaims{"uid": uid}))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request(1, "10.0.0.1:1000")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "1", rr.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	require.Equal(t, "60", rr.Header().Get("X-RateLimit-Reset"))
+
+	// тот же пользователь с другого адреса
+	rr = request(1, "10.0.0.2:1000")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	require.Equal(t, http.StatusOK, request(2, "10.0.0.1:1000").Code)
+
+	// без токена ключом служит адрес
+	require.Equal(t, http.StatusOK, request(0, "10.0.0.3:1000").Code)
+	require.Equal(t, http.StatusTooManyRequests, request(0, "10.0.0.3:2000").Code)
+}
+
+func TestMiddleware_StoreError(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	handler := New(slogdiscard.NewDiscardLogger(), failingStore{}, "api", Limit{Requests: 1, Per: time.Minute}, ByIP)(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.True(t, called)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("X-RateLimit-Limit"))
+}
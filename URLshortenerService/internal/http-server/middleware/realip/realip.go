@@ -0,0 +1,32 @@
+package realip
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// Header is set by nginx to the address the connection came from.
+const Header = "X-Real-IP"
+
+// New puts the client address from X-Real-IP into RemoteAddr. Unlike
+// middleware.RealIP it ignores True-Client-IP and X-Forwarded-For: nginx
+// overwrites only X-Real-IP, the other headers come from the client as is
+// and would let it pick its own rate limit bucket and click address.
+func New(log *slog.Logger) func(next http.Handler) http.Handler {
+	log.With(
+		slog.String("component", "middleware/realip"),
+	).Info("real ip middleware enabled")
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if ip := net.ParseIP(r.Header.Get(Header)); ip != nil {
+				r.RemoteAddr = ip.String()
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,63 @@
+package realip
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "X-Real-IP from nginx",
+			headers: map[string]string{"X-Real-IP": "203.0.113.7"},
+			want:    "203.0.113.7",
+		},
+		{
+			name: "client headers are ignored",
+			headers: map[string]string{
+				"X-Real-IP":       "203.0.113.7",
+				"True-Client-IP":  "198.51.100.1",
+				"X-Forwarded-For": "198.51.100.2",
+			},
+			want: "203.0.113.7",
+		},
+		{
+			name:    "without X-Real-IP",
+			headers: map[string]string{"True-Client-IP": "198.51.100.1"},
+			want:    "192.0.2.1:1234",
+		},
+		{
+			name:    "invalid X-Real-IP",
+			headers: map[string]string{"X-Real-IP": "not an ip"},
+			want:    "192.0.2.1:1234",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			handler := New(slog.New(slog.NewTextHandler(io.Discard, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/alias", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net/http"
+)
+
+// New names the span of the request by the pattern of the chi route it
+// matched and tags it with the request id, so a trace can be found by the
+// id from the logs. The span is started by otelhttp around the router, and
+// the middleware must be used after middleware.RequestID.
+func New(log *slog.Logger) func(next http.Handler) http.Handler {
+	log.With(
+		slog.String("component", "middleware/tracing"),
+	).Info("tracing middleware enabled")
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			span := trace.SpanFromContext(r.Context())
+			span.SetAttributes(attribute.String("request_id", middleware.GetReqID(r.Context())))
+
+			defer func() {
+				rctx := chi.RouteContext(r.Context())
+				if rctx == nil || rctx.RoutePattern() == "" {
+					return
+				}
+
+				span.SetName(r.Method + " " + rctx.RoutePattern())
+				span.SetAttributes(attribute.String("http.route", rctx.RoutePattern()))
+			}()
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
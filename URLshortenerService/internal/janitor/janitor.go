@@ -0,0 +1,97 @@
+package janitor
+
+import (
+	"URLshortener/internal/lib/logger/sl"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	ModeDelete  = "delete"
+	ModeArchive = "archive"
+)
+
+type Purger interface {
+	PurgeExpiredLinks(ctx context.Context, now time.Time, archive bool) (int64, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Janitor periodically purges or archives expired links and empties the
+// trash of links deleted more than the retention period ago.
+type Janitor struct {
+	log       *slog.Logger
+	purger    Purger
+	interval  time.Duration
+	archive   bool
+	retention time.Duration
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func New(log *slog.Logger, purger Purger, interval time.Duration, mode string, retention time.Duration) *Janitor {
+	return &Janitor{
+		log:       log.With(slog.String("component", "janitor")),
+		purger:    purger,
+		interval:  interval,
+		archive:   mode == ModeArchive,
+		retention: retention,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the janitor in the background until Stop is called.
+func (j *Janitor) Start() {
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		j.log.Info("janitor started", slog.String("interval", j.interval.String()), slog.Bool("archive", j.archive),
+			slog.String("trash_retention", j.retention.String()))
+
+		for {
+			j.RunOnce()
+
+			select {
+			case <-ticker.C:
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// RunOnce purges the links that are expired at the moment and the links
+// that have stayed in the trash longer than the retention period.
+func (j *Janitor) RunOnce() {
+	ctx := context.Background()
+	now := time.Now()
+
+	purged, err := j.purger.PurgeExpiredLinks(ctx, now, j.archive)
+	if err != nil {
+		j.log.Error("failed to purge expired links", sl.Err(err))
+	} else if purged > 0 {
+		j.log.Info("expired links purged", slog.Int64("count", purged))
+	}
+
+	purged, err = j.purger.PurgeTrash(ctx, now.Add(-j.retention))
+	if err != nil {
+		j.log.Error("failed to empty trash", sl.Err(err))
+	} else if purged > 0 {
+		j.log.Info("trashed links purged", slog.Int64("count", purged))
+	}
+}
+
+// Stop waits for the current run to finish. It must be called after Start.
+func (j *Janitor) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+}
@@ -117,3 +117,21 @@ func GetClaimsFromContext(ctx context.Context) (jwt.MapClaims, error) {
 	}
 	return claims, nil
 }
+
+// GetActorFromContext returns the user ID and the role from the JWT claims.
+func GetActorFromContext(ctx context.Context) (models.Actor, error) {
+	claims, err := GetClaimsFromContext(ctx)
+	if err != nil {
+		return models.Actor{}, err
+	}
+
+	uid, ok := claims["uid"].(float64)
+	if !ok {
+		return models.Actor{}, errors.New("failed to get field uid from claims")
+	}
+
+	// В старых токенах роли может не быть
+	role, _ := claims["role"].(string)
+
+	return models.Actor{UserID: int64(uid), Role: role}, nil
+}
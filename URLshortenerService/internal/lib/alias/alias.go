@@ -0,0 +1,174 @@
+package alias
+
+import (
+	"URLshortener/internal/lib/random"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	KindRandom     = "random"
+	KindSequential = "sequential"
+	KindHashids    = "hashids"
+	KindWords      = "words"
+)
+
+var (
+	ErrNoFreeAlias     = errors.New("failed to generate a free alias")
+	ErrInvalidAlphabet = errors.New("invalid alphabet")
+)
+
+// Generator produces candidate aliases. A candidate may already be taken,
+// Allocator retries with a new one in that case.
+type Generator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// Sequence issues increasing ids for sequential aliases.
+type Sequence interface {
+	NextAliasID(ctx context.Context) (int64, error)
+}
+
+// Allocator saves links under generated aliases and retries when the
+// generated alias turns out to be taken.
+type Allocator struct {
+	Generator
+	attempts int
+}
+
+func NewAllocator(gen Generator, attempts int) *Allocator {
+	return &Allocator{Generator: gen, attempts: attempts}
+}
+
+// Attempts is the number of aliases Allocate generates before giving up.
+func (a *Allocator) Attempts() int {
+	return a.attempts
+}
+
+// Generate returns a generated alias that passes Validate. Generators do not
+// know the reserved route names, so such candidates are skipped.
+func (a *Allocator) Generate(ctx context.Context) (string, error) {
+	const op = "lib.alias.Generate"
+
+	for i := 0; i < a.attempts; i++ {
+		alias, err := a.Generator.Generate(ctx)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		if Validate(alias) == nil {
+			return alias, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: %w", op, ErrNoFreeAlias)
+}
+
+// Allocate calls try with generated aliases until it succeeds or fails with
+// an error other than storage.ErrAliasExist. It returns the alias that was saved.
+func (a *Allocator) Allocate(ctx context.Context, try func(alias string) error) (string, error) {
+	const op = "lib.alias.Allocate"
+
+	for i := 0; i < a.attempts; i++ {
+		alias, err := a.Generate(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		err = try(alias)
+		if err == nil {
+			return alias, nil
+		}
+		if !errors.Is(err, storage.ErrAliasExist) {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("%s: %w", op, ErrNoFreeAlias)
+}
+
+// Random generates crypto-random aliases of a fixed length.
+type Random struct {
+	length   int
+	alphabet string
+}
+
+func NewRandom(length int, alphabet string) (*Random, error) {
+	if err := checkAlphabet(alphabet, 2); err != nil {
+		return nil, err
+	}
+	return &Random{length: length, alphabet: alphabet}, nil
+}
+
+func (g *Random) Generate(context.Context) (string, error) {
+	return random.String(g.length, g.alphabet), nil
+}
+
+// Sequential encodes the next id of the sequence in the alphabet and pads it
+// with the first character of the alphabet up to minLength.
+type Sequential struct {
+	seq       Sequence
+	alphabet  []rune
+	minLength int
+}
+
+func NewSequential(seq Sequence, minLength int, alphabet string) (*Sequential, error) {
+	if err := checkAlphabet(alphabet, 2); err != nil {
+		return nil, err
+	}
+	return &Sequential{seq: seq, alphabet: []rune(alphabet), minLength: minLength}, nil
+}
+
+func (g *Sequential) Generate(ctx context.Context) (string, error) {
+	const op = "lib.alias.Sequential.Generate"
+
+	id, err := g.seq.NextAliasID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	encoded := encode(id, g.alphabet)
+	if pad := g.minLength - len(encoded); pad > 0 {
+		encoded = append([]rune(strings.Repeat(string(g.alphabet[0]), pad)), encoded...)
+	}
+
+	return string(encoded), nil
+}
+
+// encode writes a non-negative number in the positional system of the alphabet.
+func encode(n int64, alphabet []rune) []rune {
+	base := int64(len(alphabet))
+
+	var out []rune
+	for {
+		out = append([]rune{alphabet[n%base]}, out...)
+		n /= base
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+func checkAlphabet(alphabet string, minLength int) error {
+	chars := []rune(alphabet)
+	if len(chars) < minLength {
+		return fmt.Errorf("%w: at least %d characters required", ErrInvalidAlphabet, minLength)
+	}
+
+	seen := make(map[rune]bool, len(chars))
+	for _, ch := range chars {
+		if seen[ch] {
+			return fmt.Errorf("%w: duplicate character %q", ErrInvalidAlphabet, ch)
+		}
+		// the same characters Validate accepts in a client alias
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '-' && ch != '_' {
+			return fmt.Errorf("%w: character %q is not allowed in an alias", ErrInvalidAlphabet, ch)
+		}
+		seen[ch] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package alias
+
+import (
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type counter struct {
+	next int64
+}
+
+func (c *counter) NextAliasID(context.Context) (int64, error) {
+	c.next++
+	return c.next, nil
+}
+
+const hashidsAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+
+func TestHashids(t *testing.T) {
+	// Значения из документации Hashids
+	h, err := NewHashids(&counter{}, "this is my salt", 0, hashidsAlphabet)
+	require.NoError(t, err)
+	require.Equal(t, "NkK9", h.Encode(12345))
+
+	h, err = NewHashids(&counter{}, "this is my salt", 8, hashidsAlphabet)
+	require.NoError(t, err)
+	require.Equal(t, "gB0NV05e", h.Encode(1))
+
+	_, err = NewHashids(&counter{}, "salt", 0, "abc")
+	require.ErrorIs(t, err, ErrInvalidAlphabet)
+}
+
+func TestHashids_Unique(t *testing.T) {
+	h, err := NewHashids(&counter{}, "salt", 6, hashidsAlphabet)
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		alias, err := h.Generate(context.Background())
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(alias), 6)
+		require.False(t, seen[alias], alias)
+		seen[alias] = true
+	}
+}
+
+func TestSequential(t *testing.T) {
+	g, err := NewSequential(&counter{next: 60}, 3, "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+	require.NoError(t, err)
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		alias, err := g.Generate(context.Background())
+		require.NoError(t, err)
+		got = append(got, alias)
+	}
+	require.Equal(t, []string{"00z", "010", "011"}, got)
+}
+
+func TestRandom(t *testing.T) {
+	g, err := NewRandom(8, "ab")
+	require.NoError(t, err)
+
+	alias, err := g.Generate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, alias, 8)
+	require.Empty(t, strings.Trim(alias, "ab"))
+
+	_, err = NewRandom(8, "aa")
+	require.ErrorIs(t, err, ErrInvalidAlphabet)
+
+	_, err = NewRandom(8, "ab/")
+	require.ErrorIs(t, err, ErrInvalidAlphabet)
+
+	_, err = NewRandom(8, "ab+")
+	require.ErrorIs(t, err, ErrInvalidAlphabet)
+}
+
+func TestWords(t *testing.T) {
+	alias, err := NewWords().Generate(context.Background())
+	require.NoError(t, err)
+	require.Regexp(t, `^[a-z]+-[a-z]+-[0-9]+$`, alias)
+}
+
+func TestAllocator(t *testing.T) {
+	a := NewAllocator(&sequenceGenerator{}, 3)
+
+	var tried []string
+	alias, err := a.Allocate(context.Background(), func(alias string) error {
+		tried = append(tried, alias)
+		if len(tried) < 3 {
+			return fmt.Errorf("save: %w", storage.ErrAliasExist)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "a3", alias)
+	require.Equal(t, []string{"a1", "a2", "a3"}, tried)
+
+	_, err = a.Allocate(context.Background(), func(string) error { return storage.ErrAliasExist })
+	require.ErrorIs(t, err, ErrNoFreeAlias)
+
+	other := errors.New("unexpected error")
+	_, err = a.Allocate(context.Background(), func(string) error { return other })
+	require.ErrorIs(t, err, other)
+}
+
+func TestAllocator_SkipsReserved(t *testing.T) {
+	a := NewAllocator(&listGenerator{aliases: []string{"admin", "Stats", "free"}}, 3)
+
+	var tried []string
+	alias, err := a.Allocate(context.Background(), func(alias string) error {
+		tried = append(tried, alias)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "free", alias)
+	require.Equal(t, []string{"free"}, tried)
+
+	a = NewAllocator(&listGenerator{aliases: []string{"admin", "urls", "tags"}}, 3)
+	_, err = a.Generate(context.Background())
+	require.ErrorIs(t, err, ErrNoFreeAlias)
+}
+
+type listGenerator struct {
+	aliases []string
+}
+
+func (g *listGenerator) Generate(context.Context) (string, error) {
+	alias := g.aliases[0]
+	g.aliases = g.aliases[1:]
+	return alias, nil
+}
+
+type sequenceGenerator struct {
+	n int
+}
+
+func (g *sequenceGenerator) Generate(context.Context) (string, error) {
+	g.n++
+	return fmt.Sprintf("a%d", g.n), nil
+}
+
+func TestValidate(t *testing.T) {
+	for _, alias := range []string{"promo", "brave-otter-42", "my_link", "акция", strings.Repeat("a", MaxLength)} {
+		require.NoError(t, Validate(alias), alias)
+	}
+
+	for _, alias := range []string{"", "a/b", "page.html", "a?b", "a#b", "a b", "50%", strings.Repeat("a", MaxLength+1)} {
+		require.ErrorIs(t, Validate(alias), ErrInvalidAlias, alias)
+	}
+
+	for _, alias := range []string{"urls", "healthz", "Stats"} {
+		require.ErrorIs(t, Validate(alias), ErrReservedAlias, alias)
+	}
+}
@@ -0,0 +1,151 @@
+package alias
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Параметры алгоритма Hashids, менять нельзя: иначе алиасы не совпадут с другими реализациями
+const (
+	hashidsSeps        = "cfhistuCFHISTU"
+	hashidsSepDiv      = 3.5
+	hashidsGuardDiv    = 12
+	hashidsMinAlphabet = 16
+)
+
+// Hashids encodes ids of the sequence with the Hashids algorithm, so
+// sequential aliases do not reveal how many links exist.
+// The output is compatible with other Hashids implementations for a single number.
+type Hashids struct {
+	seq       Sequence
+	salt      []rune
+	minLength int
+	alphabet  []rune
+	seps      []rune
+	guards    []rune
+}
+
+func NewHashids(seq Sequence, salt string, minLength int, alphabet string) (*Hashids, error) {
+	if err := checkAlphabet(alphabet, hashidsMinAlphabet); err != nil {
+		return nil, err
+	}
+
+	h := &Hashids{seq: seq, salt: []rune(salt), minLength: minLength}
+
+	var alpha, seps []rune
+	for _, ch := range alphabet {
+		if !strings.ContainsRune(hashidsSeps, ch) {
+			alpha = append(alpha, ch)
+		}
+	}
+	if len(alpha) < 2 {
+		return nil, fmt.Errorf("%w: too few characters outside of %q", ErrInvalidAlphabet, hashidsSeps)
+	}
+
+	// Порядок сепараторов задаётся порядком в hashidsSeps, а не в алфавите
+	for _, ch := range hashidsSeps {
+		if strings.ContainsRune(alphabet, ch) {
+			seps = append(seps, ch)
+		}
+	}
+
+	shuffle(seps, h.salt)
+
+	if len(seps) == 0 || float64(len(alpha))/float64(len(seps)) > hashidsSepDiv {
+		sepsLength := int(math.Ceil(float64(len(alpha)) / hashidsSepDiv))
+		if sepsLength == 1 {
+			sepsLength = 2
+		}
+		if sepsLength > len(seps) {
+			diff := sepsLength - len(seps)
+			seps = append(seps, alpha[:diff]...)
+			alpha = alpha[diff:]
+		} else {
+			seps = seps[:sepsLength]
+		}
+	}
+
+	shuffle(alpha, h.salt)
+
+	guardCount := int(math.Ceil(float64(len(alpha)) / hashidsGuardDiv))
+	if len(alpha) < 3 {
+		h.guards = seps[:guardCount]
+		seps = seps[guardCount:]
+	} else {
+		h.guards = alpha[:guardCount]
+		alpha = alpha[guardCount:]
+	}
+
+	h.alphabet = alpha
+	h.seps = seps
+
+	return h, nil
+}
+
+func (h *Hashids) Generate(ctx context.Context) (string, error) {
+	const op = "lib.alias.Hashids.Generate"
+
+	id, err := h.seq.NextAliasID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return h.Encode(id), nil
+}
+
+// Encode returns the hashid of a non-negative number.
+func (h *Hashids) Encode(n int64) string {
+	alphabet := append([]rune(nil), h.alphabet...)
+
+	numbersID := n % 100
+	lottery := alphabet[numbersID%int64(len(alphabet))]
+
+	buffer := append([]rune{lottery}, h.salt...)
+	buffer = append(buffer, alphabet...)
+	shuffle(alphabet, buffer[:len(alphabet)])
+
+	result := append([]rune{lottery}, encode(n, alphabet)...)
+
+	if len(result) < h.minLength {
+		guard := h.guards[(numbersID+int64(result[0]))%int64(len(h.guards))]
+		result = append([]rune{guard}, result...)
+
+		if len(result) < h.minLength {
+			guard = h.guards[(numbersID+int64(result[2]))%int64(len(h.guards))]
+			result = append(result, guard)
+		}
+	}
+
+	half := len(alphabet) / 2
+	for len(result) < h.minLength {
+		shuffle(alphabet, append([]rune(nil), alphabet...))
+
+		padded := append([]rune(nil), alphabet[half:]...)
+		padded = append(padded, result...)
+		padded = append(padded, alphabet[:half]...)
+		result = padded
+
+		if excess := len(result) - h.minLength; excess > 0 {
+			result = result[excess/2 : excess/2+h.minLength]
+		}
+	}
+
+	return string(result)
+}
+
+// shuffle is the deterministic shuffle of Hashids driven by the salt.
+func shuffle(alphabet []rune, salt []rune) {
+	if len(salt) == 0 {
+		return
+	}
+
+	for i, v, p := len(alphabet)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		integer := int(salt[v])
+		p += integer
+		j := (integer + v + p) % i
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+}
@@ -0,0 +1,59 @@
+package alias
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest alias a client may choose.
+const MaxLength = 64
+
+var (
+	ErrInvalidAlias  = errors.New("alias must be 1-64 letters, digits, '-' or '_'")
+	ErrReservedAlias = errors.New("alias is reserved")
+)
+
+// reserved are the first path segments of the service's own routes. chi
+// matches static routes before /{alias}, so a link under one of these
+// aliases could never be opened. Keep in sync with the router in app.go.
+var reserved = map[string]bool{
+	"admin":   true,
+	"alias":   true,
+	"batch":   true,
+	"domains": true,
+	"export":  true,
+	"folders": true,
+	"healthz": true,
+	"import":  true,
+	"links":   true,
+	"readyz":  true,
+	"stats":   true,
+	"tags":    true,
+	"trash":   true,
+	"urls":    true,
+	"version": true,
+}
+
+// Validate checks an alias chosen by the client. The alias becomes a path
+// segment of the short link, so it is limited to letters, digits, '-' and
+// '_': '/', '?' and '#' split the URL and middleware.URLFormat cuts
+// everything after a '.'.
+func Validate(alias string) error {
+	if alias == "" || utf8.RuneCountInString(alias) > MaxLength {
+		return ErrInvalidAlias
+	}
+
+	for _, r := range alias {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return ErrInvalidAlias
+		}
+	}
+
+	if reserved[strings.ToLower(alias)] {
+		return ErrReservedAlias
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package alias
+
+import (
+	"URLshortener/internal/lib/random"
+	"context"
+	"strconv"
+)
+
+// maxWordsNumber bounds the number at the end of word aliases.
+const maxWordsNumber = 1000
+
+var adjectives = []string{
+	"amber", "bold", "brave", "bright", "calm", "clever", "cosy", "crisp",
+	"curly", "daring", "eager", "early", "fancy", "fast", "fluffy", "fresh",
+	"gentle", "giant", "glad", "golden", "grand", "green", "happy", "hidden",
+	"humble", "icy", "jolly", "kind", "lazy", "little", "lively", "lucky",
+	"mellow", "merry", "mighty", "misty", "modern", "noble", "odd", "polite",
+	"proud", "quick", "quiet", "rapid", "rare", "rosy", "royal", "rusty",
+	"shiny", "silent", "silver", "simple", "sleepy", "smart", "snowy", "solid",
+	"sunny", "swift", "tidy", "tiny", "vivid", "warm", "wild", "witty",
+}
+
+var nouns = []string{
+	"apple", "badger", "beacon", "breeze", "brook", "canyon", "cedar", "cloud",
+	"comet", "coral", "crane", "dolphin", "dune", "eagle", "ember", "falcon",
+	"fern", "field", "fox", "garden", "glacier", "harbor", "hawk", "heron",
+	"island", "lagoon", "lantern", "leaf", "lemon", "meadow", "moon", "moss",
+	"night", "oak", "ocean", "orchid", "otter", "owl", "panda", "pebble",
+	"pine", "planet", "pond", "rabbit", "raven", "reef", "river", "robin",
+	"rocket", "sparrow", "spruce", "star", "stone", "storm", "summit", "tiger",
+	"tulip", "valley", "violet", "wave", "willow", "wind", "wolf", "zebra",
+}
+
+// Words generates readable aliases like "brave-otter-42".
+type Words struct{}
+
+func NewWords() *Words {
+	return &Words{}
+}
+
+func (g *Words) Generate(context.Context) (string, error) {
+	return random.Choice(adjectives) + "-" + random.Choice(nouns) + "-" + strconv.FormatInt(random.Int(maxWordsNumber), 10), nil
+}
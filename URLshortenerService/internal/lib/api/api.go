@@ -3,7 +3,9 @@ package api
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 )
 
 var (
@@ -32,3 +34,14 @@ func GetRedirect(url string) (string, error) {
 
 	return resp.Header.Get("Location"), nil
 }
+
+// RequestHost returns the host the request was sent to, lowercased and
+// without the port.
+func RequestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
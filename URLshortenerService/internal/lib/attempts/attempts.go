@@ -0,0 +1,78 @@
+package attempts
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepThreshold is the number of tracked keys after which stale entries are dropped.
+const sweepThreshold = 10000
+
+type entry struct {
+	failures    int
+	windowStart time.Time
+}
+
+// Limiter counts failed attempts per key in a fixed time window and blocks
+// the key once the limit is reached until the window ends.
+type Limiter struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	entries     map[string]*entry
+	now         func() time.Time
+}
+
+func New(maxFailures int, window time.Duration) *Limiter {
+	return &Limiter{
+		maxFailures: maxFailures,
+		window:      window,
+		entries:     make(map[string]*entry),
+		now:         time.Now,
+	}
+}
+
+// Allow reserves an attempt for the key and reports whether it is allowed.
+// The attempt is counted as failed right away, so concurrent attempts can
+// not get past the limit together; the caller calls succeed once the
+// attempt turns out to be successful. If the attempt is not allowed, Allow
+// also returns the time left until the block is lifted.
+func (l *Limiter) Allow(key string) (succeed func(), ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	e, found := l.entries[key]
+	if !found || now.Sub(e.windowStart) >= l.window {
+		if !found && len(l.entries) >= sweepThreshold {
+			l.sweep(now)
+		}
+		e = &entry{windowStart: now}
+		l.entries[key] = e
+	}
+
+	if e.failures >= l.maxFailures {
+		return nil, false, l.window - now.Sub(e.windowStart)
+	}
+
+	e.failures++
+
+	return func() { l.Reset(key) }, true, 0
+}
+
+// Reset forgets failed attempts of the key.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	for key, e := range l.entries {
+		if now.Sub(e.windowStart) >= l.window {
+			delete(l.entries, key)
+		}
+	}
+}
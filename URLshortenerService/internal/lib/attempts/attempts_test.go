@@ -0,0 +1,75 @@
+package attempts
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	l := New(3, time.Minute)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, ok, _ := l.Allow("alias")
+		require.True(t, ok)
+	}
+
+	_, ok, retryAfter := l.Allow("alias")
+	require.False(t, ok)
+	require.Equal(t, time.Minute, retryAfter)
+
+	// другие ключи не блокируются
+	_, ok, _ = l.Allow("other")
+	require.True(t, ok)
+
+	now = now.Add(40 * time.Second)
+	_, ok, retryAfter = l.Allow("alias")
+	require.False(t, ok)
+	require.Equal(t, 20*time.Second, retryAfter)
+
+	now = now.Add(20 * time.Second)
+	_, ok, _ = l.Allow("alias")
+	require.True(t, ok)
+}
+
+func TestLimiter_Succeed(t *testing.T) {
+	l := New(1, time.Minute)
+
+	succeed, ok, _ := l.Allow("alias")
+	require.True(t, ok)
+
+	// попытка занята, пока не известен ее результат
+	_, ok, _ = l.Allow("alias")
+	require.False(t, ok)
+
+	succeed()
+	_, ok, _ = l.Allow("alias")
+	require.True(t, ok)
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	l := New(3, time.Minute)
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int64
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok, _ := l.Allow("alias"); ok {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.EqualValues(t, 3, allowed.Load())
+}
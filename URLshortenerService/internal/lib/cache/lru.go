@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type item[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU is a size-bounded cache that evicts the least recently used entry
+// and drops entries older than their TTL. It is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	ll       *list.List
+	items    map[K]*list.Element
+	now      func() time.Time
+}
+
+// New creates a cache holding up to capacity entries. Zero ttl disables expiration.
+func New[K comparable, V any](capacity int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[K]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	it := el.Value.(*item[K, V])
+	if !it.expiresAt.IsZero() && !c.now().Before(it.expiresAt) {
+		c.removeElement(el)
+		var zero V
+		return zero, false
+	}
+
+	c.ll.MoveToFront(el)
+	return it.value, true
+}
+
+// Set stores the value with the default TTL of the cache.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores the value with its own TTL. Zero ttl means no expiration.
+func (c *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		it := el.Value.(*item[K, V])
+		it.value = value
+		it.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&item[K, V]{key: key, value: value, expiresAt: expiresAt})
+
+	if c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// Range calls fn for every entry that has not expired, without changing the
+// recency of the entries. fn must not call the cache.
+func (c *LRU[K, V]) Range(fn func(key K, value V)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		it := el.Value.(*item[K, V])
+		if it.expiresAt.IsZero() || now.Before(it.expiresAt) {
+			fn(it.key, it.value)
+		}
+	}
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *LRU[K, V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*item[K, V]).key)
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU_Evict(t *testing.T) {
+	c := New[string, int](2, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	// a становится самым свежим, вытесняется b
+	_, ok := c.Get("a")
+	require.True(t, ok)
+	c.Set("c", 3)
+
+	_, ok = c.Get("b")
+	require.False(t, ok)
+
+	v, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	require.Equal(t, 2, c.Len())
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	c := New[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+
+	now = now.Add(time.Minute)
+
+	_, ok := c.Get("a")
+	require.False(t, ok)
+
+	_, ok = c.Get("b")
+	require.True(t, ok)
+
+	c.Delete("b")
+	_, ok = c.Get("b")
+	require.False(t, ok)
+	require.Equal(t, 0, c.Len())
+}
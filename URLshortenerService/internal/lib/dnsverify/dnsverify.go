@@ -0,0 +1,73 @@
+package dnsverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// RecordPrefix is prepended to the domain to get the name of the TXT record.
+	RecordPrefix = "_urlshortener."
+	// ValuePrefix is prepended to the token to get the value of the TXT record.
+	ValuePrefix = "urlshortener-verification="
+)
+
+var ErrRecordNotFound = errors.New("verification record not found")
+
+// Resolver looks up TXT records. *net.Resolver implements it,
+// tests use a fake one.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Verifier checks that the owner of a domain published the verification
+// token in its DNS.
+type Verifier struct {
+	resolver Resolver
+	timeout  time.Duration
+}
+
+func New(resolver Resolver, timeout time.Duration) *Verifier {
+	return &Verifier{resolver: resolver, timeout: timeout}
+}
+
+// RecordName returns the name of the TXT record to publish for the host.
+func RecordName(host string) string {
+	return RecordPrefix + host
+}
+
+// RecordValue returns the value of the TXT record to publish for the token.
+func RecordValue(token string) string {
+	return ValuePrefix + token
+}
+
+// Verify returns nil if the TXT record of the host contains the token and
+// ErrRecordNotFound if it does not.
+func (v *Verifier) Verify(ctx context.Context, host string, token string) error {
+	const op = "lib.dnsverify.Verify"
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	records, err := v.resolver.LookupTXT(ctx, RecordName(host))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return fmt.Errorf("%s: %w", op, ErrRecordNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	want := RecordValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: %w", op, ErrRecordNotFound)
+}
@@ -0,0 +1,39 @@
+package dnsverify_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"URLshortener/internal/lib/dnsverify"
+)
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestVerify(t *testing.T) {
+	resolver := fakeResolver{
+		"_urlshortener.go.example.com":  {"v=spf1 -all", "urlshortener-verification=token"},
+		"_urlshortener.bad.example.com": {"urlshortener-verification=other"},
+	}
+	v := dnsverify.New(resolver, time.Second)
+
+	require.NoError(t, v.Verify(context.Background(), "go.example.com", "token"))
+
+	err := v.Verify(context.Background(), "bad.example.com", "token")
+	require.True(t, errors.Is(err, dnsverify.ErrRecordNotFound))
+
+	err = v.Verify(context.Background(), "missing.example.com", "token")
+	require.True(t, errors.Is(err, dnsverify.ErrRecordNotFound))
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Namespace prefixes the names of all metrics of the service.
+const Namespace = "url_shortener"
+
+// Outcomes of a redirect.
+const (
+	RedirectFound            = "found"
+	RedirectNotFound         = "not_found"
+	RedirectExpired          = "expired"
+	RedirectPasswordRequired = "password_required"
+	RedirectBlocked          = "blocked"
+	RedirectRateLimited      = "rate_limited"
+	RedirectError            = "error"
+)
+
+// Metrics holds the collectors of the service in its own registry, so only
+// they and the runtime metrics are exposed.
+type Metrics struct {
+	registry      *prometheus.Registry
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	redirects     *prometheus.CounterVec
+	storageErrors *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route pattern, method and status.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by route pattern, method and status.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"route", "method", "status"}),
+		redirects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "redirects_total",
+			Help:      "Redirect requests by outcome.",
+		}, []string{"outcome"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "storage",
+			Name:      "errors_total",
+			Help:      "Failed storage operations by operation name.",
+		}, []string{"op"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.redirects,
+		m.storageErrors,
+	)
+
+	// Исходы редиректов видны с нулем еще до первого запроса
+	for _, outcome := range []string{RedirectFound, RedirectNotFound, RedirectExpired, RedirectPasswordRequired, RedirectBlocked, RedirectRateLimited, RedirectError} {
+		m.redirects.WithLabelValues(outcome)
+	}
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// MustRegister adds collectors built elsewhere, such as DBStats.
+func (m *Metrics) MustRegister(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// ObserveRequest counts a served HTTP request. The route is the pattern of
+// the matched route, not the path, so the number of series stays bounded.
+func (m *Metrics) ObserveRequest(route string, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(route, method, code).Inc()
+	m.duration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) Redirect(outcome string) {
+	m.redirects.WithLabelValues(outcome).Inc()
+}
+
+// StorageError counts a failed storage operation, it fits sql.Storage.OnError.
+func (m *Metrics) StorageError(op string) {
+	m.storageErrors.WithLabelValues(op).Inc()
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New()
+	m.MustRegister(DBStats(func() sql.DBStats {
+		return sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 4, WaitDuration: 1500 * time.Millisecond}
+	}))
+
+	m.ObserveRequest("/{alias}", http.MethodGet, http.StatusFound, 20*time.Millisecond)
+	m.ObserveRequest("/{alias}", http.MethodGet, http.StatusFound, 30*time.Millisecond)
+	m.Redirect(RedirectFound)
+	m.StorageError("storage.sql.ResolveAlias")
+
+	require.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("/{alias}", http.MethodGet, "302")))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.redirects.WithLabelValues(RedirectFound)))
+	require.Equal(t, float64(0), testutil.ToFloat64(m.redirects.WithLabelValues(RedirectExpired)))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.storageErrors.WithLabelValues("storage.sql.ResolveAlias")))
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	body := rr.Body.String()
+	for _, line := range []string{
+		`url_shortener_http_request_duration_seconds_count{method="GET",route="/{alias}",status="302"} 2`,
+		`url_shortener_redirects_total{outcome="not_found"} 0`,
+		`url_shortener_db_open_connections 3`,
+		`url_shortener_db_wait_duration_seconds_total 1.5`,
+	} {
+		require.True(t, strings.Contains(body, line), line)
+	}
+}
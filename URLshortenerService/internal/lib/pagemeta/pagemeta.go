@@ -0,0 +1,186 @@
+package pagemeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// MaxTitleLength and MaxDescriptionLength are the limits, in characters,
+	// of the fetched title and description. Longer values are cut.
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 1000
+)
+
+var (
+	ErrBadStatus = errors.New("page responded with non-2xx status")
+	ErrNotHTML   = errors.New("page is not HTML")
+)
+
+// HTTPClient sends requests for pages. *http.Client implements it,
+// tests use a fake one.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Metadata is what a page says about itself in its <head>.
+type Metadata struct {
+	Title       string
+	Description string
+}
+
+// Fetcher reads the title and description of web pages. OpenGraph tags
+// are preferred over <title> and <meta name="description">.
+type Fetcher struct {
+	client   HTTPClient
+	timeout  time.Duration
+	maxBytes int64
+}
+
+// New returns a Fetcher that gives up on a page after timeout and reads at
+// most maxBytes of it.
+func New(client HTTPClient, timeout time.Duration, maxBytes int64) *Fetcher {
+	return &Fetcher{client: client, timeout: timeout, maxBytes: maxBytes}
+}
+
+// Fetch downloads the page and returns its metadata. Fields the page does
+// not have are left empty.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (Metadata, error) {
+	const op = "lib.pagemeta.Fetch"
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Metadata{}, fmt.Errorf("%s: %w: %d", op, ErrBadStatus, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return Metadata{}, fmt.Errorf("%s: %w: %s", op, ErrNotHTML, mediaType)
+	}
+
+	// Страницы в windows-1251 и других кодировках приводятся к UTF-8
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.maxBytes), contentType)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return Parse(body), nil
+}
+
+// Parse reads metadata from the <head> of an HTML document in UTF-8.
+func Parse(r io.Reader) Metadata {
+	var (
+		title, ogTitle             string
+		description, ogDescription string
+		inTitle                    bool
+	)
+
+	z := html.NewTokenizer(r)
+
+loop:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			break loop
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+				key, content := metaAttrs(z)
+				switch key {
+				case "og:title":
+					ogTitle = content
+				case "og:description":
+					ogDescription = content
+				case "description":
+					description = content
+				}
+			case "body":
+				break loop
+			}
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				break loop
+			}
+		}
+	}
+
+	return Metadata{
+		Title:       clean(firstNonEmpty(ogTitle, title), MaxTitleLength),
+		Description: clean(firstNonEmpty(ogDescription, description), MaxDescriptionLength),
+	}
+}
+
+// metaAttrs returns the name or property of a <meta> tag in lower case and its content.
+func metaAttrs(z *html.Tokenizer) (string, string) {
+	var key, content string
+	for {
+		attr, value, more := z.TagAttr()
+		switch string(attr) {
+		case "name", "property":
+			key = strings.ToLower(string(value))
+		case "content":
+			content = string(value)
+		}
+		if !more {
+			return key, content
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean collapses whitespace and cuts s to at most limit characters.
+func clean(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	return string([]rune(s)[:limit])
+}
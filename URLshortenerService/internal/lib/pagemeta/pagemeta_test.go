@@ -0,0 +1,101 @@
+package pagemeta_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"URLshortener/internal/lib/pagemeta"
+)
+
+type fakeClient struct {
+	status      int
+	contentType string
+	body        string
+}
+
+func (f fakeClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: f.status,
+		Header:     http.Header{"Content-Type": {f.contentType}},
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestFetch(t *testing.T) {
+	testCases := []struct {
+		name   string
+		client fakeClient
+		want   pagemeta.Metadata
+		err    error
+	}{
+		{
+			name: "Title and description",
+			client: fakeClient{status: http.StatusOK, contentType: "text/html; charset=utf-8", body: `<!doctype html>
+				<html><head>
+				<title>  Example
+				Domain </title>
+				<meta name="Description" content="Just an example">
+				</head><body><title>Not this</title></body></html>`},
+			want: pagemeta.Metadata{Title: "Example Domain", Description: "Just an example"},
+		},
+		{
+			name: "OpenGraph preferred",
+			client: fakeClient{status: http.StatusOK, contentType: "text/html", body: `<head>
+				<title>Plain</title>
+				<meta property="og:title" content="Rich">
+				<meta name="description" content="Plain description">
+				<meta property="og:description" content="Rich description"/>`},
+			want: pagemeta.Metadata{Title: "Rich", Description: "Rich description"},
+		},
+		{
+			name: "Windows-1251",
+			client: fakeClient{status: http.StatusOK, contentType: "text/html; charset=windows-1251",
+				body: "<title>\xcf\xf0\xe8\xe2\xe5\xf2</title>"},
+			want: pagemeta.Metadata{Title: "Привет"},
+		},
+		{
+			name:   "No metadata",
+			client: fakeClient{status: http.StatusOK, contentType: "text/html", body: `<p>hello</p>`},
+		},
+		{
+			name:   "Not HTML",
+			client: fakeClient{status: http.StatusOK, contentType: "application/pdf", body: `%PDF`},
+			err:    pagemeta.ErrNotHTML,
+		},
+		{
+			name:   "Bad status",
+			client: fakeClient{status: http.StatusNotFound, contentType: "text/html", body: `<title>Not found</title>`},
+			err:    pagemeta.ErrBadStatus,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			meta, err := pagemeta.New(tc.client, time.Second, 1<<20).Fetch(context.Background(), "https://example.com")
+			if tc.err != nil {
+				require.True(t, errors.Is(err, tc.err), err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, meta)
+		})
+	}
+}
+
+func TestParseCutsLongTitle(t *testing.T) {
+	meta := pagemeta.Parse(strings.NewReader("<title>" + strings.Repeat("я", 300) + "</title>"))
+	require.Equal(t, pagemeta.MaxTitleLength, len([]rune(meta.Title)))
+}
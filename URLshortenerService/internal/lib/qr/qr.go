@@ -0,0 +1,171 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+var ErrInvalidColor = errors.New("invalid color")
+
+// Options describe how a QR code is drawn.
+// Size is the width of the image in pixels, Margin is the quiet zone in modules.
+type Options struct {
+	Size       int
+	Level      qrcode.RecoveryLevel
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+// ParseLevel converts an error correction level L, M, Q or H.
+func ParseLevel(s string) (qrcode.RecoveryLevel, bool) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return qrcode.Low, true
+	case "M":
+		return qrcode.Medium, true
+	case "Q":
+		return qrcode.High, true
+	case "H":
+		return qrcode.Highest, true
+	default:
+		return 0, false
+	}
+}
+
+// ParseColor parses a hex color in the form RGB, RRGGBB or RRGGBBAA with an optional leading #.
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	if len(s) != 8 {
+		return color.RGBA{}, ErrInvalidColor
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, ErrInvalidColor
+	}
+
+	return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// PNG renders content as a square PNG image of opts.Size pixels.
+func PNG(content string, opts Options) ([]byte, error) {
+	const op = "lib.qr.PNG"
+
+	bitmap, err := encode(content, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Модули рисуются целым числом пикселей, иначе сканеры ошибаются.
+	// Остаток размера уходит в поля вокруг кода.
+	total := len(bitmap)
+	scale := opts.Size / total
+	if scale < 1 {
+		scale = 1
+	}
+	size := max(opts.Size, scale*total)
+	offset := (size - scale*total) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{opts.Background, opts.Foreground})
+	for y := 0; y < scale*total; y++ {
+		row := bitmap[y/scale]
+		for x := 0; x < scale*total; x++ {
+			if row[x/scale] {
+				img.SetColorIndex(offset+x, offset+y, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// SVG renders content as a scalable image with opts.Size as its width and height.
+func SVG(content string, opts Options) ([]byte, error) {
+	const op = "lib.qr.SVG"
+
+	bitmap, err := encode(content, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	total := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total,
+	)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" %s/>`, total, total, fillAttrs(opts.Background))
+	fmt.Fprintf(&buf, `<path %s d="`, fillAttrs(opts.Foreground))
+
+	// Соседние тёмные модули строки объединяются в один прямоугольник
+	for y, row := range bitmap {
+		for x := 0; x < total; {
+			if !row[x] {
+				x++
+				continue
+			}
+
+			start := x
+			for x < total && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes(), nil
+}
+
+// encode returns the module matrix of the code including the quiet zone.
+func encode(content string, opts Options) ([][]bool, error) {
+	code, err := qrcode.New(content, opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+
+	modules := code.Bitmap()
+	total := len(modules) + 2*opts.Margin
+
+	bitmap := make([][]bool, total)
+	for y := range bitmap {
+		bitmap[y] = make([]bool, total)
+	}
+	for y, row := range modules {
+		copy(bitmap[y+opts.Margin][opts.Margin:], row)
+	}
+
+	return bitmap, nil
+}
+
+func fillAttrs(c color.RGBA) string {
+	attrs := fmt.Sprintf(`fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		attrs += fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+	}
+	return attrs
+}
@@ -0,0 +1,69 @@
+package qr
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("#f00")
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{R: 0xff, A: 0xff}, c)
+
+	c, err = ParseColor("00ff0080")
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{G: 0xff, A: 0x80}, c)
+
+	_, err = ParseColor("zzzzzz")
+	require.ErrorIs(t, err, ErrInvalidColor)
+
+	_, err = ParseColor("1234")
+	require.ErrorIs(t, err, ErrInvalidColor)
+}
+
+func TestPNG(t *testing.T) {
+	opts := Options{
+		Size:       256,
+		Level:      qrcode.Medium,
+		Margin:     4,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+
+	data, err := PNG("https://svsevs.ru/google", opts)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, 256, img.Bounds().Dx())
+	require.Equal(t, 256, img.Bounds().Dy())
+
+	// угол изображения попадает в поле
+	r, g, b, _ := img.At(0, 0).RGBA()
+	require.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+}
+
+func TestSVG(t *testing.T) {
+	opts := Options{
+		Size:       200,
+		Level:      qrcode.High,
+		Margin:     0,
+		Foreground: color.RGBA{B: 0xff, A: 0xff},
+		Background: color.RGBA{},
+	}
+
+	data, err := SVG("https://svsevs.ru/google", opts)
+	require.NoError(t, err)
+
+	svg := string(data)
+	require.True(t, strings.HasPrefix(svg, "<svg"))
+	require.Contains(t, svg, `width="200"`)
+	require.Contains(t, svg, `fill="#0000ff"`)
+	require.Contains(t, svg, `fill-opacity="0.000"`)
+}
@@ -0,0 +1,90 @@
+package quota
+
+import (
+	"URLshortener/internal/domain/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExceeded is wrapped by errors of Check, their text can be shown to the client.
+var ErrExceeded = errors.New("link quota exceeded")
+
+// Day is the period of the daily quota: the links created in the last Day count.
+const Day = 24 * time.Hour
+
+// Limits are the quotas of a role. Zero means no limit.
+type Limits struct {
+	MaxLinks int
+	MaxDaily int
+}
+
+// Unlimited reports whether the limits allow any number of links.
+func (l Limits) Unlimited() bool {
+	return l.MaxLinks == 0 && l.MaxDaily == 0
+}
+
+// Fit returns an error wrapping ErrExceeded if n more links would exceed the
+// limits, given total links of the user and recent links created within Day.
+func (l Limits) Fit(total, recent, n int) error {
+	if l.MaxLinks > 0 && total+n > l.MaxLinks {
+		return fmt.Errorf("%w: at most %d links", ErrExceeded, l.MaxLinks)
+	}
+	if l.MaxDaily > 0 && recent+n > l.MaxDaily {
+		return fmt.Errorf("%w: at most %d links per day", ErrExceeded, l.MaxDaily)
+	}
+
+	return nil
+}
+
+type LinkCounter interface {
+	CountLinks(ctx context.Context, userID int64, since time.Time) (int, int, error)
+}
+
+// Enforcer checks the quotas of the user's role before links are created.
+// Roles without their own limits get the default ones.
+type Enforcer struct {
+	counter  LinkCounter
+	roles    map[string]Limits
+	fallback Limits
+	now      func() time.Time
+}
+
+func New(counter LinkCounter, roles map[string]Limits, fallback Limits) *Enforcer {
+	return &Enforcer{
+		counter:  counter,
+		roles:    roles,
+		fallback: fallback,
+		now:      time.Now,
+	}
+}
+
+// Limits returns the quotas of the actor's role. Storage enforces them again
+// in the transaction that stores the links, since links created concurrently
+// all pass Check.
+func (e *Enforcer) Limits(actor models.Actor) Limits {
+	limits, ok := e.roles[actor.Role]
+	if !ok {
+		return e.fallback
+	}
+	return limits
+}
+
+// Check returns an error wrapping ErrExceeded if n more links would exceed
+// the total or the daily quota of the actor. The day is the last 24 hours.
+func (e *Enforcer) Check(ctx context.Context, actor models.Actor, n int) error {
+	const op = "lib.quota.Check"
+
+	limits := e.Limits(actor)
+	if limits.Unlimited() {
+		return nil
+	}
+
+	total, recent, err := e.counter.CountLinks(ctx, actor.UserID, e.now().Add(-Day))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return limits.Fit(total, recent, n)
+}
@@ -0,0 +1,72 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"URLshortener/internal/domain/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+type counterFunc func(userID int64, since time.Time) (int, int, error)
+
+func (f counterFunc) CountLinks(_ context.Context, userID int64, since time.Time) (int, int, error) {
+	return f(userID, since)
+}
+
+func TestEnforcer(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	counter := counterFunc(func(userID int64, since time.Time) (int, int, error) {
+		require.Equal(t, int64(1), userID)
+		require.Equal(t, now.Add(-24*time.Hour), since)
+		return 90, 9, nil
+	})
+
+	e := New(counter, map[string]Limits{"admin": {}}, Limits{MaxLinks: 100, MaxDaily: 10})
+	e.now = func() time.Time { return now }
+
+	cases := []struct {
+		name    string
+		actor   models.Actor
+		n       int
+		message string
+	}{
+		{name: "within quota", actor: models.Actor{UserID: 1, Role: "user"}, n: 1},
+		{name: "daily quota", actor: models.Actor{UserID: 1, Role: "user"}, n: 2, message: "link quota exceeded: at most 10 links per day"},
+		{name: "total quota", actor: models.Actor{UserID: 1}, n: 11, message: "link quota exceeded: at most 100 links"},
+		{name: "role without limits", actor: models.Actor{UserID: 1, Role: "admin"}, n: 1000},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := e.Check(context.Background(), tc.actor, tc.n)
+			if tc.message == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrExceeded)
+			require.EqualError(t, err, tc.message)
+		})
+	}
+}
+
+func TestEnforcer_Limits(t *testing.T) {
+	e := New(nil, map[string]Limits{"admin": {}}, Limits{MaxLinks: 100, MaxDaily: 10})
+
+	require.True(t, e.Limits(models.Actor{Role: "admin"}).Unlimited())
+	require.Equal(t, Limits{MaxLinks: 100, MaxDaily: 10}, e.Limits(models.Actor{Role: "user"}))
+}
+
+func TestEnforcer_CounterError(t *testing.T) {
+	e := New(counterFunc(func(int64, time.Time) (int, int, error) {
+		return 0, 0, errors.New("db is down")
+	}), nil, Limits{MaxLinks: 1})
+
+	err := e.Check(context.Background(), models.Actor{UserID: 1}, 1)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrExceeded)
+}
@@ -1,22 +1,44 @@
 package random
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
+	"math/big"
 )
 
+// Base62 is the default alphabet of generated strings.
+const Base62 = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"abcdefghijklmnopqrstuvwxyz" +
+	"0123456789"
+
 // NewRandomString generates random string with given size.
 func NewRandomString(size int) string {
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return String(size, Base62)
+}
 
-	chars := []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
-		"abcdefghijklmnopqrstuvwxyz" +
-		"0123456789")
+// String generates a random string of the alphabet characters using crypto/rand,
+// so every character is uniformly distributed and independent of the call time.
+func String(size int, alphabet string) string {
+	chars := []rune(alphabet)
 
 	b := make([]rune, size)
 	for i := range b {
-		b[i] = chars[rnd.Intn(len(chars))]
+		b[i] = chars[Int(int64(len(chars)))]
 	}
 
 	return string(b)
 }
+
+// Choice returns a random element of the slice using crypto/rand.
+func Choice[T any](items []T) T {
+	return items[Int(int64(len(items)))]
+}
+
+// Int returns a uniformly distributed number in [0, n) using crypto/rand.
+func Int(n int64) int64 {
+	v, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		// crypto/rand не возвращает ошибок начиная с Go 1.24
+		panic(err)
+	}
+	return v.Int64()
+}
@@ -0,0 +1,99 @@
+package screening
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// carrierGradeNAT is the shared address space of RFC 6598, not covered by netip.Addr.IsPrivate.
+var carrierGradeNAT = netip.MustParsePrefix("100.64.0.0/10")
+
+// Resolver looks up the addresses of a host. *net.Resolver implements it.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// PublicAddress rejects destinations whose host is, or resolves to, a
+// private, loopback, link-local or unspecified address. Hosts that cannot be
+// resolved are allowed: nothing can be reached through them anyway.
+func PublicAddress(resolver Resolver, timeout time.Duration) Checker {
+	return CheckerFunc(func(ctx context.Context, u *url.URL) error {
+		host := u.Hostname()
+
+		if addr, err := netip.ParseAddr(host); err == nil {
+			if !IsPublic(addr) {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		addrs, err := resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil
+		}
+
+		for _, ip := range addrs {
+			addr, ok := netip.AddrFromSlice(ip.IP)
+			if ok && !IsPublic(addr) {
+				return ErrPrivateAddress
+			}
+		}
+		return nil
+	})
+}
+
+// IsPublic reports whether the address can be reached from the internet.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!carrierGradeNAT.Contains(addr)
+}
+
+// DialControl refuses connections to addresses that are not public. Set as
+// net.Dialer.Control it protects outgoing requests for user-supplied URLs
+// even if DNS answers change between screening and the request.
+func DialControl(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublic(addr) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// SafeTransport returns a transport that only connects to public addresses
+// and ignores proxy settings.
+func SafeTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout, Control: DialControl}
+
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     30 * time.Second,
+	}
+}
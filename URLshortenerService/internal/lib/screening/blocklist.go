@@ -0,0 +1,140 @@
+package screening
+
+import (
+	"URLshortener/internal/lib/logger/sl"
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Blocklist rejects destinations on the domains listed in a file and on
+// their subdomains. The file has one domain per line, empty lines and lines
+// starting with # are skipped. It is read again when it changes, which is
+// checked at most once per interval.
+type Blocklist struct {
+	log      *slog.Logger
+	path     string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	domains   map[string]bool
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+func NewBlocklist(log *slog.Logger, path string, interval time.Duration) (*Blocklist, error) {
+	const op = "lib.screening.NewBlocklist"
+
+	b := &Blocklist{
+		log:      log.With(slog.String("component", "blocklist"), slog.String("path", path)),
+		path:     path,
+		interval: interval,
+	}
+
+	if err := b.load(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return b, nil
+}
+
+func (b *Blocklist) Check(_ context.Context, u *url.URL) error {
+	b.reloadIfChanged()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	// Проверяем сам хост и все родительские домены
+	host := normalizeHost(u.Hostname())
+	for host != "" {
+		if b.domains[host] {
+			return ErrBlocklisted
+		}
+
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+
+	return nil
+}
+
+// Len returns the number of blocked domains.
+func (b *Blocklist) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.domains)
+}
+
+func (b *Blocklist) reloadIfChanged() {
+	b.mu.Lock()
+	if time.Since(b.checkedAt) < b.interval {
+		b.mu.Unlock()
+		return
+	}
+	b.checkedAt = time.Now()
+	modTime, size := b.modTime, b.size
+	b.mu.Unlock()
+
+	info, err := os.Stat(b.path)
+	if err != nil {
+		b.log.Warn("failed to stat blocklist, keeping the loaded one", sl.Err(err))
+		return
+	}
+
+	if info.ModTime().Equal(modTime) && info.Size() == size {
+		return
+	}
+
+	if err := b.load(); err != nil {
+		b.log.Warn("failed to reload blocklist, keeping the loaded one", sl.Err(err))
+		return
+	}
+
+	b.log.Info("blocklist reloaded", slog.Int("domains", b.Len()))
+}
+
+func (b *Blocklist) load() error {
+	f, err := os.Open(b.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	domains := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[normalizeHost(strings.TrimPrefix(line, "*."))] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.domains = domains
+	b.modTime = info.ModTime()
+	b.size = info.Size()
+	b.checkedAt = time.Now()
+	b.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrRejected is wrapped by every error returned for a destination that is
+// not allowed. Its message can be shown to the user.
+var ErrRejected = errors.New("destination is not allowed")
+
+var (
+	ErrInvalidURL     = fmt.Errorf("%w: invalid url", ErrRejected)
+	ErrScheme         = fmt.Errorf("%w: scheme is not allowed", ErrRejected)
+	ErrPrivateAddress = fmt.Errorf("%w: private or loopback address", ErrRejected)
+	ErrSelfReference  = fmt.Errorf("%w: link to this shortener", ErrRejected)
+	ErrBlocklisted    = fmt.Errorf("%w: domain is blocklisted", ErrRejected)
+)
+
+// Checker inspects a destination. It returns an error wrapping ErrRejected
+// if the destination is not allowed and any other error if it could not
+// check it. An external reputation service can be plugged in as a Checker.
+type Checker interface {
+	Check(ctx context.Context, u *url.URL) error
+}
+
+// CheckerFunc lets an ordinary function be used as a Checker.
+type CheckerFunc func(ctx context.Context, u *url.URL) error
+
+func (f CheckerFunc) Check(ctx context.Context, u *url.URL) error {
+	return f(ctx, u)
+}
+
+// Screener runs a destination through the checkers in order and stops at
+// the first one that rejects it.
+type Screener struct {
+	checkers []Checker
+}
+
+func New(checkers ...Checker) *Screener {
+	return &Screener{checkers: checkers}
+}
+
+// Screen returns nil if all checkers allow the destination.
+func (s *Screener) Screen(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Scheme == "" {
+		return ErrInvalidURL
+	}
+
+	for _, checker := range s.checkers {
+		if err := checker.Check(ctx, u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Schemes allows only destinations with one of the schemes.
+func Schemes(schemes ...string) Checker {
+	allowed := make(map[string]bool, len(schemes))
+	for _, scheme := range schemes {
+		allowed[strings.ToLower(scheme)] = true
+	}
+
+	return CheckerFunc(func(_ context.Context, u *url.URL) error {
+		if !allowed[strings.ToLower(u.Scheme)] {
+			return ErrScheme
+		}
+		if u.Hostname() == "" {
+			return ErrInvalidURL
+		}
+		return nil
+	})
+}
+
+// DomainLookup reports whether the host is a verified custom domain of the service.
+type DomainLookup interface {
+	IsVerifiedDomain(ctx context.Context, host string) (bool, error)
+}
+
+// SelfReference rejects destinations on the hosts of the service itself or
+// on its verified custom domains, since such links would redirect to
+// other short links and could form loops. domains may be nil.
+func SelfReference(hosts []string, domains DomainLookup) Checker {
+	own := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		own[normalizeHost(host)] = true
+	}
+
+	return CheckerFunc(func(ctx context.Context, u *url.URL) error {
+		host := normalizeHost(u.Hostname())
+		if own[host] {
+			return ErrSelfReference
+		}
+
+		if domains == nil {
+			return nil
+		}
+
+		verified, err := domains.IsVerifiedDomain(ctx, host)
+		if err != nil {
+			return err
+		}
+		if verified {
+			return ErrSelfReference
+		}
+		return nil
+	})
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,127 @@
+package screening_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/lib/screening"
+)
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+type fakeDomains map[string]bool
+
+func (f fakeDomains) IsVerifiedDomain(_ context.Context, host string) (bool, error) {
+	return f[host], nil
+}
+
+func TestScreen(t *testing.T) {
+	resolver := fakeResolver{
+		"example.com":  {"93.184.216.34"},
+		"internal.lan": {"10.0.0.5"},
+		"mixed.com":    {"93.184.216.34", "127.0.0.1"},
+	}
+
+	s := screening.New(
+		screening.Schemes("http", "https"),
+		screening.SelfReference([]string{"sho.rt"}, fakeDomains{"go.example.org": true}),
+		screening.PublicAddress(resolver, time.Second),
+	)
+
+	testCases := []struct {
+		url string
+		err error
+	}{
+		{url: "https://example.com/page"},
+		{url: "HTTP://Example.com"},
+		{url: "https://unknown.example.net"},
+		{url: "javascript:alert(1)", err: screening.ErrScheme},
+		{url: "data:text/html;base64,PHNjcmlwdD4=", err: screening.ErrScheme},
+		{url: "ftp://example.com", err: screening.ErrScheme},
+		{url: "example.com", err: screening.ErrInvalidURL},
+		{url: "https://sho.rt/abc", err: screening.ErrSelfReference},
+		{url: "https://SHO.RT./abc", err: screening.ErrSelfReference},
+		{url: "https://go.example.org/abc", err: screening.ErrSelfReference},
+		{url: "http://127.0.0.1:8080/admin", err: screening.ErrPrivateAddress},
+		{url: "http://[::1]/", err: screening.ErrPrivateAddress},
+		{url: "http://169.254.169.254/latest/meta-data", err: screening.ErrPrivateAddress},
+		{url: "http://[::ffff:192.168.1.1]/", err: screening.ErrPrivateAddress},
+		{url: "http://100.64.1.1/", err: screening.ErrPrivateAddress},
+		{url: "http://internal.lan/", err: screening.ErrPrivateAddress},
+		{url: "http://mixed.com/", err: screening.ErrPrivateAddress},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			err := s.Screen(context.Background(), tc.url)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+			require.ErrorIs(t, err, screening.ErrRejected)
+		})
+	}
+}
+
+func TestBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# phishing\nevil.com\n\n*.bad.org\n"), 0o644))
+
+	b, err := screening.NewBlocklist(slogdiscard.NewDiscardLogger(), path, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, b.Len())
+
+	s := screening.New(b)
+
+	require.ErrorIs(t, s.Screen(context.Background(), "https://evil.com/login"), screening.ErrBlocklisted)
+	require.ErrorIs(t, s.Screen(context.Background(), "https://login.EVIL.com"), screening.ErrBlocklisted)
+	require.ErrorIs(t, s.Screen(context.Background(), "https://x.bad.org"), screening.ErrBlocklisted)
+	require.NoError(t, s.Screen(context.Background(), "https://notevil.com"))
+	require.NoError(t, s.Screen(context.Background(), "https://good.org"))
+
+	// Файл перечитывается после изменения
+	require.NoError(t, os.WriteFile(path, []byte("good.org\n"), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	require.ErrorIs(t, s.Screen(context.Background(), "https://good.org"), screening.ErrBlocklisted)
+	require.NoError(t, s.Screen(context.Background(), "https://evil.com"))
+
+	// Пока файл недоступен, остается загруженный список
+	require.NoError(t, os.Remove(path))
+	require.ErrorIs(t, s.Screen(context.Background(), "https://good.org"), screening.ErrBlocklisted)
+
+	_, err = screening.NewBlocklist(slogdiscard.NewDiscardLogger(), path, 0)
+	require.Error(t, err)
+}
+
+func TestDialControl(t *testing.T) {
+	require.ErrorIs(t, screening.DialControl("tcp", "127.0.0.1:80", nil), screening.ErrPrivateAddress)
+	require.ErrorIs(t, screening.DialControl("tcp", "[fd00::1]:443", nil), screening.ErrPrivateAddress)
+	require.NoError(t, screening.DialControl("tcp", "93.184.216.34:443", nil))
+
+	err := screening.DialControl("tcp", "not an address", nil)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, screening.ErrRejected))
+}
@@ -0,0 +1,170 @@
+package migrator
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"io/fs"
+	"os"
+)
+
+// Migration is a migration found in the source and whether it is applied.
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Migrator applies the migrations of the driver from the embedded directory
+// <driver dir>/*.sql. The versions are kept in the schema_migrations table,
+// the same one the migrate CLI uses, so databases migrated by it can be
+// managed here as well.
+//
+// On postgres every run holds pg_advisory_lock, so replicas starting at the
+// same time apply the migrations once. SQLite is locked within the process
+// only, a database file must not be shared by several replicas.
+//
+// The package is shared by the services: URLshortenerService/internal/migrator
+// and authService/internal/migrator are the same file and are changed together.
+type Migrator struct {
+	m      *migrate.Migrate
+	db     *sql.DB
+	source source.Driver
+}
+
+// New opens its own connection to the database, Close releases it.
+func New(driver string, connString string, migrations fs.FS) (*Migrator, error) {
+	const op = "migrator.New"
+
+	dir, err := sourceDir(driver)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	src, err := iofs.New(migrations, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	db, err := sql.Open(driver, connString)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var target database.Driver
+	switch driver {
+	case "postgres":
+		target, err = postgres.WithInstance(db, &postgres.Config{})
+	case "sqlite3":
+		target, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driver, target)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{m: m, db: db, source: src}, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	const op = "migrator.Up"
+
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Down rolls back the last steps migrations.
+func (m *Migrator) Down(steps int) error {
+	const op = "migrator.Down"
+
+	if steps < 1 {
+		return fmt.Errorf("%s: steps must be positive, got %d", op, steps)
+	}
+
+	if err := m.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Version returns the last applied migration, 0 if there is none. A dirty
+// version failed halfway and must be fixed by hand.
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	const op = "migrator.Version"
+
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, dirty, nil
+}
+
+// Status lists the migrations of the source in order.
+func (m *Migrator) Status() ([]Migration, error) {
+	const op = "migrator.Status"
+
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var migrations []Migration
+
+	version, err := m.source.First()
+	for err == nil {
+		name := ""
+		if r, ident, readErr := m.source.ReadUp(version); readErr == nil {
+			r.Close()
+			name = ident
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, Applied: version <= current})
+
+		version, err = m.source.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return migrations, nil
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	// postgres не закрывает переданный ему *sql.DB
+	closeErr := m.db.Close()
+
+	return errors.Join(sourceErr, dbErr, closeErr)
+}
+
+func sourceDir(driver string) (string, error) {
+	switch driver {
+	case "postgres":
+		return "postgres", nil
+	case "sqlite3":
+		return "sqlite", nil
+	default:
+		return "", fmt.Errorf("unsupported driver %q", driver)
+	}
+}
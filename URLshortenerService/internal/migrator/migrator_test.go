@@ -0,0 +1,59 @@
+package migrator
+
+import (
+	"URLshortener/migrations"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigratorSQLite(t *testing.T) {
+	m, err := New("sqlite3", filepath.Join(t.TempDir(), "url.db"), migrations.FS)
+	require.NoError(t, err)
+	t.Cleanup(func() { m.Close() })
+
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	require.Zero(t, version)
+	require.False(t, dirty)
+
+	require.NoError(t, m.Up())
+	// повторный запуск ничего не меняет
+	require.NoError(t, m.Up())
+
+	status, err := m.Status()
+	require.NoError(t, err)
+	require.NotEmpty(t, status)
+
+	last := status[len(status)-1]
+	require.Equal(t, "init", status[0].Name)
+
+	version, _, err = m.Version()
+	require.NoError(t, err)
+	require.Equal(t, last.Version, version)
+	for _, migration := range status {
+		require.True(t, migration.Applied, migration.Name)
+	}
+
+	require.NoError(t, m.Down(1))
+
+	status, err = m.Status()
+	require.NoError(t, err)
+	require.False(t, status[len(status)-1].Applied)
+	require.True(t, status[len(status)-2].Applied)
+
+	// все down миграции проходят до пустой базы
+	require.NoError(t, m.Down(len(status)-1))
+
+	version, _, err = m.Version()
+	require.NoError(t, err)
+	require.Zero(t, version)
+
+	require.NoError(t, m.Up())
+}
+
+func TestMigratorUnsupportedDriver(t *testing.T) {
+	_, err := New("mysql", "", migrations.FS)
+	require.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package aliascache
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/logger/sl"
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Entry is the result of resolving an alias on a host. Found is false for
+// aliases that do not exist, so repeated misses do not reach the database either.
+type Entry struct {
+	Note      models.AliasNote
+	Found     bool
+	ExpiresAt time.Time
+}
+
+// Backend keeps the entries. Backends do not return entries past their ExpiresAt.
+//
+// Every invalidation changes the epoch of the backend. Set stores the entry
+// only if the epoch is still the one read by Epoch before the lookup, in the
+// same step as the comparison: an entry read before an invalidation is never
+// stored after it. Invalidate drops the entries of the aliases on all hosts,
+// InvalidateHost drops all entries of the host.
+type Backend interface {
+	Get(ctx context.Context, alias string, host string) (Entry, bool, error)
+	Epoch(ctx context.Context) (uint64, error)
+	Set(ctx context.Context, alias string, host string, entry Entry, epoch uint64) error
+	Invalidate(ctx context.Context, aliases []string) error
+	InvalidateHost(ctx context.Context, host string) error
+}
+
+type AliasResolver interface {
+	ResolveAlias(ctx context.Context, host string, alias string) (models.AliasNote, error)
+	ConsumeClick(ctx context.Context, id int64) error
+}
+
+// Stats are the counters of the cache since the start.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	// Errors counts failed calls to the backend, the lookup goes to the database then
+	Errors uint64
+}
+
+// HitRate returns the share of lookups answered by the cache.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Resolver is a read-through cache in front of alias resolution. Click
+// limits are always checked in the database.
+type Resolver struct {
+	log         *slog.Logger
+	source      AliasResolver
+	backend     Backend
+	ttl         time.Duration
+	negativeTTL time.Duration
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	errors      atomic.Uint64
+	now         func() time.Time
+}
+
+func New(log *slog.Logger, source AliasResolver, backend Backend, ttl time.Duration, negativeTTL time.Duration) *Resolver {
+	return &Resolver{
+		log:         log,
+		source:      source,
+		backend:     backend,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		now:         time.Now,
+	}
+}
+
+func (r *Resolver) ResolveAlias(ctx context.Context, host string, alias string) (models.AliasNote, error) {
+	const op = "storage.aliascache.ResolveAlias"
+
+	entry, ok, err := r.backend.Get(ctx, alias, host)
+	if err != nil {
+		r.errors.Add(1)
+		r.log.Warn("failed to read alias cache", slog.String("op", op), sl.Err(err))
+	}
+	if ok {
+		r.hits.Add(1)
+		if !entry.Found {
+			return models.AliasNote{}, storage.ErrAliasNotFound
+		}
+		return entry.Note, nil
+	}
+
+	r.misses.Add(1)
+
+	// Без эпохи результат не кешируется: его нельзя сверить с инвалидациями
+	epoch, err := r.backend.Epoch(ctx)
+	cacheable := err == nil
+	if err != nil {
+		r.errors.Add(1)
+		r.log.Warn("failed to read alias cache epoch", slog.String("op", op), sl.Err(err))
+	}
+
+	note, err := r.source.ResolveAlias(ctx, host, alias)
+	switch {
+	case errors.Is(err, storage.ErrAliasNotFound):
+		entry = Entry{ExpiresAt: r.now().Add(r.negativeTTL)}
+	case err != nil:
+		return models.AliasNote{}, err
+	default:
+		entry = Entry{Note: note, Found: true, ExpiresAt: r.now().Add(r.ttl)}
+	}
+
+	if cacheable {
+		if err := r.backend.Set(ctx, alias, host, entry, epoch); err != nil {
+			r.errors.Add(1)
+			r.log.Warn("failed to write alias cache", slog.String("op", op), sl.Err(err))
+		}
+	}
+
+	return note, err
+}
+
+func (r *Resolver) ConsumeClick(ctx context.Context, id int64) error {
+	return r.source.ConsumeClick(ctx, id)
+}
+
+// Invalidate drops the aliases from the cache. It suits Storage.OnAliasChange.
+func (r *Resolver) Invalidate(aliases []string) {
+	const op = "storage.aliascache.Invalidate"
+
+	if err := r.backend.Invalidate(context.Background(), aliases); err != nil {
+		r.errors.Add(1)
+		r.log.Error("failed to invalidate alias cache", slog.String("op", op), slog.Any("aliases", aliases), sl.Err(err))
+	}
+}
+
+// InvalidateHost drops all entries of the host, which resolves other links
+// once it becomes or stops being a verified domain. It suits Storage.OnHostChange.
+func (r *Resolver) InvalidateHost(host string) {
+	const op = "storage.aliascache.InvalidateHost"
+
+	if err := r.backend.InvalidateHost(context.Background(), host); err != nil {
+		r.errors.Add(1)
+		r.log.Error("failed to invalidate alias cache", slog.String("op", op), slog.String("host", host), sl.Err(err))
+	}
+}
+
+func (r *Resolver) Stats() Stats {
+	return Stats{
+		Hits:   r.hits.Load(),
+		Misses: r.misses.Load(),
+		Errors: r.errors.Load(),
+	}
+}
+
+// ReportStats logs the counters every interval until stop is called.
+func (r *Resolver) ReportStats(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				stats := r.Stats()
+				r.log.Info("alias cache stats",
+					slog.Uint64("hits", stats.Hits),
+					slog.Uint64("misses", stats.Misses),
+					slog.Uint64("errors", stats.Errors),
+					slog.Float64("hit_rate", stats.HitRate()),
+				)
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,199 @@
+package aliascache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/logger/handlers/slogdiscard"
+	"URLshortener/internal/storage"
+)
+
+type fakeSource struct {
+	notes map[string]models.AliasNote
+	calls int
+	err   error
+}
+
+func (s *fakeSource) ResolveAlias(_ context.Context, host string, alias string) (models.AliasNote, error) {
+	s.calls++
+	if s.err != nil {
+		return models.AliasNote{}, s.err
+	}
+	note, ok := s.notes[host+"/"+alias]
+	if !ok {
+		return models.AliasNote{}, storage.ErrAliasNotFound
+	}
+	return note, nil
+}
+
+func (s *fakeSource) ConsumeClick(context.Context, int64) error {
+	return nil
+}
+
+type failingBackend struct{}
+
+func (failingBackend) Get(context.Context, string, string) (Entry, bool, error) {
+	return Entry{}, false, errors.New("connection refused")
+}
+
+func (failingBackend) Epoch(context.Context) (uint64, error) {
+	return 0, errors.New("connection refused")
+}
+
+func (failingBackend) Set(context.Context, string, string, Entry, uint64) error {
+	return errors.New("connection refused")
+}
+
+func (failingBackend) Invalidate(context.Context, []string) error {
+	return errors.New("connection refused")
+}
+
+func (failingBackend) InvalidateHost(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func TestResolver(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	source := &fakeSource{notes: map[string]models.AliasNote{
+		"sho.rt/google":  {ID: 1, Url: "https://google.com", Alias: "google"},
+		"my.site/google": {ID: 2, Url: "https://google.ru", Alias: "google"},
+	}}
+
+	backend := NewMemory(100, time.Minute)
+	backend.now = func() time.Time { return now }
+
+	r := New(slogdiscard.NewDiscardLogger(), source, backend, time.Minute, 10*time.Second)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		note, err := r.ResolveAlias(context.Background(), "sho.rt", "google")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), note.ID)
+	}
+	require.Equal(t, 1, source.calls)
+
+	// хосты кешируются раздельно
+	note, err := r.ResolveAlias(context.Background(), "my.site", "google")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), note.ID)
+	require.Equal(t, 2, source.calls)
+
+	// промахи тоже кешируются, но на меньший срок
+	for i := 0; i < 2; i++ {
+		_, err = r.ResolveAlias(context.Background(), "sho.rt", "missing")
+		require.ErrorIs(t, err, storage.ErrAliasNotFound)
+	}
+	require.Equal(t, 3, source.calls)
+
+	now = now.Add(15 * time.Second)
+
+	_, err = r.ResolveAlias(context.Background(), "sho.rt", "missing")
+	require.ErrorIs(t, err, storage.ErrAliasNotFound)
+	require.Equal(t, 4, source.calls)
+
+	_, err = r.ResolveAlias(context.Background(), "sho.rt", "google")
+	require.NoError(t, err)
+	require.Equal(t, 4, source.calls)
+
+	// изменение алиаса сбрасывает его на всех хостах
+	source.notes["sho.rt/google"] = models.AliasNote{ID: 1, Url: "https://google.de", Alias: "google"}
+	r.Invalidate([]string{"google"})
+
+	note, err = r.ResolveAlias(context.Background(), "sho.rt", "google")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.de", note.Url)
+	_, err = r.ResolveAlias(context.Background(), "my.site", "google")
+	require.NoError(t, err)
+	require.Equal(t, 6, source.calls)
+
+	require.Equal(t, Stats{Hits: 4, Misses: 6}, r.Stats())
+}
+
+func TestResolver_SourceError(t *testing.T) {
+	source := &fakeSource{err: errors.New("db is down")}
+
+	r := New(slogdiscard.NewDiscardLogger(), source, NewMemory(100, time.Minute), time.Minute, time.Second)
+
+	for i := 0; i < 2; i++ {
+		_, err := r.ResolveAlias(context.Background(), "sho.rt", "google")
+		require.ErrorIs(t, err, source.err)
+	}
+
+	// ошибки базы не кешируются
+	require.Equal(t, 2, source.calls)
+}
+
+func TestResolver_BackendError(t *testing.T) {
+	source := &fakeSource{notes: map[string]models.AliasNote{
+		"sho.rt/google": {ID: 1, Url: "https://google.com", Alias: "google"},
+	}}
+
+	r := New(slogdiscard.NewDiscardLogger(), source, failingBackend{}, time.Minute, time.Second)
+
+	note, err := r.ResolveAlias(context.Background(), "sho.rt", "google")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), note.ID)
+
+	r.Invalidate([]string{"google"})
+
+	require.Equal(t, Stats{Misses: 1, Errors: 3}, r.Stats())
+}
+
+func TestResolver_InvalidateHost(t *testing.T) {
+	source := &fakeSource{notes: map[string]models.AliasNote{
+		"my.site/google": {ID: 1, Url: "https://google.com", Alias: "google"},
+		"sho.rt/google":  {ID: 1, Url: "https://google.com", Alias: "google"},
+	}}
+
+	r := New(slogdiscard.NewDiscardLogger(), source, NewMemory(100, time.Minute), time.Minute, time.Minute)
+
+	for _, host := range []string{"my.site", "sho.rt"} {
+		_, err := r.ResolveAlias(context.Background(), host, "google")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, source.calls)
+
+	// домен подтвержден, на хосте теперь ссылки домена
+	source.notes["my.site/google"] = models.AliasNote{ID: 2, Url: "https://google.ru", Alias: "google"}
+	r.InvalidateHost("my.site")
+
+	note, err := r.ResolveAlias(context.Background(), "my.site", "google")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), note.ID)
+
+	// другие хосты остаются в кеше
+	_, err = r.ResolveAlias(context.Background(), "sho.rt", "google")
+	require.NoError(t, err)
+	require.Equal(t, 3, source.calls)
+}
+
+func TestMemory_SetAfterInvalidate(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemory(100, time.Minute)
+
+	entry := Entry{Note: models.AliasNote{ID: 1, Alias: "google"}, Found: true, ExpiresAt: time.Now().Add(time.Minute)}
+
+	// поиск начался до инвалидации, его результат не сохраняется
+	epoch, err := backend.Epoch(ctx)
+	require.NoError(t, err)
+	require.NoError(t, backend.Invalidate(ctx, []string{"google"}))
+	require.NoError(t, backend.Set(ctx, "google", "sho.rt", entry, epoch))
+
+	_, ok, err := backend.Get(ctx, "google", "sho.rt")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	epoch, err = backend.Epoch(ctx)
+	require.NoError(t, err)
+	require.NoError(t, backend.Set(ctx, "google", "sho.rt", entry, epoch))
+
+	_, ok, err = backend.Get(ctx, "google", "sho.rt")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
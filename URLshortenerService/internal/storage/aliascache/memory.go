@@ -0,0 +1,116 @@
+package aliascache
+
+import (
+	"URLshortener/internal/lib/cache"
+	"context"
+	"sync"
+	"time"
+)
+
+// maxHostsPerAlias bounds the entries of one alias, the host comes from the request.
+const maxHostsPerAlias = 16
+
+type hostEntries struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// Memory keeps the entries in an LRU of the process. Every instance of the
+// service has its own, so it only sees invalidations made by this instance.
+type Memory struct {
+	lru *cache.LRU[string, *hostEntries]
+	ttl time.Duration
+	now func() time.Time
+
+	// mu orders Set against invalidations, Set holds it for reading
+	mu    sync.RWMutex
+	epoch uint64
+}
+
+// NewMemory creates a backend holding up to capacity aliases. Aliases are
+// dropped ttl after they were first cached, which should be the longest TTL of entries.
+func NewMemory(capacity int, ttl time.Duration) *Memory {
+	return &Memory{
+		lru: cache.New[string, *hostEntries](capacity, ttl),
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+func (m *Memory) Get(_ context.Context, alias string, host string) (Entry, bool, error) {
+	hosts, ok := m.lru.Get(alias)
+	if !ok {
+		return Entry{}, false, nil
+	}
+
+	hosts.mu.Lock()
+	defer hosts.mu.Unlock()
+
+	entry, ok := hosts.entries[host]
+	if !ok {
+		return Entry{}, false, nil
+	}
+
+	if !m.now().Before(entry.ExpiresAt) {
+		delete(hosts.entries, host)
+		return Entry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+func (m *Memory) Epoch(context.Context) (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.epoch, nil
+}
+
+func (m *Memory) Set(_ context.Context, alias string, host string, entry Entry, epoch uint64) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.epoch != epoch {
+		return nil
+	}
+
+	hosts, ok := m.lru.Get(alias)
+	if !ok {
+		hosts = &hostEntries{entries: make(map[string]Entry)}
+		m.lru.Set(alias, hosts)
+	}
+
+	hosts.mu.Lock()
+	defer hosts.mu.Unlock()
+
+	if _, ok := hosts.entries[host]; !ok && len(hosts.entries) >= maxHostsPerAlias {
+		clear(hosts.entries)
+	}
+	hosts.entries[host] = entry
+
+	return nil
+}
+
+func (m *Memory) Invalidate(_ context.Context, aliases []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.epoch++
+	for _, alias := range aliases {
+		m.lru.Delete(alias)
+	}
+	return nil
+}
+
+func (m *Memory) InvalidateHost(_ context.Context, host string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.epoch++
+	m.lru.Range(func(_ string, hosts *hostEntries) {
+		hosts.mu.Lock()
+		delete(hosts.entries, host)
+		hosts.mu.Unlock()
+	})
+	return nil
+}
@@ -0,0 +1,158 @@
+package aliascache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// scanCount is the number of keys asked for per SCAN call.
+const scanCount = 1000
+
+// setScript stores the entry only if the epoch has not changed since the
+// lookup started. The script runs atomically, so an invalidation made by any
+// instance cannot land between the comparison and the write.
+var setScript = redis.NewScript(`
+if (redis.call('GET', KEYS[1]) or '0') ~= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[2], ARGV[2], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+return 1
+`)
+
+// Redis keeps the entries in a Redis-compatible server shared by all
+// instances. Every alias is a hash of entries by host. The epoch is shared
+// too, under a key no alias can have.
+type Redis struct {
+	client   *redis.Client
+	prefix   string
+	epochKey string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewRedis creates a backend storing aliases under keys with the prefix.
+// A key lives ttl after the last write, which should be the longest TTL of entries.
+func NewRedis(client *redis.Client, prefix string, ttl time.Duration) *Redis {
+	return &Redis{
+		client:   client,
+		prefix:   prefix,
+		epochKey: prefix + ":epoch",
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+func (c *Redis) Get(ctx context.Context, alias string, host string) (Entry, bool, error) {
+	const op = "storage.aliascache.Redis.Get"
+
+	data, err := c.client.HGet(ctx, c.prefix+alias, host).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var entry Entry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return Entry{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !c.now().Before(entry.ExpiresAt) {
+		return Entry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+func (c *Redis) Epoch(ctx context.Context) (uint64, error) {
+	const op = "storage.aliascache.Redis.Epoch"
+
+	epoch, err := c.client.Get(ctx, c.epochKey).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return epoch, nil
+}
+
+func (c *Redis) Set(ctx context.Context, alias string, host string, entry Entry, epoch uint64) error {
+	const op = "storage.aliascache.Redis.Set"
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys := []string{c.epochKey, c.prefix + alias}
+	err := setScript.Run(ctx, c.client, keys, strconv.FormatUint(epoch, 10), host, buf.Bytes(), c.ttl.Milliseconds()).Err()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (c *Redis) Invalidate(ctx context.Context, aliases []string) error {
+	const op = "storage.aliascache.Redis.Invalidate"
+
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		keys = append(keys, c.prefix+alias)
+	}
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, c.epochKey)
+		pipe.Del(ctx, keys...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// InvalidateHost walks all alias keys, domain changes are rare.
+func (c *Redis) InvalidateHost(ctx context.Context, host string) error {
+	const op = "storage.aliascache.Redis.InvalidateHost"
+
+	// Эпоха меняется до обхода, записи начатых раньше поисков уже не сохранятся
+	if err := c.client.Incr(ctx, c.epochKey).Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", scanCount).Iterator()
+	pipe := c.client.Pipeline()
+	for iter.Next(ctx) {
+		if iter.Val() == c.epochKey {
+			continue
+		}
+		pipe.HDel(ctx, iter.Val(), host)
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if pipe.Len() > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+)
+
+type rowsQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// OnAliasChange sets the function called with the aliases that may resolve
+// differently after a write, e.g. to drop them from a cache. It is called
+// after the change is committed. Set it before the storage is used.
+//
+// Links expiring or purged from the trash are not reported. Domains getting
+// verified or deleted are reported to OnHostChange.
+func (s *Storage) OnAliasChange(fn func(aliases []string)) {
+	s.aliasChanged = fn
+}
+
+// OnHostChange sets the function called with the host of a domain that was
+// verified or deleted: every alias on the host may resolve differently then.
+// It is called after the change is committed. Set it before the storage is used.
+func (s *Storage) OnHostChange(fn func(host string)) {
+	s.hostChanged = fn
+}
+
+func (s *Storage) notifyAliases(aliases []string) {
+	if s.aliasChanged != nil && len(aliases) > 0 {
+		s.aliasChanged(aliases)
+	}
+}
+
+func (s *Storage) notifyHost(host string) {
+	if s.hostChanged != nil {
+		s.hostChanged(host)
+	}
+}
+
+// linkAliases returns the aliases of the links matching the condition and
+// the old aliases still leading to them.
+func (s *Storage) linkAliases(ctx context.Context, q rowsQuerier, cond string, args ...any) ([]string, error) {
+	if s.aliasChanged == nil {
+		return nil, nil
+	}
+
+	rows, err := q.QueryContext(ctx, s.ConvertQuery(`
+		SELECT alias FROM url WHERE `+cond+`
+		UNION
+		SELECT alias FROM alias_redirects WHERE url_id IN (SELECT id FROM url WHERE `+cond+`)`),
+		append(args, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
@@ -0,0 +1,204 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const bucketLayout = "2006-01-02T15:04:05Z"
+
+// SaveClicks writes a batch of click events in one transaction.
+func (s *Storage) SaveClicks(ctx context.Context, clicks []models.Click) error {
+	const op = "storage.sql.SaveClicks"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(s.ConvertQuery(`
+		INSERT INTO clicks(url_id, alias, clicked_at, referrer, user_agent, ip, request_id)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer stmt.Close()
+
+	for _, c := range clicks {
+		_, err := stmt.ExecContext(ctx, c.URLID, c.Alias, c.ClickedAt.UTC(), c.Referrer, c.UserAgent, c.IP, c.RequestID)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// GetLinkStats returns click statistics of the user's link for [from, to).
+// The link is looked up on the custom domain or, if domain is empty, among links without one.
+func (s *Storage) GetLinkStats(ctx context.Context, domain string, alias string, userID int64, bucket string, from, to time.Time) (models.Stats, error) {
+	const op = "storage.sql.GetLinkStats"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`SELECT id FROM url WHERE alias = ? AND ` + domainCondition + ` AND user_id = ? AND deleted_at IS NULL`)
+
+	var urlID int64
+	err := s.db.QueryRowContext(ctx, query, alias, domain, userID).Scan(&urlID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return models.Stats{}, storage.ErrAliasNotFound
+	case err != nil:
+		return models.Stats{}, s.fail(ctx, op, err)
+	}
+
+	stats, err := s.clickStats(ctx, `c.url_id = ?`, urlID, bucket, from, to)
+	if err != nil {
+		return models.Stats{}, s.fail(ctx, op, err)
+	}
+
+	return stats, nil
+}
+
+// GetUserStats returns click statistics of all the user's links for [from, to).
+func (s *Storage) GetUserStats(ctx context.Context, userID int64, bucket string, from, to time.Time) (models.Stats, error) {
+	const op = "storage.sql.GetUserStats"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	const cond = `c.url_id IN (SELECT id FROM url WHERE user_id = ? AND deleted_at IS NULL)`
+
+	stats, err := s.clickStats(ctx, cond, userID, bucket, from, to)
+	if err != nil {
+		return models.Stats{}, s.fail(ctx, op, err)
+	}
+
+	query := s.ConvertQuery(`
+		SELECT u.id, u.alias, COUNT(c.id)
+		FROM url u
+		LEFT JOIN clicks c ON c.url_id = u.id AND c.clicked_at >= ? AND c.clicked_at < ?
+		WHERE u.user_id = ? AND u.deleted_at IS NULL
+		GROUP BY u.id, u.alias
+		ORDER BY COUNT(c.id) DESC, u.id`)
+
+	rows, err := s.db.QueryContext(ctx, query, from.UTC(), to.UTC(), userID)
+	if err != nil {
+		return models.Stats{}, s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	stats.Links = []models.LinkClicks{}
+	for rows.Next() {
+		var link models.LinkClicks
+		if err := rows.Scan(&link.ID, &link.Alias, &link.Clicks); err != nil {
+			return models.Stats{}, s.fail(ctx, op, err)
+		}
+		stats.Links = append(stats.Links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.Stats{}, s.fail(ctx, op, err)
+	}
+
+	return stats, nil
+}
+
+// clickStats counts clicks matching cond (with a single parameter arg) and groups them into buckets.
+func (s *Storage) clickStats(ctx context.Context, cond string, arg any, bucket string, from, to time.Time) (models.Stats, error) {
+	bucketExpr, err := s.timeBucket(bucket, "c.clicked_at")
+	if err != nil {
+		return models.Stats{}, err
+	}
+
+	const visitor = `c.ip || '|' || c.user_agent`
+
+	stats := models.Stats{Series: []models.StatsPoint{}}
+
+	totalQuery := s.ConvertQuery(`
+		SELECT COUNT(*), COUNT(DISTINCT ` + visitor + `)
+		FROM clicks c
+		WHERE ` + cond + ` AND c.clicked_at >= ? AND c.clicked_at < ?`)
+
+	err = s.db.QueryRowContext(ctx, totalQuery, arg, from.UTC(), to.UTC()).Scan(&stats.TotalClicks, &stats.UniqueVisitors)
+	if err != nil {
+		return models.Stats{}, err
+	}
+
+	seriesQuery := s.ConvertQuery(`
+		SELECT ` + bucketExpr + ` AS bucket, COUNT(*), COUNT(DISTINCT ` + visitor + `)
+		FROM clicks c
+		WHERE ` + cond + ` AND c.clicked_at >= ? AND c.clicked_at < ?
+		GROUP BY bucket
+		ORDER BY bucket`)
+
+	rows, err := s.db.QueryContext(ctx, seriesQuery, arg, from.UTC(), to.UTC())
+	if err != nil {
+		return models.Stats{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bucketStr string
+			point     models.StatsPoint
+		)
+		if err := rows.Scan(&bucketStr, &point.Clicks, &point.UniqueVisitors); err != nil {
+			return models.Stats{}, err
+		}
+
+		point.Time, err = time.Parse(bucketLayout, bucketStr)
+		if err != nil {
+			return models.Stats{}, err
+		}
+
+		stats.Series = append(stats.Series, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.Stats{}, err
+	}
+
+	return stats, nil
+}
+
+// timeBucket returns an SQL expression truncating column to the bucket start
+// formatted as bucketLayout. Week buckets start on Monday.
+func (s *Storage) timeBucket(bucket string, column string) (string, error) {
+	switch s.driver {
+	case "postgres":
+		switch bucket {
+		case "hour", "day", "week":
+			return fmt.Sprintf(`to_char(date_trunc('%s', %s), 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`, bucket, column), nil
+		}
+	default:
+		switch bucket {
+		case "hour":
+			return fmt.Sprintf(`strftime('%%Y-%%m-%%dT%%H:00:00Z', %s)`, column), nil
+		case "day":
+			return fmt.Sprintf(`strftime('%%Y-%%m-%%dT00:00:00Z', %s)`, column), nil
+		case "week":
+			return fmt.Sprintf(`strftime('%%Y-%%m-%%dT00:00:00Z', %s, 'weekday 0', '-6 days')`, column), nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown time bucket %q", bucket)
+}
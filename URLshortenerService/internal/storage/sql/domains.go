@@ -0,0 +1,250 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const domainColumns = `id, host, token, verified_at, created_at`
+
+func scanDomain(row rowScanner) (models.Domain, error) {
+	var domain models.Domain
+	err := row.Scan(
+		&domain.ID,
+		&domain.Host,
+		&domain.Token,
+		&domain.VerifiedAt,
+		&domain.CreatedAt,
+	)
+	domain.Verified = domain.VerifiedAt != nil
+	return domain, err
+}
+
+// AddDomain registers an unverified custom domain of the user. Several users
+// may claim the same host, the first one to verify it keeps the domain.
+// It returns storage.ErrDomainExist if the user already claimed the host or
+// the host is verified by someone.
+func (s *Storage) AddDomain(ctx context.Context, userID int64, host string, token string) (models.Domain, error) {
+	const op = "storage.sql.AddDomain"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Domain{}, s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	var verified bool
+	err = tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT EXISTS(SELECT 1 FROM domains WHERE host = ? AND verified_at IS NOT NULL)`), host).
+		Scan(&verified)
+	if err != nil {
+		return models.Domain{}, s.fail(ctx, op, err)
+	}
+
+	if verified {
+		return models.Domain{}, fmt.Errorf("%s: %w", op, storage.ErrDomainExist)
+	}
+
+	query := s.ConvertQuery(`
+		INSERT INTO domains(user_id, host, token, created_at)
+		VALUES(?, ?, ?, ?)
+		RETURNING ` + domainColumns)
+
+	domain, err := scanDomain(tx.QueryRowContext(ctx, query, userID, host, token, time.Now().UTC().Truncate(time.Microsecond)))
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return models.Domain{}, fmt.Errorf("%s: %w", op, storage.ErrDomainExist)
+		}
+		return models.Domain{}, s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Domain{}, s.fail(ctx, op, err)
+	}
+
+	return domain, nil
+}
+
+// ListDomains returns the custom domains of the user, oldest first.
+func (s *Storage) ListDomains(ctx context.Context, userID int64) ([]models.Domain, error) {
+	const op = "storage.sql.ListDomains"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, s.ConvertQuery(`SELECT `+domainColumns+` FROM domains WHERE user_id = ? ORDER BY id`), userID)
+	if err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	domains := []models.Domain{}
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, s.fail(ctx, op, err)
+		}
+		domains = append(domains, domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+
+	return domains, nil
+}
+
+func (s *Storage) GetDomain(ctx context.Context, id int64, userID int64) (models.Domain, error) {
+	const op = "storage.sql.GetDomain"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`SELECT ` + domainColumns + ` FROM domains WHERE id = ? AND user_id = ?`)
+
+	domain, err := scanDomain(s.db.QueryRowContext(ctx, query, id, userID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return models.Domain{}, fmt.Errorf("%s: %w", op, storage.ErrDomainNotFound)
+	case err != nil:
+		return models.Domain{}, s.fail(ctx, op, err)
+	}
+
+	return domain, nil
+}
+
+// IsVerifiedDomain reports whether the host is a verified custom domain of any user.
+func (s *Storage) IsVerifiedDomain(ctx context.Context, host string) (bool, error) {
+	const op = "storage.sql.IsVerifiedDomain"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`SELECT EXISTS(SELECT 1 FROM domains WHERE host = ? AND verified_at IS NOT NULL)`)
+
+	var verified bool
+	if err := s.db.QueryRowContext(ctx, query, host).Scan(&verified); err != nil {
+		return false, s.fail(ctx, op, err)
+	}
+
+	return verified, nil
+}
+
+// MarkDomainVerified records that the ownership of the user's domain was
+// confirmed and drops the claims of other users on the host. It returns
+// storage.ErrDomainExist if another user has verified the host first.
+func (s *Storage) MarkDomainVerified(ctx context.Context, id int64, userID int64, at time.Time) error {
+	const op = "storage.sql.MarkDomainVerified"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	query := s.ConvertQuery(`UPDATE domains SET verified_at = ? WHERE id = ? AND user_id = ? RETURNING host`)
+
+	var host string
+	err = tx.QueryRowContext(ctx, query, at.UTC().Truncate(time.Microsecond), id, userID).Scan(&host)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%s: %w", op, storage.ErrDomainNotFound)
+	case storage.IsConstraintUnique(err):
+		return fmt.Errorf("%s: %w", op, storage.ErrDomainExist)
+	case err != nil:
+		return s.fail(ctx, op, err)
+	}
+
+	// Неподтверждённые заявки не могут иметь ссылок, поэтому удаляются целиком
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM domains WHERE host = ? AND id <> ?`), host, id)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	// Хост теперь открывает ссылки домена, а не ссылки без домена
+	s.notifyHost(host)
+
+	return nil
+}
+
+// DeleteDomain removes the user's domain. Domains that still have links
+// are kept and storage.ErrDomainInUse is returned.
+func (s *Storage) DeleteDomain(ctx context.Context, id int64, userID int64) error {
+	const op = "storage.sql.DeleteDomain"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	err = tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT EXISTS(SELECT 1 FROM url WHERE domain_id = ?)`), id).Scan(&inUse)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if inUse {
+		return fmt.Errorf("%s: %w", op, storage.ErrDomainInUse)
+	}
+
+	var host string
+	err = tx.QueryRowContext(ctx, s.ConvertQuery(`DELETE FROM domains WHERE id = ? AND user_id = ? RETURNING host`), id, userID).
+		Scan(&host)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, storage.ErrDomainNotFound)
+	}
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	s.notifyHost(host)
+
+	return nil
+}
+
+// userDomainID returns the id of the user's verified domain with the host,
+// or nil for an empty host.
+func (s *Storage) userDomainID(ctx context.Context, q rowQuerier, host string, userID int64) (any, error) {
+	if host == "" {
+		return nil, nil
+	}
+
+	var id int64
+	var verifiedAt *time.Time
+	err := q.QueryRowContext(ctx, s.ConvertQuery(`SELECT id, verified_at FROM domains WHERE host = ? AND user_id = ?`), host, userID).
+		Scan(&id, &verifiedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, storage.ErrDomainNotFound
+	case err != nil:
+		return nil, err
+	}
+
+	if verifiedAt == nil {
+		return nil, storage.ErrDomainNotVerified
+	}
+
+	return id, nil
+}
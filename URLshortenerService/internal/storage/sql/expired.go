@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"URLshortener/internal/lib/quota"
+	"context"
+	"time"
+)
+
+const expiredCondition = `(expires_at IS NOT NULL AND expires_at <= ?) OR (max_clicks IS NOT NULL AND clicks_used >= max_clicks)`
+
+// PurgeExpiredLinks removes links that reached their time or click limit.
+// With archive set the links are copied to url_archive together with their
+// clicks and revisions kept, otherwise those are removed as well. Old aliases of
+// renamed links whose grace period is over are removed too, as well as
+// creations older than the daily quota counts.
+func (s *Storage) PurgeExpiredLinks(ctx context.Context, now time.Time, archive bool) (int64, error) {
+	const op = "storage.sql.PurgeExpiredLinks"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	now = now.UTC()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if archive {
+		_, err = tx.ExecContext(ctx, s.ConvertQuery(`
+			INSERT INTO url_archive(id, url, alias, user_id, redirect_code, expires_at, max_clicks, clicks_used, domain_id, archived_at)
+			SELECT id, url, alias, user_id, redirect_code, expires_at, max_clicks, clicks_used, domain_id, ?
+			FROM url
+			WHERE `+expiredCondition), now, now)
+		if err != nil {
+			return 0, s.fail(ctx, op, err)
+		}
+	} else {
+		for _, table := range []string{"clicks", "link_revisions"} {
+			_, err = tx.ExecContext(ctx, s.ConvertQuery(`
+				DELETE FROM `+table+` WHERE url_id IN (SELECT id FROM url WHERE `+expiredCondition+`)`), now)
+			if err != nil {
+				return 0, s.fail(ctx, op, err)
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`
+		DELETE FROM url_tags WHERE url_id IN (SELECT id FROM url WHERE `+expiredCondition+`)`), now)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`
+		DELETE FROM alias_redirects
+		WHERE expires_at <= ? OR url_id IN (SELECT id FROM url WHERE `+expiredCondition+`)`), now, now)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM link_creations WHERE created_at < ?`), now.Add(-quota.Day))
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	result, err := tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM url WHERE `+expiredCondition), now)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"URLshortener/internal/storage"
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// opError is an error of a storage operation. Its text is the same as of
+// fmt.Errorf("%s: %w", op, err), the type only marks errors that were
+// already reported to the OnError hook.
+type opError struct {
+	op  string
+	err error
+}
+
+func (e *opError) Error() string {
+	return e.op + ": " + e.err.Error()
+}
+
+func (e *opError) Unwrap() error {
+	return e.err
+}
+
+// OnError registers fn to be called with the op name of every failed
+// operation. Expected outcomes, such as a missing or a taken alias, are not
+// failures. fn is called synchronously and must be fast.
+func (s *Storage) OnError(fn func(op string)) {
+	s.failed = fn
+}
+
+// fail wraps err with the op name, reports it to the OnError hook and marks
+// the span of the operation as failed. An error of a nested operation is
+// reported only once, by the innermost one.
+func (s *Storage) fail(ctx context.Context, op string, err error) error {
+	var reported *opError
+	if !errors.As(err, &reported) && !storage.IsExpected(err) {
+		span := trace.SpanFromContext(ctx)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		if s.failed != nil {
+			s.failed(op)
+		}
+	}
+
+	return &opError{op: op, err: err}
+}
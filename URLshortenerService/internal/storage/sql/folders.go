@@ -0,0 +1,255 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ListFolders returns all folders of the user by name with the number of
+// links directly in each of them. The tree is built from ParentID.
+func (s *Storage) ListFolders(ctx context.Context, userID int64) ([]models.Folder, error) {
+	const op = "storage.sql.ListFolders"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`
+		SELECT id, parent_id, name, created_at, (SELECT COUNT(*) FROM url WHERE url.folder_id = folders.id AND url.deleted_at IS NULL)
+		FROM folders
+		WHERE user_id = ?
+		ORDER BY name, id`)
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	folders := []models.Folder{}
+	for rows.Next() {
+		var folder models.Folder
+		if err := rows.Scan(&folder.ID, &folder.ParentID, &folder.Name, &folder.CreatedAt, &folder.Links); err != nil {
+			return nil, s.fail(ctx, op, err)
+		}
+		folders = append(folders, folder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+
+	return folders, nil
+}
+
+// CreateFolder adds a folder of the user at the root or inside the parent
+// folder. Names are unique among the folders of one parent.
+func (s *Storage) CreateFolder(ctx context.Context, userID int64, name string, parentID *int64) (models.Folder, error) {
+	const op = "storage.sql.CreateFolder"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	if parentID != nil {
+		if err := s.checkFolderOwned(ctx, s.db, *parentID, userID); err != nil {
+			return models.Folder{}, s.fail(ctx, op, err)
+		}
+	}
+
+	query := s.ConvertQuery(`
+		INSERT INTO folders(user_id, parent_id, name, created_at)
+		VALUES(?, ?, ?, ?)
+		RETURNING id, parent_id, name, created_at`)
+
+	var folder models.Folder
+	err := s.db.QueryRowContext(ctx, query, userID, parentID, name, time.Now().UTC().Truncate(time.Microsecond)).
+		Scan(&folder.ID, &folder.ParentID, &folder.Name, &folder.CreatedAt)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return models.Folder{}, fmt.Errorf("%s: %w", op, storage.ErrFolderExist)
+		}
+		return models.Folder{}, s.fail(ctx, op, err)
+	}
+
+	return folder, nil
+}
+
+// UpdateFolder renames the folder and moves it to another parent. An empty
+// name keeps the current one, a nil parentID keeps the current parent and
+// a zero parentID moves the folder to the root. A folder cannot be moved
+// into itself or its descendants: storage.ErrFolderCycle is returned.
+func (s *Storage) UpdateFolder(ctx context.Context, id int64, userID int64, name string, parentID *int64) error {
+	const op = "storage.sql.UpdateFolder"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	var current models.Folder
+	err = tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT parent_id, name FROM folders WHERE id = ? AND user_id = ?`), id, userID).
+		Scan(&current.ParentID, &current.Name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%s: %w", op, storage.ErrFolderNotFound)
+	case err != nil:
+		return s.fail(ctx, op, err)
+	}
+
+	if name == "" {
+		name = current.Name
+	}
+
+	parent := current.ParentID
+	if parentID != nil {
+		parent = parentID
+		if *parentID == 0 {
+			parent = nil
+		}
+	}
+
+	if parent != nil {
+		if err := s.checkFolderOwned(ctx, tx, *parent, userID); err != nil {
+			return s.fail(ctx, op, err)
+		}
+
+		// Новый родитель не может быть самой папкой или ее потомком
+		var cycle bool
+		err := tx.QueryRowContext(ctx, s.ConvertQuery(`
+			WITH RECURSIVE ancestors(id, parent_id) AS (
+				SELECT id, parent_id FROM folders WHERE id = ?
+				UNION ALL
+				SELECT folders.id, folders.parent_id FROM folders JOIN ancestors ON folders.id = ancestors.parent_id
+			)
+			SELECT COUNT(*) > 0 FROM ancestors WHERE id = ?`), *parent, id).Scan(&cycle)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+
+		if cycle {
+			return fmt.Errorf("%s: %w", op, storage.ErrFolderCycle)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`UPDATE folders SET name = ?, parent_id = ? WHERE id = ?`), name, parent, id)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrFolderExist)
+		}
+		return s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// DeleteFolder deletes the folder of the user. Its links and subfolders are
+// moved to the parent of the deleted folder.
+func (s *Storage) DeleteFolder(ctx context.Context, id int64, userID int64) error {
+	const op = "storage.sql.DeleteFolder"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	var parentID *int64
+	err = tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT parent_id FROM folders WHERE id = ? AND user_id = ?`), id, userID).Scan(&parentID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%s: %w", op, storage.ErrFolderNotFound)
+	case err != nil:
+		return s.fail(ctx, op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`UPDATE url SET folder_id = ? WHERE folder_id = ?`), parentID, id)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`UPDATE folders SET parent_id = ? WHERE parent_id = ?`), parentID, id)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrFolderExist)
+		}
+		return s.fail(ctx, op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM folders WHERE id = ?`), id); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// MoveLinks puts the user's links into the folder, or out of any folder if
+// folderID is nil. If some of the links do not belong to the user, nothing
+// is changed and storage.ErrAliasNotFound is returned.
+func (s *Storage) MoveLinks(ctx context.Context, userID int64, linkIDs []int64, folderID *int64) error {
+	const op = "storage.sql.MoveLinks"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if folderID != nil {
+		if err := s.checkFolderOwned(ctx, tx, *folderID, userID); err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	if err := s.checkLinksOwned(ctx, tx, userID, linkIDs); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	in, ids := inList(linkIDs)
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`UPDATE url SET folder_id = ? WHERE id IN (`+in+`)`), append([]any{folderID}, ids...)...)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// checkFolderOwned returns storage.ErrFolderNotFound unless the folder belongs to the user.
+func (s *Storage) checkFolderOwned(ctx context.Context, q rowQuerier, id int64, userID int64) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, s.ConvertQuery(`SELECT COUNT(*) > 0 FROM folders WHERE id = ? AND user_id = ?`), id, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return storage.ErrFolderNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortColumns maps the sort keys of models.LinkFilter to columns of the listing query.
+var sortColumns = map[string]string{
+	models.SortCreated: "created_at",
+	models.SortAlias:   "alias",
+	models.SortClicks:  "clicks",
+}
+
+// cursor points at the last link of a page. It is bound to the sort order
+// it was issued for and is passed to clients as an opaque string.
+type cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// ListUserLinks returns a page of the user's links matching the filter and
+// the total number of matching links. Pages are keyset-paginated: the next
+// page starts right after the link encoded in filter.Cursor.
+func (s *Storage) ListUserLinks(ctx context.Context, userID int64, filter models.LinkFilter) (models.LinkPage, error) {
+	const op = "storage.sql.ListUserLinks"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	column, ok := sortColumns[filter.Sort]
+	if !ok {
+		return models.LinkPage{}, fmt.Errorf("%s: unknown sort %q", op, filter.Sort)
+	}
+
+	where, args := linkFilterCondition(userID, filter, time.Now().UTC())
+
+	var total int64
+	err := s.db.QueryRowContext(ctx, s.ConvertQuery(`SELECT COUNT(*) FROM url WHERE `+where), args...).Scan(&total)
+	if err != nil {
+		return models.LinkPage{}, s.fail(ctx, op, err)
+	}
+
+	direction, cmp := "ASC", ">"
+	if filter.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	query := `
+		SELECT ` + noteColumns + `, clicks FROM (
+			SELECT url.*, (SELECT COUNT(*) FROM clicks WHERE clicks.url_id = url.id) AS clicks
+			FROM url
+			WHERE ` + where + `
+		) u`
+
+	if filter.Cursor != "" {
+		value, id, err := decodeCursor(filter)
+		if err != nil {
+			return models.LinkPage{}, s.fail(ctx, op, err)
+		}
+
+		query += fmt.Sprintf(` WHERE (%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))`, column, cmp)
+		args = append(args, value, value, id)
+	}
+
+	// Лишняя строка показывает, что есть следующая страница
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT ?`, column, direction, direction)
+	args = append(args, filter.Limit+1)
+
+	rows, err := s.db.QueryContext(ctx, s.ConvertQuery(query), args...)
+	if err != nil {
+		return models.LinkPage{}, s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	items := []models.LinkItem{}
+	for rows.Next() {
+		var item models.LinkItem
+
+		item.AliasNote, err = scanNote(extraScanner{row: rows, extra: []any{&item.Clicks}})
+		if err != nil {
+			return models.LinkPage{}, s.fail(ctx, op, err)
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.LinkPage{}, s.fail(ctx, op, err)
+	}
+
+	page := models.LinkPage{Items: items, Total: total}
+	if len(items) > filter.Limit {
+		page.Items = items[:filter.Limit]
+		page.NextCursor = encodeCursor(filter, page.Items[filter.Limit-1])
+	}
+
+	ids := make([]int64, len(page.Items))
+	for i, item := range page.Items {
+		ids[i] = item.ID
+	}
+
+	tags, err := s.linkTags(ctx, ids)
+	if err != nil {
+		return models.LinkPage{}, s.fail(ctx, op, err)
+	}
+
+	for i := range page.Items {
+		page.Items[i].Tags = tags[page.Items[i].ID]
+		if page.Items[i].Tags == nil {
+			page.Items[i].Tags = []string{}
+		}
+	}
+
+	return page, nil
+}
+
+// linkFilterCondition builds the WHERE clause over the url table for the filter.
+func linkFilterCondition(userID int64, filter models.LinkFilter, now time.Time) (string, []any) {
+	conditions := []string{"user_id = ?", "deleted_at IS NULL"}
+	args := []any{userID}
+
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Search)) + "%"
+		conditions = append(conditions, `(LOWER(url) LIKE ? ESCAPE '\' OR LOWER(alias) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.CreatedFrom.UTC())
+	}
+
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.CreatedTo.UTC())
+	}
+
+	for _, tag := range filter.Tags {
+		conditions = append(conditions, `id IN (
+			SELECT url_tags.url_id FROM url_tags JOIN tags ON tags.id = url_tags.tag_id
+			WHERE tags.user_id = ? AND tags.name = ?)`)
+		args = append(args, userID, tag)
+	}
+
+	if filter.FolderID != nil {
+		conditions = append(conditions, "folder_id = ?")
+		args = append(args, *filter.FolderID)
+	}
+
+	switch filter.Status {
+	case models.StatusExpired:
+		conditions = append(conditions, "("+expiredCondition+")")
+		args = append(args, now)
+	case models.StatusActive:
+		conditions = append(conditions, "NOT ("+expiredCondition+")")
+		args = append(args, now)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func encodeCursor(filter models.LinkFilter, last models.LinkItem) string {
+	c := cursor{Sort: filter.Sort, Desc: filter.Desc, ID: last.ID}
+
+	switch filter.Sort {
+	case models.SortCreated:
+		c.Value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case models.SortAlias:
+		c.Value = last.Alias
+	case models.SortClicks:
+		c.Value = strconv.FormatInt(last.Clicks, 10)
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the sort value and id of the link the page starts after.
+func decodeCursor(filter models.LinkFilter) (any, int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	if err != nil {
+		return nil, 0, storage.ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, 0, storage.ErrInvalidCursor
+	}
+
+	// Курсор другой сортировки указывал бы на случайное место списка
+	if c.Sort != filter.Sort || c.Desc != filter.Desc {
+		return nil, 0, storage.ErrInvalidCursor
+	}
+
+	switch c.Sort {
+	case models.SortCreated:
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, 0, storage.ErrInvalidCursor
+		}
+		return t.UTC(), c.ID, nil
+	case models.SortClicks:
+		n, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return nil, 0, storage.ErrInvalidCursor
+		}
+		return n, c.ID, nil
+	default:
+		return c.Value, c.ID, nil
+	}
+}
+
+// extraScanner lets scanNote read rows that have additional columns after noteColumns.
+type extraScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+func (e extraScanner) Scan(dest ...any) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
@@ -0,0 +1,64 @@
+package sql
+
+import (
+	"URLshortener/internal/lib/quota"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// CountLinks returns the number of links of the user and how many links the
+// user created since the given time. Creations are counted from the
+// link_creations journal: deleting a link does not give the daily quota back,
+// and an imported created_at cannot move a link out of the day. Links in the
+// trash are counted too, they still take space until purged.
+func (s *Storage) CountLinks(ctx context.Context, userID int64, since time.Time) (int, int, error) {
+	const op = "storage.sql.CountLinks"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	total, recent, err := s.countLinks(ctx, s.db, userID, since)
+	if err != nil {
+		return 0, 0, s.fail(ctx, op, err)
+	}
+
+	return total, recent, nil
+}
+
+func (s *Storage) countLinks(ctx context.Context, q rowQuerier, userID int64, since time.Time) (int, int, error) {
+	query := s.ConvertQuery(`
+		SELECT (SELECT COUNT(*) FROM url WHERE user_id = ?),
+			(SELECT COUNT(*) FROM link_creations WHERE user_id = ? AND created_at >= ?)`)
+
+	var total, recent int
+	if err := q.QueryRowContext(ctx, query, userID, userID, since.UTC()).Scan(&total, &recent); err != nil {
+		return 0, 0, err
+	}
+
+	return total, recent, nil
+}
+
+// reserveLinks checks within tx that n more links fit in the limits of the
+// user. The user's row in link_quotas is written first: postgres locks it
+// and sqlite takes the write lock, so concurrent creations of the same user
+// are checked one after another instead of all passing together.
+func (s *Storage) reserveLinks(ctx context.Context, tx *sql.Tx, userID int64, limits quota.Limits, n int) error {
+	if limits.Unlimited() {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, s.ConvertQuery(`
+		INSERT INTO link_quotas(user_id) VALUES(?)
+		ON CONFLICT (user_id) DO UPDATE SET user_id = excluded.user_id`), userID)
+	if err != nil {
+		return err
+	}
+
+	total, recent, err := s.countLinks(ctx, tx, userID, time.Now().Add(-quota.Day))
+	if err != nil {
+		return err
+	}
+
+	return limits.Fit(total, recent, n)
+}
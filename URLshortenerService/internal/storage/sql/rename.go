@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// RenameAlias changes the alias of the user's link given by id, or by alias
+// among links without a domain if id is 0. The new alias must be free on the
+// domain of the link. Clicks stay with the link. With keepOldUntil set the old
+// alias keeps leading to the link until that time and cannot be taken by
+// other links. The rename is recorded as a revision made by the actor. The
+// renamed link is returned with its previous alias, which is empty if the
+// alias did not change.
+func (s *Storage) RenameAlias(ctx context.Context, id int64, alias string, newAlias string, actor models.Actor, keepOldUntil *time.Time) (models.AliasNote, string, error) {
+	const op = "storage.sql.RenameAlias"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AliasNote{}, "", s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	link, err := s.findLink(ctx, tx, id, alias, actor.UserID)
+	if err != nil {
+		return models.AliasNote{}, "", s.fail(ctx, op, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	if newAlias != link.alias {
+		if err := s.setAlias(ctx, tx, link, newAlias, now); err != nil {
+			return models.AliasNote{}, "", s.fail(ctx, op, err)
+		}
+
+		if keepOldUntil != nil {
+			_, err = tx.ExecContext(ctx, s.ConvertQuery(`
+				INSERT INTO alias_redirects(url_id, domain_id, alias, expires_at, created_at)
+				VALUES(?, ?, ?, ?, ?)`), link.id, link.domainID, link.alias, keepOldUntil.UTC(), now)
+			if err != nil {
+				return models.AliasNote{}, "", s.fail(ctx, op, err)
+			}
+		}
+
+		renamed := link
+		renamed.alias = newAlias
+		if err := s.insertRevision(ctx, tx, models.ChangeAlias, link, renamed, actor, now); err != nil {
+			return models.AliasNote{}, "", s.fail(ctx, op, err)
+		}
+	}
+
+	note, err := scanNote(tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT `+noteColumns+` FROM url WHERE id = ?`), link.id))
+	if err != nil {
+		return models.AliasNote{}, "", s.fail(ctx, op, err)
+	}
+
+	// Старый алиас без льготного срока освобождается и в список не попадает
+	aliases, err := s.linkAliases(ctx, tx, `id = ?`, link.id)
+	if err != nil {
+		return models.AliasNote{}, "", s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.AliasNote{}, "", s.fail(ctx, op, err)
+	}
+
+	s.notifyAliases(append(aliases, link.alias))
+
+	oldAlias := ""
+	if newAlias != link.alias {
+		oldAlias = link.alias
+	}
+
+	return note, oldAlias, nil
+}
+
+// setAlias gives the link the new alias if it is free on the domain of the link.
+func (s *Storage) setAlias(ctx context.Context, tx *sql.Tx, link linkState, newAlias string, now time.Time) error {
+	// Свой старый алиас можно вернуть, чужой еще занят
+	redirected, err := s.activeRedirect(ctx, tx, link.domainID, newAlias)
+	if err != nil {
+		return err
+	}
+	if redirected != 0 && redirected != link.id {
+		return storage.ErrAliasExist
+	}
+
+	// Истекшие записи только мешали бы уникальному индексу
+	for _, a := range []string{newAlias, link.alias} {
+		_, err = tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM alias_redirects WHERE alias = ? AND COALESCE(domain_id, 0) = COALESCE(?, 0)`),
+			a, link.domainID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`UPDATE url SET alias = ?, updated_at = ? WHERE id = ?`), newAlias, now, link.id)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return storage.ErrAliasExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+// activeRedirect returns the id of the link the old alias on the domain
+// still leads to, or 0 if there is none.
+func (s *Storage) activeRedirect(ctx context.Context, q rowQuerier, domainID any, alias string) (int64, error) {
+	query := s.ConvertQuery(`
+		SELECT url_id FROM alias_redirects
+		WHERE alias = ? AND COALESCE(domain_id, 0) = COALESCE(?, 0) AND expires_at > ?`)
+
+	var urlID int64
+	err := q.QueryRowContext(ctx, query, alias, domainID, time.Now().UTC()).Scan(&urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return urlID, err
+}
@@ -0,0 +1,194 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// linkState is what revisions record about a link.
+type linkState struct {
+	id       int64
+	url      string
+	alias    string
+	domainID *int64
+}
+
+// findLink returns the user's link given by id, or by alias among links
+// without a domain if id is 0. Links in the trash are not found.
+func (s *Storage) findLink(ctx context.Context, q rowQuerier, id int64, alias string, userID int64) (linkState, error) {
+	query := `SELECT id, url, alias, domain_id FROM url WHERE id = ? AND user_id = ? AND deleted_at IS NULL`
+	var ref any = id
+	if id == 0 {
+		query = `SELECT id, url, alias, domain_id FROM url WHERE alias = ? AND domain_id IS NULL AND user_id = ? AND deleted_at IS NULL`
+		ref = alias
+	}
+
+	var link linkState
+	err := q.QueryRowContext(ctx, s.ConvertQuery(query), ref, userID).Scan(&link.id, &link.url, &link.alias, &link.domainID)
+	if errors.Is(err, sql.ErrNoRows) {
+		if id == 0 {
+			// Алиас может быть занят чужой ссылкой или своей из корзины
+			var ownerID int64
+			err := q.QueryRowContext(ctx, s.ConvertQuery(`SELECT user_id FROM url WHERE alias = ? AND domain_id IS NULL`), alias).Scan(&ownerID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return linkState{}, err
+			}
+			if err == nil && ownerID != userID {
+				return linkState{}, storage.ErrAliasNotOwned
+			}
+		}
+		return linkState{}, storage.ErrAliasNotFound
+	}
+	if err != nil {
+		return linkState{}, err
+	}
+
+	return link, nil
+}
+
+// insertRevision records the change of the link from old to new.
+func (s *Storage) insertRevision(ctx context.Context, tx *sql.Tx, change string, old linkState, new linkState, actor models.Actor, now time.Time) error {
+	_, err := tx.ExecContext(ctx, s.ConvertQuery(`
+		INSERT INTO link_revisions(url_id, change, old_url, new_url, old_alias, new_alias, actor_id, actor_role, created_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		old.id, change, old.url, new.url, old.alias, new.alias, actor.UserID, actor.Role, now)
+
+	return err
+}
+
+// ListRevisions returns the revisions of the user's link, newest first.
+func (s *Storage) ListRevisions(ctx context.Context, id int64, userID int64) ([]models.Revision, error) {
+	const op = "storage.sql.ListRevisions"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.findLink(ctx, s.db, id, "", userID); err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.ConvertQuery(`
+		SELECT id, url_id, change, old_url, new_url, old_alias, new_alias, actor_id, actor_role, created_at
+		FROM link_revisions
+		WHERE url_id = ?
+		ORDER BY id DESC`), id)
+	if err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	revisions := []models.Revision{}
+	for rows.Next() {
+		var rev models.Revision
+		err := rows.Scan(&rev.ID, &rev.URLID, &rev.Change, &rev.OldURL, &rev.NewURL,
+			&rev.OldAlias, &rev.NewAlias, &rev.ActorID, &rev.ActorRole, &rev.CreatedAt)
+		if err != nil {
+			return nil, s.fail(ctx, op, err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns the revision of the user's link.
+func (s *Storage) GetRevision(ctx context.Context, id int64, revisionID int64, userID int64) (models.Revision, error) {
+	const op = "storage.sql.GetRevision"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.findLink(ctx, s.db, id, "", userID); err != nil {
+		return models.Revision{}, s.fail(ctx, op, err)
+	}
+
+	var rev models.Revision
+	err := s.db.QueryRowContext(ctx, s.ConvertQuery(`
+		SELECT id, url_id, change, old_url, new_url, old_alias, new_alias, actor_id, actor_role, created_at
+		FROM link_revisions
+		WHERE id = ? AND url_id = ?`), revisionID, id).
+		Scan(&rev.ID, &rev.URLID, &rev.Change, &rev.OldURL, &rev.NewURL,
+			&rev.OldAlias, &rev.NewAlias, &rev.ActorID, &rev.ActorRole, &rev.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Revision{}, fmt.Errorf("%s: %w", op, storage.ErrRevisionNotFound)
+	}
+	if err != nil {
+		return models.Revision{}, s.fail(ctx, op, err)
+	}
+
+	return rev, nil
+}
+
+// RollbackLink returns the destination and the alias of the user's link to
+// what they were before the revision. The old alias must still be free. The
+// rollback is recorded as a revision too. The restored link is returned.
+func (s *Storage) RollbackLink(ctx context.Context, id int64, revisionID int64, actor models.Actor) (models.AliasNote, error) {
+	const op = "storage.sql.RollbackLink"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	link, err := s.findLink(ctx, tx, id, "", actor.UserID)
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	restored := link
+	err = tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT old_url, old_alias FROM link_revisions WHERE id = ? AND url_id = ?`),
+		revisionID, id).Scan(&restored.url, &restored.alias)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AliasNote{}, fmt.Errorf("%s: %w", op, storage.ErrRevisionNotFound)
+	}
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	if restored.alias != link.alias {
+		if err := s.setAlias(ctx, tx, link, restored.alias, now); err != nil {
+			return models.AliasNote{}, s.fail(ctx, op, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`UPDATE url SET url = ?, updated_at = ? WHERE id = ?`), restored.url, now, id)
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	if err := s.insertRevision(ctx, tx, models.ChangeRollback, link, restored, actor, now); err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	note, err := scanNote(tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT `+noteColumns+` FROM url WHERE id = ?`), id))
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	aliases, err := s.linkAliases(ctx, tx, `id = ?`, id)
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	s.notifyAliases(append(aliases, link.alias))
+
+	return note, nil
+}
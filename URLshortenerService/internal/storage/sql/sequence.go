@@ -0,0 +1,20 @@
+package sql
+
+import "context"
+
+// NextAliasID returns the next value of the counter used by sequential alias generators.
+// The increment is a single statement, so concurrent callers never get the same value.
+func (s *Storage) NextAliasID(ctx context.Context) (int64, error) {
+	const op = "storage.sql.NextAliasID"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `UPDATE alias_counter SET value = value + 1 WHERE id = 1 RETURNING value`).Scan(&id)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	return id, nil
+}
@@ -2,23 +2,37 @@ package sql
 
 import (
 	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/quota"
 	"URLshortener/internal/storage"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"strings"
+	"time"
 )
 
 type Storage struct {
 	db     *sql.DB
 	driver string
+	// aliasChanged is set by OnAliasChange
+	aliasChanged func(aliases []string)
+	// hostChanged is set by OnHostChange
+	hostChanged func(host string)
+	// failed is set by OnError
+	failed func(op string)
 }
 
-// New creates a new instance of the SQLite storage
+// New opens the storage on the postgres or the sqlite3 driver
 func New(driver string, connStr string) (*Storage, error) {
 	const op = "storage.sql.New"
 
+	if driver == "sqlite3" {
+		connStr = sqliteDSN(connStr)
+	}
+
 	db, err := sql.Open(driver, connStr)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -28,18 +42,22 @@ func New(driver string, connStr string) (*Storage, error) {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if driver == "sqlite3" {
-		_, err = db.Exec("PRAGMA foreign_keys = ON;")
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-	}
 	return &Storage{
 		db:     db,
 		driver: driver,
 	}, nil
 }
 
+// sqliteDSN turns a path to the database file into a DSN that enables foreign
+// keys and waits for locks on every connection of the pool, PRAGMA would
+// reach only one of them. A DSN with its own options is kept as is.
+func sqliteDSN(path string) string {
+	if strings.Contains(path, "?") {
+		return path
+	}
+	return path + "?_foreign_keys=on&_busy_timeout=5000"
+}
+
 func (s *Storage) ConvertQuery(query string) string {
 	switch s.driver {
 	case "postgres":
@@ -62,151 +80,532 @@ func (s *Storage) ConvertQuery(query string) string {
 	}
 }
 
-func (s *Storage) SaveURL(urlToSave string, alias string, userID int64) (int64, error) {
+// noteColumns are the url columns read by scanNote, in order.
+const noteColumns = `id, url, alias, redirect_code, expires_at, max_clicks, clicks_used, password_hash, created_at, folder_id,
+	title, description, notes, updated_at, deleted_at,
+	COALESCE((SELECT host FROM domains WHERE domains.id = domain_id), '') AS domain`
+
+// domainCondition matches links of the verified domain with the host bound
+// to it, or links without a domain if there is no such domain.
+const domainCondition = `COALESCE(domain_id, 0) = COALESCE((SELECT id FROM domains WHERE host = ? AND verified_at IS NOT NULL), 0)`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNote(row rowScanner) (models.AliasNote, error) {
+	var note models.AliasNote
+	err := row.Scan(
+		&note.ID,
+		&note.Url,
+		&note.Alias,
+		&note.RedirectCode,
+		&note.ExpiresAt,
+		&note.MaxClicks,
+		&note.ClicksUsed,
+		&note.PasswordHash,
+		&note.CreatedAt,
+		&note.FolderID,
+		&note.Title,
+		&note.Description,
+		&note.Notes,
+		&note.UpdatedAt,
+		&note.DeletedAt,
+		&note.Domain,
+	)
+	note.Protected = len(note.PasswordHash) > 0
+	return note, err
+}
+
+// SaveURL stores the note of the user. Unless limits are zero, the link must
+// fit in them, otherwise an error wrapping quota.ErrExceeded is returned; its
+// text can be shown to the client.
+func (s *Storage) SaveURL(ctx context.Context, note models.AliasNote, userID int64, limits quota.Limits) (int64, error) {
 	const op = "storage.sql.SaveURL"
 
-	query := s.ConvertQuery(`INSERT INTO url(url, alias, user_id)  VALUES(?, ?, ?) RETURNING id;`)
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
 
-	var lastInsertID int64
-	err := s.db.QueryRow(query, urlToSave, alias, userID).Scan(&lastInsertID)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
 
-		if storage.IsConstraintUnique(err) {
-			return 0, fmt.Errorf("%s: %w", op, storage.ErrAliasExist)
+	if err := s.reserveLinks(ctx, tx, userID, limits, 1); err != nil {
+		if errors.Is(err, quota.ErrExceeded) {
+			return 0, err
 		}
+		return 0, s.fail(ctx, op, err)
+	}
 
-		return 0, fmt.Errorf("%s: %w", op, err)
+	id, err := s.insertNote(ctx, tx, note, userID)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
 	}
 
-	return lastInsertID, nil
+	if err := tx.Commit(); err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	// Алиас мог быть закеширован как несуществующий
+	s.notifyAliases([]string{note.Alias})
+
+	return id, nil
 }
 
-func (s *Storage) GetUserUrls(userID int64) ([]models.AliasNote, error) {
-	const op = "storage.sql.GetUserUrls"
+// SaveURLs stores all notes in one transaction. If one of them cannot be
+// stored, nothing is saved and the index of that note is returned with the
+// error. Notes that do not fit in non-zero limits all together are not
+// saved either, the error wraps quota.ErrExceeded and the index is -1.
+func (s *Storage) SaveURLs(ctx context.Context, notes []models.AliasNote, userID int64, limits quota.Limits) ([]int64, int, error) {
+	const op = "storage.sql.SaveURLs"
 
-	query := s.ConvertQuery(`SELECT id, url, alias FROM url WHERE user_id = ?`)
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
 
-	rows, err := s.db.Query(query, userID)
-	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		return nil, storage.ErrAliasNotFound
-	case err != nil:
-		return nil, fmt.Errorf("%s: %w", op, err)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, -1, s.fail(ctx, op, err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	aliasNotes := []models.AliasNote{}
-	for rows.Next() {
-		var aliasNote models.AliasNote
-		err = rows.Scan(&aliasNote.ID, &aliasNote.Url, &aliasNote.Alias)
+	if err := s.reserveLinks(ctx, tx, userID, limits, len(notes)); err != nil {
+		if errors.Is(err, quota.ErrExceeded) {
+			return nil, -1, err
+		}
+		return nil, -1, s.fail(ctx, op, err)
+	}
+
+	ids := make([]int64, 0, len(notes))
+	for i, note := range notes {
+		id, err := s.insertNote(ctx, tx, note, userID)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+			return nil, i, s.fail(ctx, op, err)
 		}
+		ids = append(ids, id)
+	}
 
-		aliasNotes = append(aliasNotes, aliasNote)
+	if err := tx.Commit(); err != nil {
+		return nil, -1, s.fail(ctx, op, err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	aliases := make([]string, 0, len(notes))
+	for _, note := range notes {
+		aliases = append(aliases, note.Alias)
 	}
+	s.notifyAliases(aliases)
 
-	return aliasNotes, nil
+	return ids, -1, nil
 }
 
-func (s *Storage) GetURL(alias string, userID int64) (string, error) {
-	const op = "storage.sql.GetURL"
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertNote stores the note and records its creation in link_creations,
+// the journal the daily quota is counted from.
+func (s *Storage) insertNote(ctx context.Context, tx *sql.Tx, note models.AliasNote, userID int64) (int64, error) {
+	domainID, err := s.userDomainID(ctx, tx, note.Domain, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Алиас, который еще ведет на переименованную ссылку, занят
+	redirected, err := s.activeRedirect(ctx, tx, domainID, note.Alias)
+	if err != nil {
+		return 0, err
+	}
+	if redirected != 0 {
+		return 0, storage.ErrAliasExist
+	}
 
-	query := s.ConvertQuery(`SELECT url FROM url WHERE alias = ? AND user_id = ?`)
+	query := s.ConvertQuery(`
+		INSERT INTO url(url, alias, redirect_code, expires_at, max_clicks, clicks_used, password_hash, created_at, updated_at,
+			inserted_at, title, description, notes, domain_id, user_id)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;`)
 
-	var resURL string
-	err := s.db.QueryRow(query, alias, userID).Scan(&resURL)
+	// created_at может прийти из импорта, inserted_at всегда ставит сервер
+	insertedAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	createdAt := note.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = insertedAt
+	}
+	createdAt = createdAt.UTC().Truncate(time.Microsecond)
+
+	updatedAt := note.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+
+	var lastInsertID int64
+	err = tx.QueryRowContext(ctx, query,
+		note.Url,
+		note.Alias,
+		note.RedirectCode,
+		utcOrNil(note.ExpiresAt),
+		note.MaxClicks,
+		note.ClicksUsed,
+		nilIfEmpty(note.PasswordHash),
+		createdAt,
+		updatedAt.UTC().Truncate(time.Microsecond),
+		insertedAt,
+		note.Title,
+		note.Description,
+		note.Notes,
+		domainID,
+		userID,
+	).Scan(&lastInsertID)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return 0, storage.ErrAliasExist
+		}
+
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`INSERT INTO link_creations(user_id, created_at) VALUES(?, ?)`), userID, insertedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return lastInsertID, nil
+}
+
+// ResolveAlias finds the link behind the alias on the host regardless of its
+// owner. Aliases are unique within a domain, so no user id is required.
+// Hosts that are not verified custom domains resolve links without a domain.
+// Links in the trash are not resolved.
+func (s *Storage) ResolveAlias(ctx context.Context, host string, alias string) (models.AliasNote, error) {
+	const op = "storage.sql.ResolveAlias"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`SELECT ` + noteColumns + ` FROM url WHERE alias = ? AND ` + domainCondition + ` AND deleted_at IS NULL`)
+
+	note, err := scanNote(s.db.QueryRowContext(ctx, query, alias, host))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Старый алиас переименованной ссылки ведет на нее до конца льготного срока
+		query = s.ConvertQuery(`
+			SELECT ` + noteColumns + ` FROM url
+			WHERE id = (SELECT url_id FROM alias_redirects WHERE alias = ? AND ` + domainCondition + ` AND expires_at > ?)
+				AND deleted_at IS NULL`)
+
+		note, err = scanNote(s.db.QueryRowContext(ctx, query, alias, host, time.Now().UTC()))
+	}
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
-		return "", storage.ErrAliasNotFound
+		return models.AliasNote{}, storage.ErrAliasNotFound
 	case err != nil:
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.AliasNote{}, s.fail(ctx, op, err)
 	}
 
-	return resURL, nil
+	return note, nil
 }
 
-func (s *Storage) DeleteAlias(id int64, userID int64) error {
-	const op = "storage.sql.DeleteURL"
+// ConsumeClick atomically uses up one click of a link limited by max_clicks.
+// It returns storage.ErrLinkExpired when no clicks are left.
+func (s *Storage) ConsumeClick(ctx context.Context, id int64) error {
+	const op = "storage.sql.ConsumeClick"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
 
-	query := s.ConvertQuery(`DELETE FROM url WHERE id = ? AND user_id = ?`)
+	query := s.ConvertQuery(`
+		UPDATE url SET clicks_used = clicks_used + 1
+		WHERE id = ? AND (max_clicks IS NULL OR clicks_used < max_clicks)`)
 
-	result, err := s.db.Exec(query, id, userID)
+	result, err := s.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return s.fail(ctx, op, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return s.fail(ctx, op, err)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+		return fmt.Errorf("%s: %w", op, storage.ErrLinkExpired)
 	}
 
 	return nil
 }
 
-func (s *Storage) UpdateAlias(id int64, newUrl string, userID int64) error {
-	const op = "storage.sql.UpdateURL"
+// DeleteAlias moves the user's link to the trash. The alias stays reserved
+// until the link is purged.
+func (s *Storage) DeleteAlias(ctx context.Context, id int64, userID int64) error {
+	const op = "storage.sql.DeleteURL"
 
-	query := s.ConvertQuery(`UPDATE url SET url = ? WHERE id = ? AND user_id = ?`)
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
 
-	result, err := s.db.Exec(query, newUrl, id, userID)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	aliases, err := s.linkAliases(ctx, s.db, `id = ?`, id)
 	if err != nil {
-		if storage.IsConstraintUnique(err) {
-			return fmt.Errorf("%s: %w", op, storage.ErrAliasExist)
-		}
-		return fmt.Errorf("%s: %w", op, err)
+		return s.fail(ctx, op, err)
+	}
+
+	result, err := s.db.ExecContext(ctx, s.ConvertQuery(`
+		UPDATE url SET deleted_at = ?, updated_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL`), now, now, id, userID)
+	if err != nil {
+		return s.fail(ctx, op, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return s.fail(ctx, op, err)
 	}
 
 	if rowsAffected == 0 {
 		return fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
 	}
 
+	s.notifyAliases(aliases)
+
 	return nil
 }
 
-func (s *Storage) UpdateAliasURL(newURL string, alias string, userID int64) error {
+// UpdateAlias changes the URL of the user's link with the id.
+func (s *Storage) UpdateAlias(ctx context.Context, id int64, newUrl string, actor models.Actor) error {
 	const op = "storage.sql.UpdateURL"
 
-	query := s.ConvertQuery(`UPDATE url SET url = ? WHERE alias = ? AND user_id = ?`)
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	if err := s.updateLink(ctx, id, "", actor, models.ChangeURL, `url = ?`, newUrl); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// UpdateAliasURL changes the URL behind the alias owned by the user.
+// Only links without a custom domain are addressed by alias. It returns storage.ErrAliasNotOwned if the alias belongs to somebody else.
+func (s *Storage) UpdateAliasURL(ctx context.Context, newURL string, alias string, actor models.Actor) error {
+	const op = "storage.sql.UpdateAliasURL"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	if err := s.updateLink(ctx, 0, alias, actor, models.ChangeURL, `url = ?`, newURL); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// aliasExists reports whether the alias is taken on the domain with the host,
+// or among links without a domain if the host is empty.
+func (s *Storage) aliasExists(ctx context.Context, domain string, alias string) (bool, error) {
+	query := s.ConvertQuery(`SELECT EXISTS(SELECT 1 FROM url WHERE alias = ? AND ` + domainCondition + `)`)
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, alias, domain).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// SetLinkPassword sets the password hash of the user's link found by id or,
+// if id is zero, by alias without a custom domain. A nil hash removes the password.
+func (s *Storage) SetLinkPassword(ctx context.Context, id int64, alias string, hash []byte, actor models.Actor) error {
+	const op = "storage.sql.SetLinkPassword"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	if err := s.updateLink(ctx, id, alias, actor, models.ChangePassword, `password_hash = ?`, nilIfEmpty(hash)); err != nil {
+		return s.fail(ctx, op, err)
+	}
 
-	result, err := s.db.Exec(query, newURL, alias, userID)
+	return nil
+}
+
+// UpdateLinkMetadata changes the title, description and notes of the user's
+// link given by id, or by alias if id is 0. Nil fields are not changed.
+func (s *Storage) UpdateLinkMetadata(ctx context.Context, id int64, alias string, meta models.LinkMetadata, actor models.Actor) error {
+	const op = "storage.sql.UpdateLinkMetadata"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	err := s.updateLink(ctx, id, alias, actor, models.ChangeMetadata,
+		`title = COALESCE(?, title), description = COALESCE(?, description), notes = COALESCE(?, notes)`,
+		meta.Title, meta.Description, meta.Notes,
+	)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return s.fail(ctx, op, err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return nil
+}
+
+// updateLink sets the columns of the actor's link given by id, or by alias
+// among links without a domain if id is 0, bumps its updated_at and records
+// the change as a revision.
+func (s *Storage) updateLink(ctx context.Context, id int64, alias string, actor models.Actor, change string, set string, args ...any) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return err
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	link, err := s.findLink(ctx, tx, id, alias, actor.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`UPDATE url SET `+set+`, updated_at = ? WHERE id = ?`), append(args, now, link.id)...)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return storage.ErrAliasExist
+		}
+		return err
+	}
+
+	updated := link
+	if err := tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT url FROM url WHERE id = ?`), link.id).Scan(&updated.url); err != nil {
+		return err
+	}
+
+	if err := s.insertRevision(ctx, tx, change, link, updated, actor, now); err != nil {
+		return err
 	}
+
+	aliases, err := s.linkAliases(ctx, tx, `id = ?`, link.id)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.notifyAliases(aliases)
+
 	return nil
 }
 
-func (s *Storage) DeleteUserData(userID int64) error {
+func (s *Storage) DeleteUserData(ctx context.Context, userID int64) error {
 	const op = "storage.sql.DeleteUserData"
 
-	query := s.ConvertQuery(`DELETE FROM url WHERE user_id = ?`)
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
 
-	_, err := s.db.Exec(query, userID)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	aliases, err := s.linkAliases(ctx, tx, `user_id = ?`, userID)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	// Archived links keep their clicks and revisions, so both are removed
+	// for the ids in url_archive as well.
+	for _, table := range []string{"clicks", "link_revisions"} {
+		_, err = tx.ExecContext(ctx, s.ConvertQuery(`
+			DELETE FROM `+table+`
+			WHERE url_id IN (SELECT id FROM url WHERE user_id = ?)
+			   OR url_id IN (SELECT id FROM url_archive WHERE user_id = ?)`), userID, userID)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	for _, table := range []string{"url_tags", "alias_redirects"} {
+		_, err = tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM `+table+` WHERE url_id IN (SELECT id FROM url WHERE user_id = ?)`), userID)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	for _, table := range []string{"url", "url_archive"} {
+		_, err = tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM `+table+` WHERE user_id = ?`), userID)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	for _, table := range []string{"tags", "folders", "link_creations", "link_quotas"} {
+		_, err = tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM `+table+` WHERE user_id = ?`), userID)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	hosts, err := s.deleteUserDomains(ctx, tx, userID)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	s.notifyAliases(aliases)
+	for _, host := range hosts {
+		s.notifyHost(host)
 	}
 
 	return nil
 }
+
+// deleteUserDomains removes the domains of the user and returns their hosts.
+func (s *Storage) deleteUserDomains(ctx context.Context, tx *sql.Tx, userID int64) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, s.ConvertQuery(`DELETE FROM domains WHERE user_id = ? RETURNING host`), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hosts []string
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, rows.Err()
+}
+
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func nilIfEmpty(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// Close closes the connections to the database.
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// Ping checks that the database answers.
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Stats returns the statistics of the connection pool.
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
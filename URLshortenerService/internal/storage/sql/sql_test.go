@@ -0,0 +1,361 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/lib/quota"
+	"URLshortener/internal/migrator"
+	"URLshortener/internal/storage"
+	"URLshortener/migrations"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStorage opens a migrated sqlite storage in a temporary file, so the
+// storage is tested without a postgres server.
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "url.db")
+
+	m, err := migrator.New("sqlite3", path, migrations.FS)
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Close())
+
+	s, err := New("sqlite3", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func TestStorage_SaveResolveDelete(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	const userID = 1
+
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "abc", RedirectCode: 302}, userID, quota.Limits{})
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	_, err = s.SaveURL(ctx, models.AliasNote{Url: "https://example.org", Alias: "abc", RedirectCode: 302}, userID+1, quota.Limits{})
+	require.ErrorIs(t, err, storage.ErrAliasExist)
+
+	note, err := s.ResolveAlias(ctx, "localhost", "abc")
+	require.NoError(t, err)
+	require.Equal(t, id, note.ID)
+	require.Equal(t, "https://example.com", note.Url)
+	require.WithinDuration(t, time.Now(), note.CreatedAt, time.Minute)
+
+	require.NoError(t, s.DeleteAlias(ctx, id, userID))
+
+	_, err = s.ResolveAlias(ctx, "localhost", "abc")
+	require.ErrorIs(t, err, storage.ErrAliasNotFound)
+
+	trash, err := s.ListTrash(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.NotNil(t, trash[0].DeletedAt)
+}
+
+func TestStorage_ConsumeClick(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	maxClicks := int64(1)
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "once", RedirectCode: 302, MaxClicks: &maxClicks}, 1, quota.Limits{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.ConsumeClick(ctx, id))
+	require.ErrorIs(t, s.ConsumeClick(ctx, id), storage.ErrLinkExpired)
+}
+
+func TestStorage_NextAliasID(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	first, err := s.NextAliasID(ctx)
+	require.NoError(t, err)
+
+	second, err := s.NextAliasID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, first+1, second)
+}
+
+func TestStorage_TagsAndFolders(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	const userID = 1
+
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "tagged", RedirectCode: 302}, userID, quota.Limits{})
+	require.NoError(t, err)
+
+	tag, err := s.CreateTag(ctx, userID, "work")
+	require.NoError(t, err)
+	require.NotZero(t, tag.ID)
+
+	_, err = s.CreateTag(ctx, userID, "work")
+	require.ErrorIs(t, err, storage.ErrTagExist)
+
+	require.NoError(t, s.TagLinks(ctx, userID, []int64{id}, []string{"work", "new"}, nil))
+
+	tags, err := s.ListTags(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	folder, err := s.CreateFolder(ctx, userID, "projects", nil)
+	require.NoError(t, err)
+
+	child, err := s.CreateFolder(ctx, userID, "2026", &folder.ID)
+	require.NoError(t, err)
+	require.Equal(t, folder.ID, *child.ParentID)
+
+	require.ErrorIs(t, s.UpdateFolder(ctx, folder.ID, userID, "projects", &child.ID), storage.ErrFolderCycle)
+
+	require.NoError(t, s.MoveLinks(ctx, userID, []int64{id}, &child.ID))
+}
+
+func TestStorage_DeleteUserData(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	const userID = 1
+
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "gone", RedirectCode: 302}, userID, quota.Limits{})
+	require.NoError(t, err)
+
+	_, err = s.CreateTag(ctx, userID, "work")
+	require.NoError(t, err)
+	require.NoError(t, s.TagLinks(ctx, userID, []int64{id}, []string{"work"}, nil))
+	require.NoError(t, s.SaveClicks(ctx, []models.Click{{URLID: id, Alias: "gone", ClickedAt: time.Now()}}))
+
+	// внешние ключи sqlite включены на каждом соединении пула
+	require.NoError(t, s.DeleteUserData(ctx, userID))
+
+	_, err = s.ResolveAlias(ctx, "localhost", "gone")
+	require.ErrorIs(t, err, storage.ErrAliasNotFound)
+
+	tags, err := s.ListTags(ctx, userID)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+}
+
+func TestStorage_DeleteUserDataArchived(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	const userID = 1
+
+	maxClicks := int64(1)
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "archived", RedirectCode: 302, MaxClicks: &maxClicks}, userID, quota.Limits{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.ConsumeClick(ctx, id))
+	require.NoError(t, s.SaveClicks(ctx, []models.Click{{URLID: id, Alias: "archived", ClickedAt: time.Now()}}))
+
+	purged, err := s.PurgeExpiredLinks(ctx, time.Now(), true)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+
+	require.NoError(t, s.DeleteUserData(ctx, userID))
+
+	var archived, clicks int
+	require.NoError(t, s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM url_archive WHERE user_id = ?`, userID).Scan(&archived))
+	require.NoError(t, s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM clicks WHERE url_id = ?`, id).Scan(&clicks))
+	require.Zero(t, archived)
+	require.Zero(t, clicks)
+}
+
+func TestStorage_LinkStats(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	const userID = 1
+
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "clicked", RedirectCode: 302}, userID, quota.Limits{})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	require.NoError(t, s.SaveClicks(ctx, []models.Click{
+		{URLID: id, Alias: "clicked", ClickedAt: now.Add(-48 * time.Hour), IP: "10.0.0.1"},
+		{URLID: id, Alias: "clicked", ClickedAt: now.Add(-time.Hour), IP: "10.0.0.1"},
+		{URLID: id, Alias: "clicked", ClickedAt: now.Add(-time.Hour), IP: "10.0.0.2"},
+	}))
+
+	stats, err := s.GetLinkStats(ctx, "", "clicked", userID, "day", now.Add(-7*24*time.Hour), now)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, stats.TotalClicks)
+	require.EqualValues(t, 2, stats.UniqueVisitors)
+	require.NotEmpty(t, stats.Series)
+}
+
+func TestStorage_RenameAlias(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	actor := models.Actor{UserID: 1, Role: "user"}
+
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "old", RedirectCode: 302}, actor.UserID, quota.Limits{})
+	require.NoError(t, err)
+
+	until := time.Now().Add(time.Hour)
+	note, oldAlias, err := s.RenameAlias(ctx, id, "", "new", actor, &until)
+	require.NoError(t, err)
+	require.Equal(t, "new", note.Alias)
+	require.Equal(t, "old", oldAlias)
+
+	// старый алиас еще ведет на ссылку
+	resolved, err := s.ResolveAlias(ctx, "localhost", "old")
+	require.NoError(t, err)
+	require.Equal(t, id, resolved.ID)
+
+	_, oldAlias, err = s.RenameAlias(ctx, id, "", "new", actor, nil)
+	require.NoError(t, err)
+	require.Empty(t, oldAlias)
+}
+
+func TestStorage_DailyQuotaIgnoresImportedCreatedAt(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	actor := models.Actor{UserID: 1, Role: "user"}
+	enforcer := quota.New(s, nil, quota.Limits{MaxDaily: 2})
+
+	// импорт сохраняет created_at из файла, ссылки из прошлого все равно входят в дневную квоту
+	backdated := time.Now().Add(-30 * 24 * time.Hour)
+	for _, alias := range []string{"first", "second"} {
+		require.NoError(t, enforcer.Check(ctx, actor, 1))
+
+		_, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: alias, RedirectCode: 302, CreatedAt: backdated}, actor.UserID, quota.Limits{})
+		require.NoError(t, err)
+	}
+
+	total, recent, err := s.CountLinks(ctx, actor.UserID, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, 2, recent)
+
+	require.ErrorIs(t, enforcer.Check(ctx, actor, 1), quota.ErrExceeded)
+}
+
+func TestStorage_DailyQuotaSurvivesDeletion(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	const userID = 1
+	limits := quota.Limits{MaxDaily: 1}
+
+	id, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "first", RedirectCode: 302}, userID, limits)
+	require.NoError(t, err)
+
+	// удаленная и вычищенная из корзины ссылка остается в дневной квоте
+	require.NoError(t, s.DeleteAlias(ctx, id, userID))
+	require.NoError(t, s.PurgeLink(ctx, id, userID))
+
+	_, err = s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: "second", RedirectCode: 302}, userID, limits)
+	require.ErrorIs(t, err, quota.ErrExceeded)
+	require.EqualError(t, err, "link quota exceeded: at most 1 links per day")
+
+	_, recent, err := s.CountLinks(ctx, userID, time.Now().Add(-quota.Day))
+	require.NoError(t, err)
+	require.Equal(t, 1, recent)
+}
+
+func TestStorage_QuotaConcurrentCreates(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	limits := quota.Limits{MaxLinks: 5, MaxDaily: 3}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			alias := fmt.Sprintf("link-%d", i)
+			_, err := s.SaveURL(ctx, models.AliasNote{Url: "https://example.com", Alias: alias, RedirectCode: 302}, 1, limits)
+			if err != nil {
+				assert.ErrorIs(t, err, quota.ErrExceeded)
+				return
+			}
+
+			mu.Lock()
+			created++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 3, created)
+
+	_, _, err := s.SaveURLs(ctx, []models.AliasNote{
+		{Url: "https://example.com", Alias: "batch-1", RedirectCode: 302},
+	}, 1, limits)
+	require.ErrorIs(t, err, quota.ErrExceeded)
+}
+
+func TestStorage_DomainChangesNotifyHost(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var hosts []string
+	s.OnHostChange(func(host string) { hosts = append(hosts, host) })
+
+	domain, err := s.AddDomain(ctx, 1, "my.site", "token")
+	require.NoError(t, err)
+
+	require.NoError(t, s.MarkDomainVerified(ctx, domain.ID, 1, time.Now()))
+	require.NoError(t, s.DeleteDomain(ctx, domain.ID, 1))
+	require.ErrorIs(t, s.DeleteDomain(ctx, domain.ID, 1), storage.ErrDomainNotFound)
+
+	require.Equal(t, []string{"my.site", "my.site"}, hosts)
+
+	domain, err = s.AddDomain(ctx, 1, "my.site", "token")
+	require.NoError(t, err)
+	require.NoError(t, s.MarkDomainVerified(ctx, domain.ID, 1, time.Now()))
+
+	hosts = nil
+	require.NoError(t, s.DeleteUserData(ctx, 1))
+	require.Equal(t, []string{"my.site"}, hosts)
+}
+
+func TestStorage_DomainClaims(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	squatter, err := s.AddDomain(ctx, 1, "my.site", "token1")
+	require.NoError(t, err)
+
+	owner, err := s.AddDomain(ctx, 2, "my.site", "token2")
+	require.NoError(t, err)
+
+	other, err := s.AddDomain(ctx, 3, "my.site", "token3")
+	require.NoError(t, err)
+
+	_, err = s.AddDomain(ctx, 2, "my.site", "token4")
+	require.ErrorIs(t, err, storage.ErrDomainExist)
+
+	require.NoError(t, s.MarkDomainVerified(ctx, owner.ID, 2, time.Now()))
+
+	// заявки остальных пользователей снимаются
+	_, err = s.GetDomain(ctx, squatter.ID, 1)
+	require.ErrorIs(t, err, storage.ErrDomainNotFound)
+	require.ErrorIs(t, s.MarkDomainVerified(ctx, other.ID, 3, time.Now()), storage.ErrDomainNotFound)
+
+	_, err = s.AddDomain(ctx, 1, "my.site", "token5")
+	require.ErrorIs(t, err, storage.ErrDomainExist)
+}
@@ -0,0 +1,260 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ListTags returns the user's tags by name with the number of tagged links.
+func (s *Storage) ListTags(ctx context.Context, userID int64) ([]models.Tag, error) {
+	const op = "storage.sql.ListTags"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`
+		SELECT id, name, created_at, (SELECT COUNT(*) FROM url_tags JOIN url ON url.id = url_tags.url_id WHERE url_tags.tag_id = tags.id AND url.deleted_at IS NULL)
+		FROM tags
+		WHERE user_id = ?
+		ORDER BY name`)
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.Links); err != nil {
+			return nil, s.fail(ctx, op, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+
+	return tags, nil
+}
+
+// CreateTag adds a tag of the user. It returns storage.ErrTagExist if the
+// user already has a tag with the name.
+func (s *Storage) CreateTag(ctx context.Context, userID int64, name string) (models.Tag, error) {
+	const op = "storage.sql.CreateTag"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`INSERT INTO tags(user_id, name, created_at) VALUES(?, ?, ?) RETURNING id, name, created_at`)
+
+	var tag models.Tag
+	err := s.db.QueryRowContext(ctx, query, userID, name, time.Now().UTC().Truncate(time.Microsecond)).
+		Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return models.Tag{}, fmt.Errorf("%s: %w", op, storage.ErrTagExist)
+		}
+		return models.Tag{}, s.fail(ctx, op, err)
+	}
+
+	return tag, nil
+}
+
+func (s *Storage) RenameTag(ctx context.Context, id int64, userID int64, name string) error {
+	const op = "storage.sql.RenameTag"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	result, err := s.db.ExecContext(ctx, s.ConvertQuery(`UPDATE tags SET name = ? WHERE id = ? AND user_id = ?`), name, id, userID)
+	if err != nil {
+		if storage.IsConstraintUnique(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrTagExist)
+		}
+		return s.fail(ctx, op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTagNotFound)
+	}
+
+	return nil
+}
+
+// DeleteTag removes the user's tag from all links and deletes it.
+func (s *Storage) DeleteTag(ctx context.Context, id int64, userID int64) error {
+	const op = "storage.sql.DeleteTag"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM url_tags WHERE tag_id IN (SELECT id FROM tags WHERE id = ? AND user_id = ?)`), id, userID)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	result, err := tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM tags WHERE id = ? AND user_id = ?`), id, userID)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTagNotFound)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// TagLinks adds and removes tags of the user's links in one transaction.
+// Tags to add that the user does not have yet are created. If some of the
+// links do not belong to the user, nothing is changed and
+// storage.ErrAliasNotFound is returned.
+func (s *Storage) TagLinks(ctx context.Context, userID int64, linkIDs []int64, add []string, remove []string) error {
+	const op = "storage.sql.TagLinks"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	if err := s.checkLinksOwned(ctx, tx, userID, linkIDs); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	in, ids := inList(linkIDs)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	for _, name := range add {
+		var tagID int64
+		err := tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT id FROM tags WHERE user_id = ? AND name = ?`), userID, name).Scan(&tagID)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = tx.QueryRowContext(ctx, s.ConvertQuery(`INSERT INTO tags(user_id, name, created_at) VALUES(?, ?, ?) RETURNING id`), userID, name, now).
+				Scan(&tagID)
+		}
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+
+		_, err = tx.ExecContext(ctx, s.ConvertQuery(`
+			INSERT INTO url_tags(url_id, tag_id)
+			SELECT id, ? FROM url WHERE id IN (`+in+`)
+			ON CONFLICT DO NOTHING`), append([]any{tagID}, ids...)...)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	for _, name := range remove {
+		_, err := tx.ExecContext(ctx, s.ConvertQuery(`
+			DELETE FROM url_tags
+			WHERE tag_id IN (SELECT id FROM tags WHERE user_id = ? AND name = ?) AND url_id IN (`+in+`)`),
+			append([]any{userID, name}, ids...)...)
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// linkTags returns tag names of the links by link id, sorted by name.
+func (s *Storage) linkTags(ctx context.Context, linkIDs []int64) (map[int64][]string, error) {
+	tags := make(map[int64][]string, len(linkIDs))
+	if len(linkIDs) == 0 {
+		return tags, nil
+	}
+
+	in, ids := inList(linkIDs)
+
+	rows, err := s.db.QueryContext(ctx, s.ConvertQuery(`
+		SELECT url_tags.url_id, tags.name
+		FROM url_tags
+		JOIN tags ON tags.id = url_tags.tag_id
+		WHERE url_tags.url_id IN (`+in+`)
+		ORDER BY tags.name`), ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], name)
+	}
+
+	return tags, rows.Err()
+}
+
+// checkLinksOwned returns storage.ErrAliasNotFound unless all links belong to the user.
+func (s *Storage) checkLinksOwned(ctx context.Context, q rowQuerier, userID int64, linkIDs []int64) error {
+	unique := make(map[int64]struct{}, len(linkIDs))
+	for _, id := range linkIDs {
+		unique[id] = struct{}{}
+	}
+
+	in, ids := inList(linkIDs)
+
+	var owned int
+	err := q.QueryRowContext(ctx, s.ConvertQuery(`SELECT COUNT(*) FROM url WHERE user_id = ? AND deleted_at IS NULL AND id IN (`+in+`)`), append([]any{userID}, ids...)...).
+		Scan(&owned)
+	if err != nil {
+		return err
+	}
+
+	if owned != len(unique) {
+		return storage.ErrAliasNotFound
+	}
+
+	return nil
+}
+
+// inList returns placeholders for an IN (...) list of the ids and the ids as query arguments.
+func inList(ids []int64) (string, []any) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
@@ -0,0 +1,123 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ExportUserLinks calls fn for every link of the user together with its click
+// total, oldest first. Rows are read one at a time, so the links are never
+// held in memory together. An error returned by fn stops the export.
+func (s *Storage) ExportUserLinks(ctx context.Context, userID int64, fn func(models.LinkItem) error) error {
+	const op = "storage.sql.ExportUserLinks"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`
+		SELECT ` + noteColumns + `, (SELECT COUNT(*) FROM clicks WHERE clicks.url_id = url.id) AS clicks
+		FROM url
+		WHERE user_id = ? AND deleted_at IS NULL
+		ORDER BY id`)
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.LinkItem
+
+		item.AliasNote, err = scanNote(extraScanner{row: rows, extra: []any{&item.Clicks}})
+		if err != nil {
+			return s.fail(ctx, op, err)
+		}
+
+		if err := fn(item); err != nil {
+			return s.fail(ctx, op, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// GetAliasOwner returns the id of the user the alias on the domain belongs to.
+// An empty domain stands for links without a custom domain.
+func (s *Storage) GetAliasOwner(ctx context.Context, domain string, alias string) (int64, error) {
+	const op = "storage.sql.GetAliasOwner"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`SELECT user_id FROM url WHERE alias = ? AND ` + domainCondition)
+
+	var userID int64
+	err := s.db.QueryRowContext(ctx, query, alias, domain).Scan(&userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	case err != nil:
+		return 0, s.fail(ctx, op, err)
+	}
+
+	return userID, nil
+}
+
+// OverwriteLink replaces the settings of the user's link with the domain and
+// alias of the note. The password, the metadata and the click history of
+// the link are kept. A link in the trash is restored.
+func (s *Storage) OverwriteLink(ctx context.Context, note models.AliasNote, userID int64) (int64, error) {
+	const op = "storage.sql.OverwriteLink"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	query := s.ConvertQuery(`
+		UPDATE url SET url = ?, redirect_code = ?, expires_at = ?, max_clicks = ?, clicks_used = ?, updated_at = ?, deleted_at = NULL
+		WHERE alias = ? AND ` + domainCondition + ` AND user_id = ?
+		RETURNING id`)
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		note.Url,
+		note.RedirectCode,
+		utcOrNil(note.ExpiresAt),
+		note.MaxClicks,
+		note.ClicksUsed,
+		time.Now().UTC().Truncate(time.Microsecond),
+		note.Alias,
+		note.Domain,
+		userID,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		exists, err := s.aliasExists(ctx, note.Domain, note.Alias)
+		if err != nil {
+			return 0, s.fail(ctx, op, err)
+		}
+		if exists {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrAliasNotOwned)
+		}
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	}
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	aliases, err := s.linkAliases(ctx, s.db, `id = ?`, id)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+	s.notifyAliases(aliases)
+
+	return id, nil
+}
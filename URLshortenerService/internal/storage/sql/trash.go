@@ -0,0 +1,167 @@
+package sql
+
+import (
+	"URLshortener/internal/domain/models"
+	"URLshortener/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ListTrash returns the user's links in the trash, most recently deleted first.
+func (s *Storage) ListTrash(ctx context.Context, userID int64) ([]models.AliasNote, error) {
+	const op = "storage.sql.ListTrash"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx, s.ConvertQuery(`
+		SELECT `+noteColumns+` FROM url
+		WHERE user_id = ? AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC`), userID)
+	if err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+	defer rows.Close()
+
+	notes := []models.AliasNote{}
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, s.fail(ctx, op, err)
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, s.fail(ctx, op, err)
+	}
+
+	return notes, nil
+}
+
+// RestoreLink takes the user's link out of the trash and returns it.
+func (s *Storage) RestoreLink(ctx context.Context, id int64, userID int64) (models.AliasNote, error) {
+	const op = "storage.sql.RestoreLink"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, s.ConvertQuery(`
+		UPDATE url SET deleted_at = NULL, updated_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL`),
+		time.Now().UTC().Truncate(time.Microsecond), id, userID)
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	if rowsAffected == 0 {
+		return models.AliasNote{}, fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	}
+
+	note, err := scanNote(tx.QueryRowContext(ctx, s.ConvertQuery(`SELECT `+noteColumns+` FROM url WHERE id = ?`), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AliasNote{}, fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	}
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	aliases, err := s.linkAliases(ctx, tx, `id = ?`, id)
+	if err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.AliasNote{}, s.fail(ctx, op, err)
+	}
+
+	s.notifyAliases(aliases)
+
+	return note, nil
+}
+
+// PurgeLink permanently removes the user's link from the trash together with
+// its clicks, tags, old aliases and revisions. The alias becomes free.
+func (s *Storage) PurgeLink(ctx context.Context, id int64, userID int64) error {
+	const op = "storage.sql.PurgeLink"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	purged, err := s.purgeLinks(ctx, tx, `id = ? AND user_id = ? AND deleted_at IS NOT NULL`, id, userID)
+	if err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	if purged == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return s.fail(ctx, op, err)
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently removes links moved to the trash before the time.
+func (s *Storage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	const op = "storage.sql.PurgeTrash"
+
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+	defer tx.Rollback()
+
+	purged, err := s.purgeLinks(ctx, tx, `deleted_at IS NOT NULL AND deleted_at <= ?`, before.UTC())
+	if err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, s.fail(ctx, op, err)
+	}
+
+	return purged, nil
+}
+
+// purgeLinks deletes the links matching the condition and everything that
+// refers to them. It returns the number of deleted links.
+func (s *Storage) purgeLinks(ctx context.Context, tx *sql.Tx, cond string, args ...any) (int64, error) {
+	for _, table := range []string{"clicks", "url_tags", "alias_redirects", "link_revisions"} {
+		_, err := tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM `+table+` WHERE url_id IN (SELECT id FROM url WHERE `+cond+`)`), args...)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, s.ConvertQuery(`DELETE FROM url WHERE `+cond), args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
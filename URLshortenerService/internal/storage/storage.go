@@ -10,8 +10,42 @@ var (
 	ErrAliasNotFound = errors.New("alias not found")
 	ErrURLNotFound   = errors.New("url not found")
 	ErrAliasExist    = errors.New("alias exist")
+	ErrAliasNotOwned = errors.New("alias belongs to another user")
+	ErrLinkExpired   = errors.New("link expired")
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	ErrDomainNotFound    = errors.New("domain not found")
+	ErrDomainExist       = errors.New("domain exist")
+	ErrDomainNotVerified = errors.New("domain is not verified")
+	ErrDomainInUse       = errors.New("domain has links")
+
+	ErrTagNotFound    = errors.New("tag not found")
+	ErrTagExist       = errors.New("tag exist")
+	ErrFolderNotFound = errors.New("folder not found")
+	ErrFolderExist    = errors.New("folder exist")
+	ErrFolderCycle    = errors.New("folder cannot be moved into itself")
+
+	ErrRevisionNotFound = errors.New("revision not found")
 )
 
+// IsExpected reports whether err is one of the errors above. They describe
+// the state of the data, not a failure of the storage.
+func IsExpected(err error) bool {
+	for _, expected := range expectedErrors {
+		if errors.Is(err, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+var expectedErrors = []error{
+	ErrAliasNotFound, ErrURLNotFound, ErrAliasExist, ErrAliasNotOwned, ErrLinkExpired, ErrInvalidCursor,
+	ErrDomainNotFound, ErrDomainExist, ErrDomainNotVerified, ErrDomainInUse,
+	ErrTagNotFound, ErrTagExist, ErrFolderNotFound, ErrFolderExist, ErrFolderCycle,
+	ErrRevisionNotFound,
+}
+
 func IsConstraintUnique(err error) bool {
 	var sqliteErr sqlite3.Error
 	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
@@ -0,0 +1,8 @@
+// Package migrations embeds the schema migrations, so the binary can apply
+// them itself. Every driver has its own directory.
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -7,5 +7,5 @@ CREATE TABLE IF NOT EXISTS url (
 );
 
 CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
-CREATE INDEX IF NOT EXISTS idx_user_id ON url(user_id)
+CREATE INDEX IF NOT EXISTS idx_user_id ON url(user_id);
 
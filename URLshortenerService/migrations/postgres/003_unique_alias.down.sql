@@ -0,0 +1,10 @@
+ALTER TABLE url DROP CONSTRAINT IF EXISTS uq_alias;
+
+UPDATE url SET alias = r.old_alias
+FROM alias_renames r
+WHERE url.id = r.url_id;
+
+ALTER TABLE url ADD CONSTRAINT uq_alias_user UNIQUE (alias, user_id);
+CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
+
+DROP TABLE IF EXISTS alias_renames;
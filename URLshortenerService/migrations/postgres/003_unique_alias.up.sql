@@ -0,0 +1,28 @@
+-- Отчёт о переименованных алиасах: владельцы дубликатов должны узнать новые имена
+CREATE TABLE IF NOT EXISTS alias_renames (
+    id SERIAL PRIMARY KEY,
+    url_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    old_alias TEXT NOT NULL,
+    new_alias TEXT NOT NULL,
+    renamed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Самая старая ссылка сохраняет алиас, остальные получают суффикс -<id>.
+-- Если новое имя тоже занято, миграция упадёт на уникальном ограничении ниже.
+INSERT INTO alias_renames (url_id, user_id, old_alias, new_alias)
+SELECT id, user_id, alias, alias || '-' || id
+FROM (
+    SELECT id, user_id, alias, ROW_NUMBER() OVER (PARTITION BY alias ORDER BY id) AS rn
+    FROM url
+) duplicates
+WHERE rn > 1;
+
+UPDATE url SET alias = r.new_alias
+FROM alias_renames r
+WHERE url.id = r.url_id;
+
+ALTER TABLE url DROP CONSTRAINT IF EXISTS uq_alias_user;
+ALTER TABLE url ADD CONSTRAINT uq_alias UNIQUE (alias);
+
+DROP INDEX IF EXISTS idx_alias;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_clicks_url_id_clicked_at;
+
+DROP TABLE IF EXISTS clicks;
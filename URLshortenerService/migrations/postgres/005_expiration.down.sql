@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_url_archive_user_id;
+DROP TABLE IF EXISTS url_archive;
+
+DROP INDEX IF EXISTS idx_url_expires_at;
+
+ALTER TABLE url DROP COLUMN IF EXISTS clicks_used;
+ALTER TABLE url DROP COLUMN IF EXISTS max_clicks;
+ALTER TABLE url DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,19 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NULL;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS max_clicks INTEGER NULL;
+ALTER TABLE url ADD COLUMN IF NOT EXISTS clicks_used INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+
+CREATE TABLE IF NOT EXISTS url_archive (
+    id INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    alias TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    redirect_code INTEGER NOT NULL,
+    expires_at TIMESTAMP NULL,
+    max_clicks INTEGER NULL,
+    clicks_used INTEGER NOT NULL,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_archive_user_id ON url_archive(user_id);
@@ -0,0 +1,6 @@
+CREATE INDEX IF NOT EXISTS idx_user_id ON url(user_id);
+
+DROP INDEX IF EXISTS idx_url_user_id_alias;
+DROP INDEX IF EXISTS idx_url_user_id_created_at;
+
+ALTER TABLE url DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,6 @@
+ALTER TABLE url ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc');
+
+CREATE INDEX IF NOT EXISTS idx_url_user_id_created_at ON url(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_url_user_id_alias ON url(user_id, alias);
+
+DROP INDEX IF EXISTS idx_user_id;
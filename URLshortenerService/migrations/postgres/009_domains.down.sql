@@ -0,0 +1,12 @@
+-- Ссылки на собственных доменах не укладываются в глобально уникальные алиасы и удаляются
+DELETE FROM clicks WHERE url_id IN (SELECT id FROM url WHERE domain_id IS NOT NULL);
+DELETE FROM url WHERE domain_id IS NOT NULL;
+
+DROP INDEX IF EXISTS uq_url_domain_alias;
+ALTER TABLE url ADD CONSTRAINT uq_alias UNIQUE (alias);
+
+ALTER TABLE url_archive DROP COLUMN IF EXISTS domain_id;
+ALTER TABLE url DROP COLUMN IF EXISTS domain_id;
+
+DROP INDEX IF EXISTS idx_domains_user_id;
+DROP TABLE IF EXISTS domains;
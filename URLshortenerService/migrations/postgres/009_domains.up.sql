@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS domains (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    host TEXT NOT NULL,
+    token TEXT NOT NULL,
+    verified_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    CONSTRAINT uq_domains_host UNIQUE (host)
+);
+
+CREATE INDEX IF NOT EXISTS idx_domains_user_id ON domains(user_id);
+
+ALTER TABLE url ADD COLUMN IF NOT EXISTS domain_id INTEGER NULL REFERENCES domains(id);
+ALTER TABLE url_archive ADD COLUMN IF NOT EXISTS domain_id INTEGER NULL;
+
+-- Алиас уникален в пределах домена, ссылки без домена живут на основном
+ALTER TABLE url DROP CONSTRAINT IF EXISTS uq_alias;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_url_domain_alias ON url((COALESCE(domain_id, 0)), alias);
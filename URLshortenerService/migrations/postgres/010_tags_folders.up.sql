@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS folders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    parent_id INTEGER NULL REFERENCES folders(id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+-- Имена уникальны среди соседей, папки верхнего уровня соседствуют друг с другом
+CREATE UNIQUE INDEX IF NOT EXISTS uq_folders_user_parent_name ON folders(user_id, (COALESCE(parent_id, 0)), name);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    CONSTRAINT uq_tags_user_name UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS url_tags (
+    url_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL REFERENCES tags(id),
+    PRIMARY KEY (url_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_tags_tag_id ON url_tags(tag_id);
+
+ALTER TABLE url ADD COLUMN IF NOT EXISTS folder_id INTEGER NULL;
+CREATE INDEX IF NOT EXISTS idx_url_folder_id ON url(folder_id);
@@ -0,0 +1,4 @@
+ALTER TABLE url DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE url DROP COLUMN IF EXISTS notes;
+ALTER TABLE url DROP COLUMN IF EXISTS description;
+ALTER TABLE url DROP COLUMN IF EXISTS title;
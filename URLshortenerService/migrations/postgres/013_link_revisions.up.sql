@@ -0,0 +1,15 @@
+-- История изменений ссылок, строки не меняются и не удаляются до удаления ссылки
+CREATE TABLE IF NOT EXISTS link_revisions (
+    id SERIAL PRIMARY KEY,
+    url_id INTEGER NOT NULL,
+    change TEXT NOT NULL,
+    old_url TEXT NOT NULL,
+    new_url TEXT NOT NULL,
+    old_alias TEXT NOT NULL,
+    new_alias TEXT NOT NULL,
+    actor_id INTEGER NOT NULL,
+    actor_role TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_revisions_url_id ON link_revisions(url_id);
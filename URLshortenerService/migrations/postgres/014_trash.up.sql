@@ -0,0 +1,4 @@
+-- Удаленные ссылки лежат в корзине до очистки, алиас за ними сохраняется
+ALTER TABLE url ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_url_deleted_at ON url(deleted_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_url_user_id_inserted_at;
+ALTER TABLE url DROP COLUMN IF EXISTS inserted_at;
@@ -0,0 +1,8 @@
+-- created_at приходит из импорта, дневная квота считается по времени вставки,
+-- которое ставит сервер. Существующие ссылки заполняются по created_at
+ALTER TABLE url ADD COLUMN IF NOT EXISTS inserted_at TIMESTAMP NULL;
+UPDATE url SET inserted_at = created_at WHERE inserted_at IS NULL;
+ALTER TABLE url ALTER COLUMN inserted_at SET DEFAULT (now() AT TIME ZONE 'utc');
+ALTER TABLE url ALTER COLUMN inserted_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_url_user_id_inserted_at ON url(user_id, inserted_at);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS uq_domains_verified_host;
+DROP INDEX IF EXISTS uq_domains_user_host;
+
+-- Из параллельных заявок остаётся подтверждённая или самая ранняя
+DELETE FROM domains d
+WHERE EXISTS (
+    SELECT 1 FROM domains o
+    WHERE o.host = d.host AND o.id <> d.id
+      AND (o.verified_at IS NOT NULL OR (d.verified_at IS NULL AND o.id < d.id))
+);
+
+ALTER TABLE domains ADD CONSTRAINT uq_domains_host UNIQUE (host);
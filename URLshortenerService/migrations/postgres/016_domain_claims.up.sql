@@ -0,0 +1,5 @@
+-- Хост может заявить любой пользователь, уникален только подтверждённый.
+-- Иначе чужая неподтверждённая заявка навсегда закрывала домен владельцу
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS uq_domains_host;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_domains_user_host ON domains(user_id, host);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_domains_verified_host ON domains(host) WHERE verified_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS link_quotas;
+DROP INDEX IF EXISTS idx_link_creations_user_id_created_at;
+DROP TABLE IF EXISTS link_creations;
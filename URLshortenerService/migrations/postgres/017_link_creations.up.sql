@@ -0,0 +1,17 @@
+-- Дневная квота считается по журналу созданий: удалённая ссылка из него
+-- не пропадает и не возвращает квоту
+CREATE TABLE IF NOT EXISTS link_creations (
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_creations_user_id_created_at ON link_creations(user_id, created_at);
+
+INSERT INTO link_creations (user_id, created_at)
+SELECT user_id, inserted_at
+FROM url;
+
+-- Строка пользователя блокируется на время проверки квоты и вставки ссылок
+CREATE TABLE IF NOT EXISTS link_quotas (
+    user_id INTEGER PRIMARY KEY
+);
@@ -0,0 +1,21 @@
+CREATE TABLE url_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    alias TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    redirect_code INTEGER NOT NULL DEFAULT 302,
+    CONSTRAINT uq_alias_user UNIQUE (alias, user_id)
+);
+
+INSERT INTO url_old (id, url, alias, user_id, redirect_code)
+SELECT u.id, u.url, COALESCE(r.old_alias, u.alias), u.user_id, u.redirect_code
+FROM url u
+LEFT JOIN alias_renames r ON r.url_id = u.id;
+
+DROP TABLE url;
+ALTER TABLE url_old RENAME TO url;
+
+CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
+CREATE INDEX IF NOT EXISTS idx_user_id ON url(user_id);
+
+DROP TABLE IF EXISTS alias_renames;
@@ -0,0 +1,39 @@
+-- Отчёт о переименованных алиасах: владельцы дубликатов должны узнать новые имена
+CREATE TABLE IF NOT EXISTS alias_renames (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    old_alias TEXT NOT NULL,
+    new_alias TEXT NOT NULL,
+    renamed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Самая старая ссылка сохраняет алиас, остальные получают суффикс -<id>.
+-- Если новое имя тоже занято, миграция упадёт на уникальном ограничении ниже.
+INSERT INTO alias_renames (url_id, user_id, old_alias, new_alias)
+SELECT id, user_id, alias, alias || '-' || id
+FROM (
+    SELECT id, user_id, alias, ROW_NUMBER() OVER (PARTITION BY alias ORDER BY id) AS rn
+    FROM url
+) duplicates
+WHERE rn > 1;
+
+-- SQLite не умеет менять ограничения, поэтому таблица пересоздаётся
+CREATE TABLE url_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    alias TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    redirect_code INTEGER NOT NULL DEFAULT 302,
+    CONSTRAINT uq_alias UNIQUE (alias)
+);
+
+INSERT INTO url_new (id, url, alias, user_id, redirect_code)
+SELECT u.id, u.url, COALESCE(r.new_alias, u.alias), u.user_id, u.redirect_code
+FROM url u
+LEFT JOIN alias_renames r ON r.url_id = u.id;
+
+DROP TABLE url;
+ALTER TABLE url_new RENAME TO url;
+
+CREATE INDEX IF NOT EXISTS idx_user_id ON url(user_id);
@@ -0,0 +1,9 @@
+-- SQLite не умеет добавлять столбец с неконстантным значением по умолчанию,
+-- поэтому существующие ссылки заполняются отдельно, а новые пишет сервис
+ALTER TABLE url ADD COLUMN created_at TIMESTAMP NULL;
+UPDATE url SET created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+
+CREATE INDEX IF NOT EXISTS idx_url_user_id_created_at ON url(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_url_user_id_alias ON url(user_id, alias);
+
+DROP INDEX IF EXISTS idx_user_id;
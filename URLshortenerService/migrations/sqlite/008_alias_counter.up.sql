@@ -0,0 +1,7 @@
+-- Счётчик последовательных алиасов, одна строка
+CREATE TABLE IF NOT EXISTS alias_counter (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    value BIGINT NOT NULL
+);
+
+INSERT INTO alias_counter (id, value) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;
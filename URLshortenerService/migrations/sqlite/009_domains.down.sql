@@ -0,0 +1,33 @@
+-- Ссылки на собственных доменах не укладываются в глобально уникальные алиасы и удаляются
+DELETE FROM clicks WHERE url_id IN (SELECT id FROM url WHERE domain_id IS NOT NULL);
+
+CREATE TABLE url_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    alias TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    redirect_code INTEGER NOT NULL DEFAULT 302,
+    expires_at TIMESTAMP NULL,
+    max_clicks INTEGER NULL,
+    clicks_used INTEGER NOT NULL DEFAULT 0,
+    password_hash BLOB NULL,
+    created_at TIMESTAMP NULL,
+    CONSTRAINT uq_alias UNIQUE (alias)
+);
+
+INSERT INTO url_old (id, url, alias, user_id, redirect_code, expires_at, max_clicks, clicks_used, password_hash, created_at)
+SELECT id, url, alias, user_id, redirect_code, expires_at, max_clicks, clicks_used, password_hash, created_at
+FROM url
+WHERE domain_id IS NULL;
+
+DROP TABLE url;
+ALTER TABLE url_old RENAME TO url;
+
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+CREATE INDEX IF NOT EXISTS idx_url_user_id_created_at ON url(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_url_user_id_alias ON url(user_id, alias);
+
+ALTER TABLE url_archive DROP COLUMN domain_id;
+
+DROP INDEX IF EXISTS idx_domains_user_id;
+DROP TABLE IF EXISTS domains;
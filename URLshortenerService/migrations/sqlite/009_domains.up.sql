@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS domains (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    host TEXT NOT NULL,
+    token TEXT NOT NULL,
+    verified_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_domains_host UNIQUE (host)
+);
+
+CREATE INDEX IF NOT EXISTS idx_domains_user_id ON domains(user_id);
+
+ALTER TABLE url_archive ADD COLUMN domain_id INTEGER NULL;
+
+-- SQLite не умеет удалять ограничения, поэтому таблица пересоздаётся
+CREATE TABLE url_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    alias TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    redirect_code INTEGER NOT NULL DEFAULT 302,
+    expires_at TIMESTAMP NULL,
+    max_clicks INTEGER NULL,
+    clicks_used INTEGER NOT NULL DEFAULT 0,
+    password_hash BLOB NULL,
+    created_at TIMESTAMP NULL,
+    domain_id INTEGER NULL REFERENCES domains(id)
+);
+
+INSERT INTO url_new (id, url, alias, user_id, redirect_code, expires_at, max_clicks, clicks_used, password_hash, created_at)
+SELECT id, url, alias, user_id, redirect_code, expires_at, max_clicks, clicks_used, password_hash, created_at
+FROM url;
+
+DROP TABLE url;
+ALTER TABLE url_new RENAME TO url;
+
+-- Алиас уникален в пределах домена, ссылки без домена живут на основном
+CREATE UNIQUE INDEX IF NOT EXISTS uq_url_domain_alias ON url(COALESCE(domain_id, 0), alias);
+CREATE INDEX IF NOT EXISTS idx_url_expires_at ON url(expires_at);
+CREATE INDEX IF NOT EXISTS idx_url_user_id_created_at ON url(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_url_user_id_alias ON url(user_id, alias);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_url_folder_id;
+ALTER TABLE url DROP COLUMN folder_id;
+
+DROP INDEX IF EXISTS idx_url_tags_tag_id;
+DROP TABLE IF EXISTS url_tags;
+DROP TABLE IF EXISTS tags;
+
+DROP INDEX IF EXISTS uq_folders_user_parent_name;
+DROP TABLE IF EXISTS folders;
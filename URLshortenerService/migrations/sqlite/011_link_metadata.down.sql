@@ -0,0 +1,4 @@
+ALTER TABLE url DROP COLUMN updated_at;
+ALTER TABLE url DROP COLUMN notes;
+ALTER TABLE url DROP COLUMN description;
+ALTER TABLE url DROP COLUMN title;
@@ -0,0 +1,7 @@
+ALTER TABLE url ADD COLUMN title TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE url ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+-- Как и created_at, новые ссылки заполняет сервис,
+-- а существующие не менялись с момента создания
+ALTER TABLE url ADD COLUMN updated_at TIMESTAMP NULL;
+UPDATE url SET updated_at = created_at;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_alias_redirects_url_id;
+DROP INDEX IF EXISTS uq_alias_redirects_domain_alias;
+DROP TABLE IF EXISTS alias_redirects;
@@ -0,0 +1,12 @@
+-- Старые алиасы переименованных ссылок, которые еще ведут на ссылку
+CREATE TABLE IF NOT EXISTS alias_redirects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url_id INTEGER NOT NULL,
+    domain_id INTEGER NULL REFERENCES domains(id),
+    alias TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_alias_redirects_domain_alias ON alias_redirects(COALESCE(domain_id, 0), alias);
+CREATE INDEX IF NOT EXISTS idx_alias_redirects_url_id ON alias_redirects(url_id);
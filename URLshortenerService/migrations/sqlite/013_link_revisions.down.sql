@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_link_revisions_url_id;
+DROP TABLE IF EXISTS link_revisions;
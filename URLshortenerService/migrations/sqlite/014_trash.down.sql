@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_url_deleted_at;
+ALTER TABLE url DROP COLUMN deleted_at;
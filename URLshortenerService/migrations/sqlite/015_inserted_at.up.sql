@@ -0,0 +1,6 @@
+-- created_at приходит из импорта, дневная квота считается по времени вставки,
+-- которое пишет сервис. Существующие ссылки заполняются по created_at
+ALTER TABLE url ADD COLUMN inserted_at TIMESTAMP NULL;
+UPDATE url SET inserted_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_url_user_id_inserted_at ON url(user_id, inserted_at);
@@ -0,0 +1,26 @@
+-- Из параллельных заявок остаётся подтверждённая или самая ранняя
+DELETE FROM domains
+WHERE EXISTS (
+    SELECT 1 FROM domains o
+    WHERE o.host = domains.host AND o.id <> domains.id
+      AND (o.verified_at IS NOT NULL OR (domains.verified_at IS NULL AND o.id < domains.id))
+);
+
+CREATE TABLE domains_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    host TEXT NOT NULL,
+    token TEXT NOT NULL,
+    verified_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_domains_host UNIQUE (host)
+);
+
+INSERT INTO domains_new (id, user_id, host, token, verified_at, created_at)
+SELECT id, user_id, host, token, verified_at, created_at
+FROM domains;
+
+DROP TABLE domains;
+ALTER TABLE domains_new RENAME TO domains;
+
+CREATE INDEX IF NOT EXISTS idx_domains_user_id ON domains(user_id);
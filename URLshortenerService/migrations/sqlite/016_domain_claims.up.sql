@@ -0,0 +1,21 @@
+-- Хост может заявить любой пользователь, уникален только подтверждённый.
+-- SQLite не умеет удалять ограничения, поэтому таблица пересоздаётся
+CREATE TABLE domains_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    host TEXT NOT NULL,
+    token TEXT NOT NULL,
+    verified_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO domains_new (id, user_id, host, token, verified_at, created_at)
+SELECT id, user_id, host, token, verified_at, created_at
+FROM domains;
+
+DROP TABLE domains;
+ALTER TABLE domains_new RENAME TO domains;
+
+CREATE INDEX IF NOT EXISTS idx_domains_user_id ON domains(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_domains_user_host ON domains(user_id, host);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_domains_verified_host ON domains(host) WHERE verified_at IS NOT NULL;
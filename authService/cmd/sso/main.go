@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -21,6 +22,11 @@ func main() {
 
 	cfg := config.MustLoad()
 
+	// sso migrate up|down|status|version
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(cfg, flag.Args()[1:]))
+	}
+
 	log := setupLogger(cfg.Env)
 
 	log.Info("starting application",
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sso/internal/config"
+	"sso/internal/migrator"
+	"sso/migrations"
+	"strconv"
+)
+
+const migrateUsage = `usage: sso [-config path] migrate <command>
+
+commands:
+  up        apply all pending migrations
+  down [n]  roll back the last n migrations, 1 by default
+  status    list the migrations and whether they are applied
+  version   print the version of the schema
+
+Every command runs on the main and then on the sessions database.`
+
+type database struct {
+	name       string
+	driver     string
+	connString string
+	migrations fs.FS
+}
+
+// runMigrate runs the migrate subcommand and returns the exit code.
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	steps := 1
+	switch args[0] {
+	case "up", "status", "version":
+	case "down":
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	databases := []database{
+		{name: "main", driver: cfg.MainStorageDBDriver, connString: cfg.MainStorageConnString, migrations: migrations.Main},
+		{name: "sessions", driver: cfg.SessionsStorageDBDriver, connString: cfg.SessionsStorageConnString, migrations: migrations.Sessions},
+	}
+
+	for _, db := range databases {
+		if err := migrateDatabase(db, args[0], steps); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", db.name, err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func migrateDatabase(db database, command string, steps int) error {
+	m, err := migrator.New(db.driver, db.connString, db.migrations)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch command {
+	case "up":
+		return m.Up()
+	case "down":
+		return m.Down(steps)
+	case "status":
+		status, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, migration := range status {
+			state := "pending"
+			if migration.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%s\t%d_%s\t%s\n", db.name, migration.Version, migration.Name, state)
+		}
+	case "version":
+		version, dirty, err := m.Version()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%d", db.name, version)
+		if dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -2,22 +2,33 @@ package grpcapp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io/fs"
 	"log/slog"
 	"net"
 	"net/http"
 	"sso/gen/go/sso"
 	"sso/internal/config"
 	authgrpc "sso/internal/grpc/auth"
+	"sso/internal/grpc/health"
 	"sso/internal/grpc/interceptors/authorization"
 	"sso/internal/http/urlServiceSender"
+	"sso/internal/lib/buildinfo"
 	jwtlib "sso/internal/lib/jwt"
+	"sso/internal/lib/tracing"
+	"sso/internal/migrator"
 	"sso/internal/services/auth"
 	"sso/internal/storage/sql"
+	"sso/migrations"
 	"time"
 )
 
@@ -27,10 +38,35 @@ type App struct {
 	port           int
 	gatewayServer  *http.Server
 	gatewayEnabled bool
+	healthConn     *grpc.ClientConn
+	stopTracing    func(context.Context) error
+	healthServer   *grpchealth.Server
+	stopHealth     func()
 }
 
 func New(log *slog.Logger, cfg *config.Config) *App {
 
+	stopTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: "auth-service",
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if cfg.AutoMigrate {
+		if err := migrate(log, "main", cfg.MainStorageDBDriver, cfg.MainStorageConnString, migrations.Main); err != nil {
+			panic(err)
+		}
+		if err := migrate(log, "sessions", cfg.SessionsStorageDBDriver, cfg.SessionsStorageConnString, migrations.Sessions); err != nil {
+			panic(err)
+		}
+	}
+
 	mainStorage, err := sql.New(cfg.MainStorageDBDriver, cfg.MainStorageConnString)
 	if err != nil {
 		panic(err)
@@ -47,39 +83,74 @@ func New(log *slog.Logger, cfg *config.Config) *App {
 
 	authService := auth.New(log, mainStorage, tokenManager, sessionStorage, urlServiceManager)
 
+	// stats handler продолжает трассу из метаданных запроса, в том числе пришедшего через gateway
 	gRPCServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.UnaryInterceptor(authorization.NewJWTInterceptor(log, tokenManager)),
 	)
 
 	authgrpc.Register(gRPCServer, authService)
 
+	// Сервис считается готовым, пока отвечают обе базы
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(gRPCServer, healthServer)
+	stopHealth := health.Watch(log, healthServer, cfg.Health.CheckInterval, cfg.Health.Timeout,
+		health.Check{Name: "main", Pinger: mainStorage},
+		health.Check{Name: "sessions", Pinger: sessionStorage},
+	)
+
 	var gatewaySrv *http.Server
+	var healthConn *grpc.ClientConn
 	gatewayEnabled := false
 	if cfg.Gateway.Enabled {
 		gatewayEnabled = true
 
 		ctx := context.Background()
 
-		// спец. мультиплексор grpc-gateway
-		mux := runtime.NewServeMux()
-
 		opts := []grpc.DialOption{
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		}
+
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port)
+
+		healthConn, err = grpc.NewClient(grpcAddr, opts...)
+		if err != nil {
+			panic(err)
 		}
 
-		err := sso.RegisterAuthHandlerFromEndpoint(
+		// спец. мультиплексор grpc-gateway
+		// /healthz?service=auth.Auth проксирует grpc.health.v1.Health/Check
+		mux := runtime.NewServeMux(
+			runtime.WithHealthzEndpoint(healthpb.NewHealthClient(healthConn)),
+		)
+
+		err = sso.RegisterAuthHandlerFromEndpoint(
 			ctx,
 			mux,
-			fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port),
+			grpcAddr,
 			opts,
 		)
 		if err != nil {
 			panic(err)
 		}
 
+		version, err := json.Marshal(buildinfo.Get())
+		if err != nil {
+			panic(err)
+		}
+
+		err = mux.HandlePath(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(version)
+		})
+		if err != nil {
+			panic(err)
+		}
+
 		gatewaySrv = &http.Server{
 			Addr:         fmt.Sprintf(":%d", cfg.Gateway.Port),
-			Handler:      mux,
+			Handler:      otelhttp.NewHandler(mux, "auth-gateway"),
 			ReadTimeout:  cfg.Gateway.Timeout,
 			WriteTimeout: cfg.Gateway.Timeout,
 			IdleTimeout:  cfg.Gateway.IdleTimeout,
@@ -93,9 +164,37 @@ func New(log *slog.Logger, cfg *config.Config) *App {
 		port:           cfg.GRPC.Port,
 		gatewayServer:  gatewaySrv,
 		gatewayEnabled: gatewayEnabled,
+		healthConn:     healthConn,
+		stopTracing:    stopTracing,
+		healthServer:   healthServer,
+		stopHealth:     stopHealth,
 	}
 }
 
+// migrate applies the pending migrations of the database. Replicas starting
+// together wait for each other on the migration lock.
+func migrate(log *slog.Logger, name string, driver string, connString string, source fs.FS) error {
+	const op = "grpcapp.migrate"
+
+	m, err := migrator.New(driver, connString, source)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", op, name, err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return fmt.Errorf("%s: %s: %w", op, name, err)
+	}
+
+	version, _, err := m.Version()
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", op, name, err)
+	}
+	log.Info("database is migrated", slog.String("database", name), slog.Uint64("version", uint64(version)))
+
+	return nil
+}
+
 func (a *App) MustRun() {
 	if err := a.run(); err != nil {
 		panic(err)
@@ -158,6 +257,10 @@ func (a *App) Stop() {
 	a.log.With(slog.String("op", op)).
 		Info("stopping grpc server", slog.Int("port", a.port))
 
+	// Пробы видят NOT_SERVING, пока сервер дорабатывает запросы
+	a.healthServer.Shutdown()
+	a.stopHealth()
+
 	if a.gatewayEnabled {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -167,6 +270,18 @@ func (a *App) Stop() {
 		} else {
 			a.log.Info("gateway server is stopped")
 		}
+
+		// Соединение /healthz закрывается, когда gateway уже не принимает запросы
+		if err := a.healthConn.Close(); err != nil {
+			a.log.Error("failed to close health connection", slog.String("error", err.Error()))
+		}
 	}
 	a.gRPCServer.GracefulStop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := a.stopTracing(shutdownCtx); err != nil {
+		a.log.Error("failed to flush spans", slog.String("error", err.Error()))
+	}
 }
@@ -8,22 +8,30 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
 	"os"
+	"path/filepath"
 	"sso/internal/lib/api"
 	"time"
 )
 
 type Config struct {
 	Env                       string        `yaml:"env" env-default:"local"`
-	MainStorageDBDriver       string        `yaml:"mainStorage_db_driver"`
+	MainStorageDBDriver       string        `yaml:"mainStorage_db_driver" env-default:"postgres"`
 	MainStorageConnString     string        `yaml:"mainStorage_conn_string"`
-	SessionsStorageDBDriver   string        `yaml:"sessionsStorage_db_driver"`
+	MainStoragePath           string        `yaml:"mainStorage_path" env:"DB_USERS_PATH"`
+	SessionsStorageDBDriver   string        `yaml:"sessionsStorage_db_driver" env-default:"postgres"`
 	SessionsStorageConnString string        `yaml:"sessionsStorage_conn_string"`
+	SessionsStoragePath       string        `yaml:"sessionsStorage_path" env:"DB_SESSIONS_PATH"`
 	Secret                    string        `yaml:"secret"`
 	AccessTokenTTL            time.Duration `yaml:"access_token_ttl" env-required:"true"`
 	RefreshTokenTTL           time.Duration `yaml:"refresh_token_ttl" env-required:"true"`
 	GRPC                      GRPCConfig    `yaml:"grpc"`
 	Gateway                   GatewayConfig `yaml:"gateway"`
 	UrlService                UrlService    `yaml:"urlService"`
+	Tracing                   Tracing       `yaml:"tracing"`
+	Health                    HealthConfig  `yaml:"health"`
+
+	// AutoMigrate applies the pending migrations of both databases on start
+	AutoMigrate bool `yaml:"auto_migrate" env:"AUTO_MIGRATE" env-default:"false"`
 }
 
 type DBInitData struct {
@@ -52,6 +60,24 @@ type UrlService struct {
 	Port int    `yaml:"port"`
 }
 
+// HealthConfig sets how often the storages are pinged for grpc.health.v1.
+type HealthConfig struct {
+	CheckInterval time.Duration `yaml:"check_interval" env-default:"10s"`
+	Timeout       time.Duration `yaml:"timeout" env-default:"2s"`
+}
+
+// Tracing exports spans to stdout or to a file for local runs and to an
+// OTLP collector in production.
+type Tracing struct {
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none" validate:"oneof=none stdout otlp"`
+	// File receives the spans of the stdout exporter, empty means stdout
+	File string `yaml:"file" env:"TRACING_FILE"`
+	// Endpoint is the host:port of the OTLP gRPC collector
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env-default:"true"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1" validate:"min=0,max=1"`
+}
+
 func MustLoad() *Config {
 	configPath := fetchConfigPath()
 	if configPath == "" {
@@ -72,64 +98,64 @@ func MustLoadByPath(configPath string) *Config {
 		log.Fatalf("cannot read config: %v", err)
 	}
 
-	main_db := DBInitData{
-		DB_NAME:     os.Getenv("DB_USERS_NAME"),
-		DB_USERNAME: os.Getenv("DB_USERS_USERNAME"),
-		DB_PASSWORD: os.Getenv("DB_USERS_PASSWORD"),
-		DB_HOST:     os.Getenv("DB_USERS_HOST"),
-		DB_PORT:     os.Getenv("DB_USERS_PORT"),
+	if err := validator.New().Struct(cfg.Tracing); err != nil {
+		log.Fatalf("invalid tracing config: %v", err)
 	}
 
-	sessions_db := DBInitData{
-		DB_NAME:     os.Getenv("DB_SESSIONS_NAME"),
-		DB_USERNAME: os.Getenv("DB_SESSIONS_USERNAME"),
-		DB_PASSWORD: os.Getenv("DB_SESSIONS_PASSWORD"),
-		DB_HOST:     os.Getenv("DB_SESSIONS_HOST"),
-		DB_PORT:     os.Getenv("DB_SESSIONS_PORT"),
+	cfg.MainStorageConnString = connString("main", cfg.MainStorageDBDriver, cfg.MainStoragePath, "DB_USERS")
+	cfg.SessionsStorageConnString = connString("sessions", cfg.SessionsStorageDBDriver, cfg.SessionsStoragePath, "DB_SESSIONS")
+
+	secretKey := os.Getenv("SECRET_KEY")
+	if secretKey == "" {
+		log.Fatalf("env SECRET_KEY is required")
 	}
 
-	if err := validator.New().Struct(main_db); err != nil {
-		var validateErr validator.ValidationErrors
-		if !errors.As(err, &validateErr) {
-			log.Fatalf("cannot to validate main_db init data")
+	cfg.Secret = secretKey
+
+	return &cfg
+}
+
+// connString returns the path to the file for the sqlite3 driver and builds
+// the postgres URL from the <envPrefix>_* variables otherwise.
+func connString(name string, driver string, path string, envPrefix string) string {
+	switch driver {
+	case "sqlite3":
+		if path == "" {
+			log.Fatalf("%sStorage_path is required for the sqlite3 driver", name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatalf("cannot create %s storage directory: %v", name, err)
 		}
+		return path
+	case "postgres":
+	default:
+		log.Fatalf("invalid %sStorage_db_driver: %s", name, driver)
+	}
 
-		log.Fatalf(api.ValidateEnvVar(validateErr))
+	db := DBInitData{
+		DB_NAME:     os.Getenv(envPrefix + "_NAME"),
+		DB_USERNAME: os.Getenv(envPrefix + "_USERNAME"),
+		DB_PASSWORD: os.Getenv(envPrefix + "_PASSWORD"),
+		DB_HOST:     os.Getenv(envPrefix + "_HOST"),
+		DB_PORT:     os.Getenv(envPrefix + "_PORT"),
 	}
 
-	if err := validator.New().Struct(sessions_db); err != nil {
+	if err := validator.New().Struct(db); err != nil {
 		var validateErr validator.ValidationErrors
 		if !errors.As(err, &validateErr) {
-			log.Fatalf("cannot to validate sessions_db init data")
+			log.Fatalf("cannot to validate %s_db init data", name)
 		}
 
-		log.Fatalf(api.ValidateEnvVar(validateErr))
+		log.Fatal(api.ValidateEnvVar(validateErr))
 	}
 
-	cfg.MainStorageConnString = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		main_db.DB_USERNAME,
-		main_db.DB_PASSWORD,
-		main_db.DB_HOST,
-		"5432",
-		main_db.DB_NAME,
-	)
-
-	cfg.SessionsStorageConnString = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		sessions_db.DB_USERNAME,
-		sessions_db.DB_PASSWORD,
-		sessions_db.DB_HOST,
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		db.DB_USERNAME,
+		db.DB_PASSWORD,
+		db.DB_HOST,
 		"5432",
-		sessions_db.DB_NAME,
+		db.DB_NAME,
 	)
-
-	secretKey := os.Getenv("SECRET_KEY")
-	if secretKey == "" {
-		log.Fatalf("env SECRET_KEY is required")
-	}
-
-	cfg.Secret = secretKey
-
-	return &cfg
 }
 
 func fetchConfigPath() string {
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"log/slog"
+	"sso/internal/lib/logger/sl"
+	"time"
+)
+
+// AuthService is the name the auth service is checked by, "" checks the server as a whole.
+const AuthService = "auth.Auth"
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Check is a storage the service cannot serve requests without.
+type Check struct {
+	Name   string
+	Pinger Pinger
+}
+
+// Watch pings the checks every interval and reports the service as NOT_SERVING
+// through the standard grpc.health.v1 server while any of them fails. The
+// returned function stops the checks.
+func Watch(log *slog.Logger, server *grpchealth.Server, interval time.Duration, timeout time.Duration, checks ...Check) (stop func()) {
+	log = log.With(slog.String("component", "grpc/health"))
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	update := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		status := healthpb.HealthCheckResponse_SERVING
+		for _, check := range checks {
+			if err := check.Pinger.Ping(ctx); err != nil {
+				log.Error("storage is unavailable", slog.String("check", check.Name), sl.Err(err))
+				status = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+		}
+
+		server.SetServingStatus("", status)
+		server.SetServingStatus(AuthService, status)
+	}
+
+	// Статус известен до того, как сервер начнёт принимать запросы
+	update()
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
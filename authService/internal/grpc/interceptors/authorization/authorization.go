@@ -23,6 +23,7 @@ func isPublicMethod(methodName string) bool {
 		"/auth.Auth/Login":              true,
 		"/auth.Auth/GetNewRefreshToken": true,
 		"/auth.Auth/Logout":             true,
+		"/grpc.health.v1.Health/Check":  true,
 	}
 	return publicMethod[methodName]
 }
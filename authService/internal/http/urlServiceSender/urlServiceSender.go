@@ -1,7 +1,9 @@
 package urlServiceSender
 
 import (
+	"context"
 	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"log/slog"
 	"net/http"
 	"sso/internal/services/auth"
@@ -10,24 +12,25 @@ import (
 type UrlServiceSender struct {
 	log            *slog.Logger
 	urlServiceAddr string
+	client         *http.Client
 }
 
 func New(log *slog.Logger, urlServiceAddr string) *UrlServiceSender {
 	return &UrlServiceSender{
 		log:            log,
 		urlServiceAddr: urlServiceAddr,
+		// транспорт передаёт traceparent, удаление видно в URL сервисе как часть той же трассы
+		client: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
 	}
 }
 
-func (u *UrlServiceSender) DeleteUser(serviceToken string) error {
+func (u *UrlServiceSender) DeleteUser(ctx context.Context, serviceToken string) error {
 	const op = "http.urlServiceSender.DeleteUser"
 
 	log := u.log.With(
 		slog.String("op", op))
 
-	client := &http.Client{}
-
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("http://%s/admin", u.urlServiceAddr), nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("http://%s/admin", u.urlServiceAddr), nil)
 	if err != nil {
 		log.Error("failed to create new request", slog.String("error", err.Error()))
 		return auth.ErrInternalError
@@ -35,7 +38,7 @@ func (u *UrlServiceSender) DeleteUser(serviceToken string) error {
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", serviceToken))
 
-	resp, err := client.Do(req)
+	resp, err := u.client.Do(req)
 	if err != nil {
 		log.Error("error when trying to send a request", slog.String("error", err.Error()))
 		return auth.ErrInternalError
@@ -50,7 +50,7 @@ type SessionManager interface {
 }
 
 type URLServiceManager interface {
-	DeleteUser(serviceToken string) error
+	DeleteUser(ctx context.Context, serviceToken string) error
 }
 
 var (
@@ -545,7 +545,7 @@ func (a *Auth) DeleteFromURLService(ctx context.Context, userID int64) error {
 		return ErrInternalError
 	}
 
-	err = a.urlServiceManager.DeleteUser(serviceToken)
+	err = a.urlServiceManager.DeleteUser(ctx, serviceToken)
 	if err != nil {
 		log.Error("failed to delete user data from url service", slog.String("error", err.Error()))
 		return err
@@ -17,10 +17,14 @@ type Storage struct {
 	driver string
 }
 
-// New creates a new instance of the SQLite storage
+// New opens the storage on the postgres or the sqlite3 driver
 func New(driver string, connStr string) (*Storage, error) {
 	const op = "storage.sql.New"
 
+	if driver == "sqlite3" {
+		connStr = sqliteDSN(connStr)
+	}
+
 	db, err := sql.Open(driver, connStr)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -30,18 +34,22 @@ func New(driver string, connStr string) (*Storage, error) {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if driver == "sqlite3" {
-		_, err = db.Exec("PRAGMA foreign_keys = ON;")
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-	}
 	return &Storage{
 		db:     db,
 		driver: driver,
 	}, nil
 }
 
+// sqliteDSN turns a path to the database file into a DSN that enables foreign
+// keys and waits for locks on every connection of the pool, PRAGMA would
+// reach only one of them. A DSN with its own options is kept as is.
+func sqliteDSN(path string) string {
+	if strings.Contains(path, "?") {
+		return path
+	}
+	return path + "?_foreign_keys=on&_busy_timeout=5000"
+}
+
 func (s *Storage) ConvertQuery(query string) string {
 	switch s.driver {
 	case "postgres":
@@ -65,6 +73,9 @@ func (s *Storage) ConvertQuery(query string) string {
 func (s *Storage) SaveUser(ctx context.Context, email string, passHash []byte) (int64, error) {
 	const op = "storage.sql.SaveUser"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	query := s.ConvertQuery(`INSERT INTO users(email, password_hash, role_id) VALUES(?, ?, 1) RETURNING id`)
 
 	var lastInsertID int64
@@ -88,6 +99,9 @@ func (s *Storage) SaveUser(ctx context.Context, email string, passHash []byte) (
 func (s *Storage) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
 	const op = "storage.sql.GetUserByEmail"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	query := s.ConvertQuery(`
 				SELECT u.id, u.password_hash, r.name
 				FROM users u
@@ -116,6 +130,9 @@ func (s *Storage) GetUserByEmail(ctx context.Context, email string) (models.User
 func (s *Storage) GetUserByID(ctx context.Context, userID int64) (models.User, error) {
 	const op = "storage.sql.GetUserByID"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	query := s.ConvertQuery(`
 				SELECT u.email, u.password_hash, r.name
 				FROM users u
@@ -145,6 +162,9 @@ func (s *Storage) GetUserByID(ctx context.Context, userID int64) (models.User, e
 func (s *Storage) SaveSession(ctx context.Context, session models.Session) (int64, error) {
 	const op = "storage.sql.SaveSession"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	if err := ctx.Err(); err != nil {
 		return 0, fmt.Errorf("%s: context error: %w", op, err)
 	}
@@ -178,6 +198,9 @@ func (s *Storage) SaveSession(ctx context.Context, session models.Session) (int6
 func (s *Storage) GetSession(ctx context.Context, sessionID int64) (*models.Session, error) {
 	const op = "storage.sql.GetSession"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	if err := ctx.Err(); err != nil {
 		return &models.Session{}, fmt.Errorf("%s: context error: %w", op, err)
 	}
@@ -207,6 +230,9 @@ func (s *Storage) GetSession(ctx context.Context, sessionID int64) (*models.Sess
 func (s *Storage) UpdateSession(ctx context.Context, newSession *models.Session) error {
 	const op = "storage.sql.UpdateSession"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("%s: context error: %w", op, err)
 	}
@@ -248,6 +274,9 @@ func (s *Storage) UpdateSession(ctx context.Context, newSession *models.Session)
 func (s *Storage) DeleteSession(ctx context.Context, sessionID int64) error {
 	const op = "storage.sql.DeleteSession"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("%s: context error: %w", op, err)
 	}
@@ -284,6 +313,9 @@ func (s *Storage) DeleteSession(ctx context.Context, sessionID int64) error {
 func (s *Storage) DeleteUser(ctx context.Context, userID int64) error {
 	const op = "storage.sql.DeleteUser"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("%s: context error: %w", op, err)
 	}
@@ -320,6 +352,9 @@ func (s *Storage) DeleteUser(ctx context.Context, userID int64) error {
 func (s *Storage) DeleteAllUserSessions(ctx context.Context, userID int64) (int64, error) {
 	const op = "storage.sql.DeleteAllUserSessions"
 
+	ctx, span := s.startSpan(ctx, op)
+	defer span.End()
+
 	if err := ctx.Err(); err != nil {
 		return 0, fmt.Errorf("%s: context error: %w", op, err)
 	}
@@ -345,3 +380,8 @@ func (s *Storage) DeleteAllUserSessions(ctx context.Context, userID int64) (int6
 
 	return rowsAffected, nil
 }
+
+// Ping checks that the database answers.
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sso/internal/domain/models"
+	"sso/internal/migrator"
+	"sso/internal/storage"
+	"sso/migrations"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStorage opens a migrated sqlite storage in a temporary file, so the
+// storage is tested without a postgres server.
+func newTestStorage(t *testing.T, source fs.FS) *Storage {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "sso.db")
+
+	m, err := migrator.New("sqlite3", path, source)
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Close())
+
+	s, err := New("sqlite3", path)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestStorage_Users(t *testing.T) {
+	s := newTestStorage(t, migrations.Main)
+	ctx := context.Background()
+
+	id, err := s.SaveUser(ctx, "user@example.com", []byte("hash"))
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	_, err = s.SaveUser(ctx, "user@example.com", []byte("hash"))
+	require.ErrorIs(t, err, storage.ErrUserExists)
+
+	user, err := s.GetUserByEmail(ctx, "user@example.com")
+	require.NoError(t, err)
+	require.Equal(t, id, user.ID)
+	require.Equal(t, "user", user.Role)
+
+	require.NoError(t, s.DeleteUser(ctx, id))
+
+	_, err = s.GetUserByID(ctx, id)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func TestStorage_Sessions(t *testing.T) {
+	s := newTestStorage(t, migrations.Sessions)
+	ctx := context.Background()
+
+	now := time.Now()
+	session := models.Session{
+		UserID:                     1,
+		RefreshTokenRandomPartHash: []byte("first"),
+		CreatedAt:                  now.Unix(),
+		ExpiresAt:                  now.Add(time.Hour).Unix(),
+	}
+
+	id, err := s.SaveSession(ctx, session)
+	require.NoError(t, err)
+
+	_, err = s.SaveSession(ctx, session)
+	require.ErrorIs(t, err, storage.ErrSessionExists)
+
+	updated := &models.Session{
+		ID:                         id,
+		UserID:                     1,
+		RefreshTokenRandomPartHash: []byte("second"),
+		CreatedAt:                  now.Unix(),
+		ExpiresAt:                  now.Add(2 * time.Hour).Unix(),
+	}
+	require.NoError(t, s.UpdateSession(ctx, updated))
+
+	got, err := s.GetSession(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, updated.RefreshTokenRandomPartHash, got.RefreshTokenRandomPartHash)
+	require.Equal(t, updated.ExpiresAt, got.ExpiresAt)
+
+	deleted, err := s.DeleteAllUserSessions(ctx, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, deleted)
+
+	require.ErrorIs(t, s.DeleteSession(ctx, id), storage.ErrSessionNotFound)
+}
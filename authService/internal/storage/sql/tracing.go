@@ -0,0 +1,15 @@
+package sql
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("sso/internal/storage/sql")
+
+// startSpan starts the span of a storage operation, named by its op.
+func (s *Storage) startSpan(ctx context.Context, op string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("db.system", s.driver)))
+}
@@ -0,0 +1,25 @@
+// Package migrations embeds the schema migrations, so the binary can apply
+// them itself. Every database has its own directory with a directory per driver.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed main sessions
+var files embed.FS
+
+// Main and Sessions are the migrations of the users and the sessions databases.
+var (
+	Main     = sub("main")
+	Sessions = sub("sessions")
+)
+
+func sub(dir string) fs.FS {
+	sub, err := fs.Sub(files, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
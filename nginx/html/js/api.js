@@ -111,9 +111,10 @@ class ApiService {
         });
     }
 
-    async getUrls() {
+    async getUrls(cursor = null) {
         const accessToken = localStorage.getItem('accessToken');
-        return this.request('/url/urls', {
+        const query = cursor ? `?cursor=${encodeURIComponent(cursor)}` : '';
+        return this.request(`/url/urls${query}`, {
             headers: { Authorization: `Bearer ${accessToken}` }
         });
     }
@@ -136,6 +137,55 @@ class ApiService {
         });
     }
 
+    async renameUrl(urlId, newAlias, graceDays) {
+        const accessToken = localStorage.getItem('accessToken');
+        return this.request(`/url/alias`, {
+            method: 'PATCH',
+            headers: { 'Authorization': `Bearer ${accessToken}` },
+            body: { urlId: urlId, newAlias: newAlias, graceDays: graceDays }
+        });
+    }
+
+    async getRevisions(urlId) {
+        const accessToken = localStorage.getItem('accessToken');
+        return this.request(`/url/links/${urlId}/revisions`, {
+            method: 'GET',
+            headers: { 'Authorization': `Bearer ${accessToken}` }
+        });
+    }
+
+    async rollbackUrl(urlId, revisionId) {
+        const accessToken = localStorage.getItem('accessToken');
+        return this.request(`/url/links/${urlId}/revisions/${revisionId}/rollback`, {
+            method: 'POST',
+            headers: { 'Authorization': `Bearer ${accessToken}` }
+        });
+    }
+
+    async getTrash() {
+        const accessToken = localStorage.getItem('accessToken');
+        return this.request(`/url/trash`, {
+            method: 'GET',
+            headers: { 'Authorization': `Bearer ${accessToken}` }
+        });
+    }
+
+    async restoreUrl(urlId) {
+        const accessToken = localStorage.getItem('accessToken');
+        return this.request(`/url/trash/${urlId}/restore`, {
+            method: 'POST',
+            headers: { 'Authorization': `Bearer ${accessToken}` }
+        });
+    }
+
+    async purgeUrl(urlId) {
+        const accessToken = localStorage.getItem('accessToken');
+        return this.request(`/url/trash/${urlId}`, {
+            method: 'DELETE',
+            headers: { 'Authorization': `Bearer ${accessToken}` }
+        });
+    }
+
     async deleteUrl(urlId) {
         const accessToken = localStorage.getItem('accessToken');
         return this.request(`/url`, {
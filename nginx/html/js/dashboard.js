@@ -14,6 +14,7 @@ document.addEventListener('DOMContentLoaded', function() {
     // Обработчики
     logoutBtn.addEventListener('click', handleLogout);
     addUrlForm.addEventListener('submit', handleAddUrl);
+    document.getElementById('showTrashBtn').addEventListener('click', loadTrash);
     
     // Загрузка URL
     loadUrls();
@@ -67,12 +68,15 @@ async function handleAddUrl(e) {
     }
 }
 
-async function loadUrls() {
+let nextCursor = null;
+
+async function loadUrls(append = false) {
     console.log('Загрузка URL...');
     try {
-        const urls = await apiService.getUrls();
-        console.log('Получены URL:', urls);
-        displayUrls(urls);
+        const page = await apiService.getUrls(append ? nextCursor : null);
+        console.log('Получены URL:', page);
+        nextCursor = page.nextCursor || null;
+        displayUrls(page.items, page.total, append);
     } catch (error) {
         if (error.data.message) {
             alert('Ошибка загрузки URL: ' + error.data.message);
@@ -83,34 +87,61 @@ async function loadUrls() {
     }
 }
 
-function displayUrls(urls) {
+// Заголовок может прийти со сторонней страницы, поэтому экранируется
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function displayUrls(urls, total, append) {
     console.log('Отображение URL:', urls);
     const container = document.getElementById('urlsContainer');
     
-    if (urls.length === 0) {
+    if (!append && urls.length === 0) {
         container.innerHTML = '<p>У вас пока нет сокращённых URL</p>';
         return;
     }
 
-    container.innerHTML = urls.map(url => `
+    const moreBtn = document.getElementById('loadMoreBtn');
+    if (moreBtn) {
+        moreBtn.remove();
+    }
+
+    const html = urls.map(url => `
         <div class="url-item">
             <div class="url-info">
+                ${url.title ? `<strong>${escapeHtml(url.title)}</strong><br>` : ''}
                 <strong>Оригинальный:</strong> 
                 <a href="${url.url}" target="_blank">${url.url}</a><br>
                 <strong>Сокращённый:</strong> 
                 <a href="${url.url}" target="_blank">${url.alias}</a><br>
-                <small>Воспользуйтесь этой ссылкой, введя в адресную строку <strong>svsevs.ru/${url.alias}</strong></small><br>
+                <small>Воспользуйтесь этой ссылкой, введя в адресную строку <strong>${url.shortUrl || 'svsevs.ru/' + url.alias}</strong></small><br>
+                <small>Создана: ${new Date(url.createdAt).toLocaleString()}</small><br>
             </div>
             <div class="url-actions">
                 <button onclick="editUrl(${url.id})">Изменить</button>
+                <button onclick="renameUrl(${url.id})">Переименовать</button>
+                <button onclick="showRevisions(${url.id})">История</button>
                 <button onclick="deleteUrl(${url.id})">Удалить</button>
+                <a href="${url.domain ? url.shortUrl : '/url/' + url.alias}/qr.svg" target="_blank">QR-код</a>
             </div>
         </div>
     `).join('');
+
+    if (append) {
+        container.insertAdjacentHTML('beforeend', html);
+    } else {
+        container.innerHTML = `<p>Всего ссылок: ${total}</p>` + html;
+    }
+
+    if (nextCursor) {
+        container.insertAdjacentHTML('beforeend', '<button id="loadMoreBtn" onclick="loadUrls(true)">Показать ещё</button>');
+    }
 }
 
 async function deleteUrl(urlId) {
-    if (!confirm('Удалить эту ссылку?')) return;
+    if (!confirm('Переместить эту ссылку в корзину?')) return;
     
     console.log('Удаление URL с ID:', urlId);
     try {
@@ -147,4 +178,111 @@ async function editUrl(urlId) {
             }
         }
     }
-}
\ No newline at end of file
+}
+
+async function renameUrl(urlId) {
+    const newAlias = prompt('Введите новый алиас:');
+    if (!newAlias) return;
+    const graceDays = parseInt(prompt('Сколько дней старый алиас будет вести на ссылку? (0 - сразу освободить)', '7'), 10) || 0;
+
+    try {
+        await apiService.renameUrl(urlId, newAlias, graceDays);
+        loadUrls(); // Обновляем список
+    } catch (error) {
+        if (error.data.message) {
+            alert('Ошибка переименования: ' + error.data.message);
+        }
+        else {
+            alert('Ошибка переименования: ' + error.message);
+        }
+    }
+}
+
+async function showRevisions(urlId) {
+    try {
+        const data = await apiService.getRevisions(urlId);
+        if (data.revisions.length === 0) {
+            alert('Ссылка ещё не изменялась');
+            return;
+        }
+
+        const lines = data.revisions.map(rev =>
+            `#${rev.id} ${new Date(rev.createdAt).toLocaleString()} (${rev.change}): ` +
+            `${rev.oldAlias} → ${rev.newAlias}, ${rev.oldUrl} → ${rev.newUrl}`
+        );
+        const revisionId = prompt(lines.join('\n') + '\n\nВведите номер правки, чтобы вернуть ссылку к состоянию до неё:');
+        if (!revisionId) return;
+
+        await apiService.rollbackUrl(urlId, revisionId);
+        loadUrls(); // Обновляем список
+    } catch (error) {
+        if (error.data.message) {
+            alert('Ошибка: ' + error.data.message);
+        }
+        else {
+            alert('Ошибка: ' + error.message);
+        }
+    }
+}
+
+async function loadTrash() {
+    const container = document.getElementById('trashContainer');
+    try {
+        const data = await apiService.getTrash();
+        if (data.items.length === 0) {
+            container.innerHTML = '<p>Корзина пуста</p>';
+            return;
+        }
+
+        container.innerHTML = data.items.map(url => `
+            <div class="url-item">
+                <div class="url-info">
+                    <strong>${url.alias}</strong> → ${escapeHtml(url.url)}<br>
+                    <small>Удалена: ${new Date(url.deletedAt).toLocaleString()}, будет стёрта: ${new Date(url.purgeAt).toLocaleString()}</small>
+                </div>
+                <div class="url-actions">
+                    <button onclick="restoreUrl(${url.id})">Восстановить</button>
+                    <button onclick="purgeUrl(${url.id})">Удалить навсегда</button>
+                </div>
+            </div>
+        `).join('');
+    } catch (error) {
+        if (error.data.message) {
+            alert('Ошибка загрузки корзины: ' + error.data.message);
+        }
+        else {
+            alert('Ошибка загрузки корзины: ' + error.message);
+        }
+    }
+}
+
+async function restoreUrl(urlId) {
+    try {
+        await apiService.restoreUrl(urlId);
+        loadTrash();
+        loadUrls(); // Обновляем список
+    } catch (error) {
+        if (error.data.message) {
+            alert('Ошибка восстановления: ' + error.data.message);
+        }
+        else {
+            alert('Ошибка восстановления: ' + error.message);
+        }
+    }
+}
+
+async function purgeUrl(urlId) {
+    if (!confirm('Удалить ссылку навсегда? Алиас и статистика будут потеряны.')) return;
+
+    try {
+        await apiService.purgeUrl(urlId);
+        loadTrash();
+    } catch (error) {
+        if (error.data.message) {
+            alert('Ошибка удаления: ' + error.data.message);
+        }
+        else {
+            alert('Ошибка удаления: ' + error.message);
+        }
+    }
+}